@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
@@ -18,13 +20,24 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/frherrer/mcp-sail-operator/pkg/audit"
+	"github.com/frherrer/mcp-sail-operator/pkg/events"
+	"github.com/frherrer/mcp-sail-operator/pkg/handlers/completion"
+	k8shandlers "github.com/frherrer/mcp-sail-operator/pkg/handlers/k8s"
 	sailoperatorhandlers "github.com/frherrer/mcp-sail-operator/pkg/handlers/sailoperator"
+	"github.com/frherrer/mcp-sail-operator/pkg/health"
 	mcptools "github.com/frherrer/mcp-sail-operator/pkg/mcp"
+	"github.com/frherrer/mcp-sail-operator/pkg/mcpext"
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
 	"github.com/frherrer/mcp-sail-operator/pkg/types"
 )
 
 var (
-	kubeconfigPath string
+	kubeconfigPath  string
+	healthRulesPath string
+	enableWrites    bool
+	auditLogPath    string
+	responseBudget  int
 )
 
 func main() {
@@ -38,6 +51,7 @@ USAGE:
   mcp-sail-operator              # Start MCP server (default)
   mcp-sail-operator logs <pod>   # Get pod logs  
   mcp-sail-operator pods         # List pods
+  mcp-sail-operator events       # List or watch events
   mcp-sail-operator health       # Check health
   mcp-sail-operator status       # Get Istio status`,
 		Run: runServer,
@@ -46,10 +60,20 @@ USAGE:
 	// Add global kubeconfig flag
 	rootCmd.PersistentFlags().StringVar(&kubeconfigPath, "kubeconfig", "",
 		"Path to kubeconfig file (default: ~/.kube/config or KUBECONFIG env var)")
+	rootCmd.PersistentFlags().StringVar(&healthRulesPath, "health-rules", "",
+		"Path to a YAML file with additional health rules for Sail components (default: built-in rules)")
+
+	rootCmd.Flags().BoolVar(&enableWrites, "enable-writes", false,
+		"Register tools that modify Sail resources and restart workloads (every write is dry-run and needs user confirmation)")
+	rootCmd.Flags().StringVar(&auditLogPath, "audit-log", audit.DefaultPath(),
+		"Path of the audit log recording every write when --enable-writes is set")
+	rootCmd.Flags().IntVar(&responseBudget, "response-budget", k8shandlers.DefaultResponseBudget,
+		"Bytes of items list tools return per call before truncating with a cursor to the rest")
 
 	// Add CLI subcommands
 	rootCmd.AddCommand(createLogsCommand())
 	rootCmd.AddCommand(createPodsCommand())
+	rootCmd.AddCommand(createEventsCommand())
 	rootCmd.AddCommand(createHealthCommand())
 	rootCmd.AddCommand(createStatusCommand())
 
@@ -65,22 +89,72 @@ func runServer(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to initialize Kubernetes clients: %v", err)
 	}
 
-	// Create MCP server
+	healthRules, err := loadHealthRules(healthRulesPath)
+	if err != nil {
+		log.Fatalf("Failed to load health rules: %v", err)
+	}
+
+	// Discover the Sail Operator CRDs served by the cluster
+	registry := sail.NewRegistry(k8sClient.Discovery(), dynamicClient)
+	if err := registry.Refresh(context.Background()); err != nil {
+		log.Printf("Warning: Sail Operator CRD discovery failed, will retry on demand: %v", err)
+	}
+
+	// Create MCP server, completing arguments from live cluster data
+	completer := completion.NewCompleter(k8sClient, dynamicClient, registry)
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "mcp-sail-operator",
 		Version: "0.1.0",
-	}, nil)
+	}, &mcp.ServerOptions{
+		CompletionHandler: completer.Complete,
+	})
+
+	transport := mcpext.NewStdioTransport()
+	cfg := mcptools.Config{
+		HealthRules:    healthRules,
+		EnableWrites:   enableWrites,
+		Elicitor:       transport,
+		ResponseBudget: responseBudget,
+	}
+	if enableWrites {
+		auditLog, err := audit.NewLogger(auditLogPath)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer auditLog.Close()
+		cfg.AuditLog = auditLog
+		log.Printf("Write tools enabled, recording writes in %s", auditLogPath)
+	}
 
 	// Register all MCP tools
-	mcptools.RegisterAllTools(server, k8sClient, dynamicClient)
+	mcptools.RegisterAllTools(server, k8sClient, dynamicClient, registry, cfg)
+
+	// Register MCP resources
+	mcptools.RegisterAllResources(context.Background(), server, k8sClient, dynamicClient, registry, transport)
+
+	// Register troubleshooting playbooks
+	mcptools.RegisterAllPrompts(server)
 
 	// Start server using stdio transport
 	log.Println("Starting MCP Sail Operator server...")
-	if err := server.Run(context.Background(), mcp.NewStdioTransport()); err != nil {
+	if err := server.Run(context.Background(), transport); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
 
+// loadHealthRules loads health rules from the given file, or returns the built-in defaults when no file is set
+func loadHealthRules(path string) (*health.RuleSet, error) {
+	if path == "" {
+		return health.DefaultRuleSet(), nil
+	}
+	rules, err := health.LoadRuleSet(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Loaded %d health rules from %s", len(rules.Rules), path)
+	return rules, nil
+}
+
 // initKubernetesClients creates both standard and dynamic Kubernetes clients using the specified or default kubeconfig
 func initKubernetesClients(kubeconfigPath string) (*kubernetes.Clientset, dynamic.Interface, error) {
 	var config *rest.Config
@@ -195,33 +269,113 @@ EXAMPLES:
 
 // createPodsCommand creates the pods subcommand
 func createPodsCommand() *cobra.Command {
-	var namespace, labelSelector string
+	var podArgs types.ListPodsParams
+	var restartsAbove int32
+	var olderThan, youngerThan time.Duration
+	var withSidecar, withoutSidecar bool
 
 	cmd := &cobra.Command{
 		Use:   "pods",
 		Short: "List pods in the cluster",
-		Long: `List pods with optional namespace and label filtering.
+		Long: `List pods with optional namespace, label and field selectors, status
+filters and sorting.
 
 EXAMPLES:
   mcp-sail-operator pods
   mcp-sail-operator pods --namespace istio-system
-  mcp-sail-operator pods --label-selector app=istiod`,
+  mcp-sail-operator pods --label-selector app=istiod
+  mcp-sail-operator pods --node worker-1 --phase Pending
+  mcp-sail-operator pods --not-ready --restarts-above 3 --with-sidecar --sort-by restarts
+  mcp-sail-operator pods --younger-than 15m --sort-by age`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if withSidecar && withoutSidecar {
+				log.Fatalf("--with-sidecar and --without-sidecar are mutually exclusive")
+			}
+			if withSidecar || withoutSidecar {
+				podArgs.HasSidecar = &withSidecar
+			}
+			if cmd.Flags().Changed("restarts-above") {
+				podArgs.RestartsAbove = &restartsAbove
+			}
+			podArgs.OlderThanSeconds = int64(olderThan.Seconds())
+			podArgs.YoungerThanSeconds = int64(youngerThan.Seconds())
+
 			// Initialize Kubernetes client
 			k8sClient, _, err := initKubernetesClients(kubeconfigPath)
 			if err != nil {
 				log.Fatalf("Failed to initialize Kubernetes client: %v", err)
 			}
 
-			err = listPodsDirectly(k8sClient, namespace, labelSelector)
+			err = listPodsDirectly(k8sClient, podArgs)
 			if err != nil {
 				log.Fatalf("Failed to list pods: %v", err)
 			}
 		},
 	}
 
+	cmd.Flags().StringVarP(&podArgs.Namespace, "namespace", "n", "", "Namespace (empty for all namespaces)")
+	cmd.Flags().StringVarP(&podArgs.LabelSelector, "label-selector", "l", "", "Label selector")
+	cmd.Flags().StringVar(&podArgs.FieldSelector, "field-selector", "", "Field selector")
+	cmd.Flags().StringVar(&podArgs.NodeName, "node", "", "Only pods scheduled on this node")
+	cmd.Flags().StringVar(&podArgs.Phase, "phase", "", "Only pods in this phase (Pending, Running, Succeeded, Failed, Unknown)")
+	cmd.Flags().BoolVar(&podArgs.NotReady, "not-ready", false, "Only pods that are not Ready (completed pods excepted)")
+	cmd.Flags().Int32Var(&restartsAbove, "restarts-above", 0, "Only pods restarted more than this many times")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only pods created longer ago than this")
+	cmd.Flags().DurationVar(&youngerThan, "younger-than", 0, "Only pods created more recently than this")
+	cmd.Flags().BoolVar(&withSidecar, "with-sidecar", false, "Only pods with an istio-proxy container")
+	cmd.Flags().BoolVar(&withoutSidecar, "without-sidecar", false, "Only pods without an istio-proxy container")
+	cmd.Flags().StringVar(&podArgs.SortBy, "sort-by", "", "Sort by age (newest first), restarts (most first) or name")
+
+	return cmd
+}
+
+// createEventsCommand creates the events subcommand
+func createEventsCommand() *cobra.Command {
+	var namespace string
+	var watchEvents, warningsOnly bool
+	var since, duration, stormWindow time.Duration
+	var stormThreshold int
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "List or watch events",
+		Long: `List recent events, or watch new events with repeats deduplicated and an
+alert when Warning events for a mesh component cross the storm threshold.
+
+EXAMPLES:
+  mcp-sail-operator events --namespace istio-system
+  mcp-sail-operator events --watch --warnings-only
+  mcp-sail-operator events -w -n istio-system --duration 5m --storm-threshold 5 --storm-window 30s`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize Kubernetes client
+			k8sClient, _, err := initKubernetesClients(kubeconfigPath)
+			if err != nil {
+				log.Fatalf("Failed to initialize Kubernetes client: %v", err)
+			}
+
+			if watchEvents {
+				err = watchEventsDirectly(k8sClient, events.Options{
+					Namespace:      namespace,
+					WarningsOnly:   warningsOnly,
+					StormThreshold: stormThreshold,
+					StormWindow:    stormWindow,
+				}, duration)
+			} else {
+				err = listEventsDirectly(k8sClient, namespace, warningsOnly, since)
+			}
+			if err != nil {
+				log.Fatalf("Failed to get events: %v", err)
+			}
+		},
+	}
+
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace (empty for all namespaces)")
-	cmd.Flags().StringVarP(&labelSelector, "label-selector", "l", "", "Label selector")
+	cmd.Flags().BoolVarP(&watchEvents, "watch", "w", false, "Watch new events until interrupted or --duration passed")
+	cmd.Flags().BoolVar(&warningsOnly, "warnings-only", false, "Show Warning events only")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only list events seen within this duration (without --watch)")
+	cmd.Flags().DurationVar(&duration, "duration", 0, "How long to watch (default: until interrupted)")
+	cmd.Flags().IntVar(&stormThreshold, "storm-threshold", events.DefaultStormThreshold, "Warning events for one mesh component and reason that raise a storm alert")
+	cmd.Flags().DurationVar(&stormWindow, "storm-window", events.DefaultStormWindow, "Window the storm threshold applies to")
 
 	return cmd
 }
@@ -229,6 +383,8 @@ EXAMPLES:
 // createHealthCommand creates the health subcommand
 func createHealthCommand() *cobra.Command {
 	var namespace string
+	var staleAfter, timeout time.Duration
+	var concurrency int
 
 	cmd := &cobra.Command{
 		Use:   "health",
@@ -237,15 +393,28 @@ func createHealthCommand() *cobra.Command {
 
 EXAMPLES:
   mcp-sail-operator health
-  mcp-sail-operator health --namespace istio-system`,
+  mcp-sail-operator health --namespace istio-system
+  mcp-sail-operator health --stale-after 30m
+  mcp-sail-operator health --concurrency 2 --timeout 5s`,
 		Run: func(cmd *cobra.Command, args []string) {
 			// Initialize Kubernetes clients
-			_, dynamicClient, err := initKubernetesClients(kubeconfigPath)
+			k8sClient, dynamicClient, err := initKubernetesClients(kubeconfigPath)
 			if err != nil {
 				log.Fatalf("Failed to initialize Kubernetes clients: %v", err)
 			}
 
-			err = checkHealthDirectly(dynamicClient, namespace)
+			healthRules, err := loadHealthRules(healthRulesPath)
+			if err != nil {
+				log.Fatalf("Failed to load health rules: %v", err)
+			}
+
+			registry := sail.NewRegistry(k8sClient.Discovery(), dynamicClient)
+			err = checkHealthDirectly(dynamicClient, registry, healthRules, types.CheckSailOperatorHealthParams{
+				Namespace:         namespace,
+				StaleAfterSeconds: int64(staleAfter.Seconds()),
+				Concurrency:       concurrency,
+				TimeoutSeconds:    int64(timeout.Seconds()),
+			})
 			if err != nil {
 				log.Fatalf("Failed to check health: %v", err)
 			}
@@ -253,6 +422,9 @@ EXAMPLES:
 	}
 
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace (empty for all namespaces)")
+	cmd.Flags().DurationVar(&staleAfter, "stale-after", 10*time.Minute, "Report resources unreconciled or stuck in a non-True condition for longer than this as Stale")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of component types checked in parallel")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "Deadline for checking each component type")
 
 	return cmd
 }
@@ -280,12 +452,13 @@ EXAMPLES:
 			// unless explicitly specified
 
 			// Initialize Kubernetes clients
-			_, dynamicClient, err := initKubernetesClients(kubeconfigPath)
+			k8sClient, dynamicClient, err := initKubernetesClients(kubeconfigPath)
 			if err != nil {
 				log.Fatalf("Failed to initialize Kubernetes clients: %v", err)
 			}
 
-			err = getStatusDirectly(dynamicClient, istioName, namespace)
+			registry := sail.NewRegistry(k8sClient.Discovery(), dynamicClient)
+			err = getStatusDirectly(dynamicClient, registry, istioName, namespace)
 			if err != nil {
 				log.Fatalf("Failed to get status: %v", err)
 			}
@@ -335,37 +508,49 @@ func getPodLogsDirectly(k8sClient *kubernetes.Clientset, namespace, podName, con
 	return scanner.Err()
 }
 
-// listPodsDirectly lists pods directly
-func listPodsDirectly(k8sClient *kubernetes.Clientset, namespace, labelSelector string) error {
-	listOptions := metav1.ListOptions{}
-	if labelSelector != "" {
-		listOptions.LabelSelector = labelSelector
+// listPodsDirectly lists pods directly, applying the same selectors, filters
+// and sorting as the list_pods tool to the whole list
+func listPodsDirectly(k8sClient *kubernetes.Clientset, args types.ListPodsParams) error {
+	listOptions, err := k8shandlers.PodListOptions(args)
+	if err != nil {
+		return err
 	}
-
-	var podList *corev1.PodList
-	var err error
-
-	if namespace != "" {
-		podList, err = k8sClient.CoreV1().Pods(namespace).List(context.Background(), listOptions)
-	} else {
-		podList, err = k8sClient.CoreV1().Pods("").List(context.Background(), listOptions)
+	filter, err := k8shandlers.NewPodFilter(args)
+	if err != nil {
+		return err
 	}
 
-	if err != nil {
-		return fmt.Errorf("error listing pods: %v", err)
+	var pods []corev1.Pod
+	now := time.Now()
+	listOptions.Limit = 500
+	for {
+		podList, err := k8sClient.CoreV1().Pods(args.Namespace).List(context.Background(), listOptions)
+		if err != nil {
+			return fmt.Errorf("error listing pods: %v", err)
+		}
+		for i := range podList.Items {
+			if filter.Match(&podList.Items[i], now) {
+				pods = append(pods, podList.Items[i])
+			}
+		}
+		if podList.Continue == "" {
+			break
+		}
+		listOptions.Continue = podList.Continue
 	}
+	k8shandlers.SortPods(pods, args.SortBy)
 
-	if len(podList.Items) == 0 {
+	if len(pods) == 0 {
 		fmt.Println("No pods found")
 		return nil
 	}
 
-	fmt.Printf("Found %d pods:\n\n", len(podList.Items))
+	fmt.Printf("Found %d pods:\n\n", len(pods))
 	fmt.Printf("%-30s %-15s %-10s %-8s %-10s %s\n",
 		"NAME", "NAMESPACE", "STATUS", "READY", "RESTARTS", "AGE")
 	fmt.Println(strings.Repeat("-", 90))
 
-	for _, pod := range podList.Items {
+	for _, pod := range pods {
 		readyCount := 0
 		totalCount := len(pod.Status.ContainerStatuses)
 		restarts := int32(0)
@@ -395,19 +580,77 @@ func listPodsDirectly(k8sClient *kubernetes.Clientset, namespace, labelSelector
 	return nil
 }
 
+// listEventsDirectly lists events using the existing MCP handler
+func listEventsDirectly(k8sClient *kubernetes.Clientset, namespace string, warningsOnly bool, since time.Duration) error {
+	args := types.ListEventsParams{
+		Namespace:    namespace,
+		SinceSeconds: int64(since.Seconds()),
+	}
+	if warningsOnly {
+		args.Type = "Warning"
+	}
+
+	result, err := k8shandlers.ListEvents(k8sClient)(context.Background(), nil, &mcp.CallToolParamsFor[types.ListEventsParams]{
+		Arguments: args,
+	})
+	if err != nil {
+		return fmt.Errorf("listing events failed: %v", err)
+	}
+
+	// Print the result
+	if len(result.Content) > 0 {
+		if textContent, ok := result.Content[0].(*mcp.TextContent); ok {
+			fmt.Println(textContent.Text)
+		}
+	}
+
+	return nil
+}
+
+// watchEventsDirectly prints events as they arrive until interrupted or
+// duration passed, then prints the deduplicated summary
+func watchEventsDirectly(k8sClient *kubernetes.Clientset, opts events.Options, duration time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	fmt.Println("Watching events (Ctrl+C to stop)...")
+	result, err := events.Watch(ctx, k8sClient, opts, func(line string) {
+		fmt.Println(line)
+	})
+	if err != nil && result.Occurrences == 0 {
+		return fmt.Errorf("error watching events: %v", err)
+	}
+
+	fmt.Printf("\n=== %d occurrences, %d unique events in %s ===\n", result.Occurrences, result.Unique, result.Duration)
+	for _, a := range result.Alerts {
+		fmt.Printf("🚨 %s: %d %s events within %ds at %s\n", a.Component, a.Count, a.Reason, a.WindowSeconds, a.Time)
+	}
+	for _, e := range result.Events {
+		fmt.Printf("×%-4d %-7s %s %s: %s\n", e.Count, e.Type, e.Reason, e.Object, e.Message)
+	}
+	if err != nil {
+		return fmt.Errorf("watch stopped: %v", err)
+	}
+
+	return nil
+}
+
 // checkHealthDirectly checks health directly using existing MCP handler
-func checkHealthDirectly(dynamicClient dynamic.Interface, namespace string) error {
+func checkHealthDirectly(dynamicClient dynamic.Interface, registry *sail.Registry, healthRules *health.RuleSet, args types.CheckSailOperatorHealthParams) error {
 	// Create mock MCP server session and params
 	ctx := context.Background()
 
 	// Use the existing health check handler directly
-	healthHandler := sailoperatorhandlers.CheckSailOperatorHealth(dynamicClient)
+	healthHandler := sailoperatorhandlers.CheckSailOperatorHealth(dynamicClient, registry, healthRules)
 
 	// Create parameters
 	params := &mcp.CallToolParamsFor[types.CheckSailOperatorHealthParams]{
-		Arguments: types.CheckSailOperatorHealthParams{
-			Namespace: namespace,
-		},
+		Arguments: args,
 	}
 
 	// Call the handler
@@ -427,12 +670,12 @@ func checkHealthDirectly(dynamicClient dynamic.Interface, namespace string) erro
 }
 
 // getStatusDirectly gets status directly using existing MCP handler
-func getStatusDirectly(dynamicClient dynamic.Interface, istioName, namespace string) error {
+func getStatusDirectly(dynamicClient dynamic.Interface, registry *sail.Registry, istioName, namespace string) error {
 	// Create mock MCP server session and params
 	ctx := context.Background()
 
 	// Use the existing status handler directly
-	statusHandler := sailoperatorhandlers.GetIstioStatus(dynamicClient)
+	statusHandler := sailoperatorhandlers.GetIstioStatus(dynamicClient, registry)
 
 	// Create parameters
 	params := &mcp.CallToolParamsFor[types.GetIstioStatusParams]{
@@ -0,0 +1,71 @@
+// Package audit records write operations performed through the server
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is a single audit log record
+type Entry struct {
+	Time    string      `json:"time"`
+	Tool    string      `json:"tool"`
+	Targets []string    `json:"targets,omitempty"` // kind/namespace/name of the changed objects
+	Params  interface{} `json:"params,omitempty"`
+	Changes interface{} `json:"changes,omitempty"`
+	Outcome string      `json:"outcome"` // applied|declined|cancelled|refused|rejected|no-op|dry-run-failed|failed
+	Error   string      `json:"error,omitempty"`
+}
+
+// Logger appends entries to a JSON-lines file
+type Logger struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// DefaultPath returns the audit log location used when none is configured
+func DefaultPath() string {
+	if dir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(dir, ".mcp-sail-operator", "audit.log")
+	}
+	return "mcp-sail-operator-audit.log"
+}
+
+// NewLogger opens the audit log for appending, creating it and its directory if needed
+func NewLogger(path string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	return &Logger{file: file}, nil
+}
+
+// Record writes an entry and syncs it to disk. The time is filled in when empty.
+func (l *Logger) Record(entry Entry) error {
+	if entry.Time == "" {
+		entry.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return l.file.Sync()
+}
+
+// Close closes the audit log
+func (l *Logger) Close() error {
+	return l.file.Close()
+}
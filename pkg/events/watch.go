@@ -0,0 +1,404 @@
+// Package events watches Kubernetes events, deduplicating repeats and
+// detecting storms of Warning events for mesh components
+package events
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/logs"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const (
+	DefaultStormThreshold = 10
+	DefaultStormWindow    = time.Minute
+	// repeatReportInterval is how often repeats of already reported events are summarised
+	repeatReportInterval = 10 * time.Second
+	// rewatchDelay is how long to wait before watching again after the watch
+	// failed; it doubles with each failure in a row up to maxRewatchDelay
+	rewatchDelay    = 2 * time.Second
+	maxRewatchDelay = 30 * time.Second
+)
+
+// watchEnd is why one watch stopped
+type watchEnd int
+
+const (
+	watchClosed  watchEnd = iota // the server closed it, or ctx is done
+	watchExpired                 // its resource version is too old
+	watchFailed                  // the server sent any other error
+)
+
+// Options selects the events to watch and the storm threshold
+type Options struct {
+	Namespace     string // all namespaces when empty
+	FieldSelector string
+	WarningsOnly  bool
+	// StormThreshold Warning events for one component and reason within
+	// StormWindow raise an alert
+	StormThreshold int
+	StormWindow    time.Duration
+}
+
+// Emit receives a line to show while watching; it is called from the
+// watching goroutine only
+type Emit func(line string)
+
+// aggregate is one deduplicated event
+type aggregate struct {
+	event   types.WatchedEvent
+	first   time.Time
+	last    time.Time
+	unshown int // repeats since the last report
+}
+
+// storm tracks recent Warning occurrences of one component and reason
+type storm struct {
+	times   []time.Time
+	alerted bool
+}
+
+// watcher holds the state of one Watch call
+type watcher struct {
+	opts       Options
+	emit       Emit
+	aggregates map[string]*aggregate
+	counts     map[string]int32 // last series count seen per event UID
+	storms     map[string]*storm
+	result     types.WatchEventsResult
+}
+
+// Watch watches events until ctx is done, emitting new events as they
+// arrive, summaries of repeats every few seconds and an alert whenever
+// Warning events for a mesh component cross the storm threshold. Repeats are
+// recognised by the event's series count and by a normalised message, so a
+// restarting pod reported through many Event objects counts as one event.
+// emit may be nil when only the final result is wanted.
+func Watch(ctx context.Context, k8sClient kubernetes.Interface, opts Options, emit Emit) (types.WatchEventsResult, error) {
+	if opts.StormThreshold <= 0 {
+		opts.StormThreshold = DefaultStormThreshold
+	}
+	if opts.StormWindow <= 0 {
+		opts.StormWindow = DefaultStormWindow
+	}
+	if emit == nil {
+		emit = func(string) {}
+	}
+	w := &watcher{
+		opts:       opts,
+		emit:       emit,
+		aggregates: map[string]*aggregate{},
+		counts:     map[string]int32{},
+		storms:     map[string]*storm{},
+	}
+	start := time.Now()
+	err := w.run(ctx, k8sClient)
+	w.finish(ctx, start, err)
+	return w.result, err
+}
+
+func (w *watcher) run(ctx context.Context, k8sClient kubernetes.Interface) error {
+	client := k8sClient.EventsV1().Events(w.opts.Namespace)
+
+	// Only events from now on: start at the current resource version
+	resourceVersion := ""
+	relist := func() error {
+		list, err := client.List(ctx, metav1.ListOptions{FieldSelector: w.opts.FieldSelector, Limit: 1})
+		if err != nil {
+			return err
+		}
+		resourceVersion = list.ResourceVersion
+		return nil
+	}
+	if err := relist(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(repeatReportInterval)
+	defer ticker.Stop()
+
+	delay := rewatchDelay
+	backoff := func() bool {
+		ok := sleep(ctx, delay)
+		delay = min(delay*2, maxRewatchDelay)
+		return ok
+	}
+	for ctx.Err() == nil {
+		stream, err := client.Watch(ctx, metav1.ListOptions{
+			FieldSelector:       w.opts.FieldSelector,
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			w.emit(fmt.Sprintf("⚠️ watch failed, retrying in %s: %v", delay, err))
+			if !backoff() {
+				return nil
+			}
+			continue
+		}
+
+		end, delivered := w.consume(ctx, stream, &resourceVersion, ticker.C)
+		stream.Stop()
+		if delivered {
+			delay = rewatchDelay
+		}
+		// Only a watch that delivered events and then closed is watched
+		// again at once; anything else waits, so a server closing watches
+		// immediately does not turn this into a loop of requests
+		if end != watchClosed || !delivered {
+			if !backoff() {
+				return nil
+			}
+		}
+		if end == watchExpired {
+			// The resource version is too old; events in between are lost
+			if err := relist(); err != nil && ctx.Err() == nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// consume handles the events of one watch until it closes or ctx is done,
+// and reports why it ended and whether it delivered any event or bookmark
+func (w *watcher) consume(ctx context.Context, stream watch.Interface, resourceVersion *string, tick <-chan time.Time) (watchEnd, bool) {
+	delivered := false
+	for {
+		select {
+		case <-ctx.Done():
+			return watchClosed, delivered
+		case <-tick:
+			w.reportRepeats()
+		case ev, ok := <-stream.ResultChan():
+			if !ok {
+				return watchClosed, delivered
+			}
+			switch ev.Type {
+			case watch.Added, watch.Modified, watch.Bookmark:
+				delivered = true
+			}
+			switch ev.Type {
+			case watch.Added, watch.Modified:
+				if e, ok := ev.Object.(*eventsv1.Event); ok {
+					*resourceVersion = e.ResourceVersion
+					w.handle(e)
+				}
+			case watch.Bookmark:
+				if e, ok := ev.Object.(*eventsv1.Event); ok {
+					*resourceVersion = e.ResourceVersion
+				}
+			case watch.Error:
+				status, ok := ev.Object.(*metav1.Status)
+				if ok && status.Code == http.StatusGone {
+					return watchExpired, delivered
+				}
+				// Other errors end this watch; it is started again at the
+				// same version after a delay
+				message := "unknown error"
+				if ok {
+					message = status.Message
+				}
+				w.emit(fmt.Sprintf("⚠️ watch error, retrying: %s", message))
+				return watchFailed, delivered
+			}
+		}
+	}
+}
+
+// handle records one added or updated event
+func (w *watcher) handle(e *eventsv1.Event) {
+	if w.opts.WarningsOnly && e.Type != "Warning" {
+		return
+	}
+
+	// A series update repeats the event; count only what is new
+	count := int32(1)
+	if e.Series != nil && e.Series.Count > 0 {
+		count = e.Series.Count
+	} else if e.DeprecatedCount > 0 {
+		count = e.DeprecatedCount
+	}
+	previous, seen := w.counts[string(e.UID)]
+	w.counts[string(e.UID)] = count
+	delta := int(count - previous)
+	if !seen {
+		delta = 1
+	}
+	if delta <= 0 {
+		return
+	}
+	now := time.Now()
+	w.result.Occurrences += delta
+
+	object := e.Regarding.Kind + "/"
+	if e.Regarding.Namespace != "" {
+		object += e.Regarding.Namespace + "/"
+	}
+	object += e.Regarding.Name
+	// Pods of one workload differ only by hash, in their name and in the
+	// notes that mention it; group them under their signature
+	name := logs.Signature(e.Regarding.Name)
+	signature := logs.Signature(strings.ReplaceAll(e.Note, e.Regarding.Name, name))
+	key := strings.Join([]string{e.Regarding.Kind, e.Regarding.Namespace, name, e.Reason, signature}, "\x00")
+	component := MeshComponent(e)
+
+	agg, ok := w.aggregates[key]
+	if !ok {
+		agg = &aggregate{
+			event: types.WatchedEvent{
+				Object:    object,
+				Type:      e.Type,
+				Reason:    e.Reason,
+				Message:   e.Note,
+				Component: component,
+			},
+			first: now,
+		}
+		w.aggregates[key] = agg
+		w.emit(fmt.Sprintf("%s %-7s %s %s: %s", now.UTC().Format(time.RFC3339), e.Type, e.Reason, object, e.Note))
+	} else {
+		agg.unshown += delta
+	}
+	agg.event.Count += delta
+	agg.last = now
+
+	if e.Type == "Warning" && component != "" {
+		w.trackStorm(component, e.Reason, e.Note, delta, now)
+	}
+}
+
+// trackStorm counts Warning events per component and reason within the
+// storm window and alerts once when they cross the threshold; the alert is
+// armed again after the rate falls below half of it
+func (w *watcher) trackStorm(component, reason, example string, delta int, now time.Time) {
+	key := component + "\x00" + reason
+	s, ok := w.storms[key]
+	if !ok {
+		s = &storm{}
+		w.storms[key] = s
+	}
+	for i := 0; i < delta; i++ {
+		s.times = append(s.times, now)
+	}
+	cutoff := now.Add(-w.opts.StormWindow)
+	for len(s.times) > 0 && s.times[0].Before(cutoff) {
+		s.times = s.times[1:]
+	}
+
+	switch {
+	case !s.alerted && len(s.times) >= w.opts.StormThreshold:
+		s.alerted = true
+		alert := types.EventStormAlert{
+			Component:     component,
+			Reason:        reason,
+			Count:         len(s.times),
+			WindowSeconds: int64(w.opts.StormWindow.Seconds()),
+			Time:          now.UTC().Format(time.RFC3339),
+			Example:       example,
+		}
+		w.result.Alerts = append(w.result.Alerts, alert)
+		w.emit(fmt.Sprintf("🚨 Warning storm: %d %s events for %s within %s: %s",
+			alert.Count, reason, component, w.opts.StormWindow, example))
+	case s.alerted && len(s.times) < w.opts.StormThreshold/2:
+		s.alerted = false
+	}
+}
+
+// reportRepeats summarises events that repeated since the last report
+func (w *watcher) reportRepeats() {
+	for _, agg := range w.sorted() {
+		if agg.unshown == 0 {
+			continue
+		}
+		w.emit(fmt.Sprintf("↻ %s %s %s repeated ×%d (total %d)", agg.event.Type, agg.event.Reason, agg.event.Object, agg.unshown, agg.event.Count))
+		agg.unshown = 0
+	}
+}
+
+// sorted returns the aggregates, most frequent first
+func (w *watcher) sorted() []*aggregate {
+	list := make([]*aggregate, 0, len(w.aggregates))
+	for _, agg := range w.aggregates {
+		list = append(list, agg)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].event.Count != list[j].event.Count {
+			return list[i].event.Count > list[j].event.Count
+		}
+		return list[i].first.Before(list[j].first)
+	})
+	return list
+}
+
+// finish reports the last repeats and fills in the result
+func (w *watcher) finish(ctx context.Context, start time.Time, err error) {
+	w.reportRepeats()
+	w.result.Status = "success"
+	w.result.Duration = time.Since(start).Round(time.Second).String()
+	switch {
+	case err != nil:
+		w.result.StopReason = "error"
+		w.result.Error = err.Error()
+	case ctx.Err() == context.DeadlineExceeded:
+		w.result.StopReason = "timeout"
+	default:
+		w.result.StopReason = "cancelled"
+	}
+	w.result.Events = []types.WatchedEvent{}
+	for _, agg := range w.sorted() {
+		agg.event.FirstSeen = agg.first.UTC().Format(time.RFC3339)
+		agg.event.LastSeen = agg.last.UTC().Format(time.RFC3339)
+		w.result.Events = append(w.result.Events, agg.event)
+	}
+	w.result.Unique = len(w.result.Events)
+}
+
+// MeshComponent names the mesh component an event concerns, or "" when it
+// concerns none: Sail resources, istiod, gateways, the node agents and pods
+// the injection or validation webhooks rejected
+func MeshComponent(e *eventsv1.Event) string {
+	switch e.Regarding.Kind {
+	case "Istio", "IstioRevision", "IstioRevisionTag", "IstioCNI", "ZTunnel":
+		return e.Regarding.Kind + "/" + e.Regarding.Name
+	}
+
+	note := e.Note
+	switch {
+	case strings.Contains(note, "sidecar-injector.istio.io"), strings.Contains(note, "istio-sidecar-injector"):
+		return "injection-webhook"
+	case strings.Contains(note, "validation.istio.io"), strings.Contains(note, "istio-validator"):
+		return "validation-webhook"
+	}
+
+	name := e.Regarding.Name
+	for _, prefix := range []string{"istiod", "istio-cni-node", "ztunnel", "istio-ingressgateway", "istio-egressgateway", "sail-operator"} {
+		if strings.HasPrefix(name, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// sleep waits for d or until ctx is done, reporting whether d passed
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
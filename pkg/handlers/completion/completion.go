@@ -0,0 +1,278 @@
+// Package completion implements MCP argument completion from live cluster data
+package completion
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/handlers/resources"
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+)
+
+const (
+	// cacheTTL is how long listed names are reused before the cluster is asked again
+	cacheTTL = 30 * time.Second
+	// maxValues is the most values a completion may return, per the MCP specification
+	maxValues = 100
+)
+
+// Completer answers completion/complete requests for prompt arguments and
+// resource template variables. Values are listed from the cluster and cached
+// briefly, since clients ask again on every keystroke.
+type Completer struct {
+	k8sClient     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+	registry      *sail.Registry
+
+	mu    sync.Mutex
+	cache map[string]cachedValues
+}
+
+type cachedValues struct {
+	values  []string
+	fetched time.Time
+}
+
+// NewCompleter creates a completer backed by the given clients
+func NewCompleter(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry) *Completer {
+	return &Completer{
+		k8sClient:     k8sClient,
+		dynamicClient: dynamicClient,
+		registry:      registry,
+		cache:         make(map[string]cachedValues),
+	}
+}
+
+// Complete implements mcp.ServerOptions.CompletionHandler
+func (c *Completer) Complete(ctx context.Context, ss *mcp.ServerSession, params *mcp.CompleteParams) (*mcp.CompleteResult, error) {
+	var known map[string]string
+	if params.Context != nil {
+		known = params.Context.Arguments
+	}
+
+	var candidates []string
+	if params.Ref != nil && params.Ref.Type == "ref/resource" {
+		candidates = c.templateCandidates(ctx, params.Ref.URI, params.Argument.Name, known)
+	} else {
+		candidates = c.argumentCandidates(ctx, params.Argument.Name, known)
+	}
+
+	return &mcp.CompleteResult{Completion: filter(candidates, params.Argument.Value)}, nil
+}
+
+// argumentCandidates completes an argument by its name. The names are those
+// of the prompts and, for clients that complete them, the tools.
+func (c *Completer) argumentCandidates(ctx context.Context, name string, known map[string]string) []string {
+	namespace := known["namespace"]
+
+	switch name {
+	case "namespace", "destination_namespace", "involved_namespace":
+		return c.namespaces(ctx)
+	case "pod_name", "pod":
+		return c.pods(ctx, namespace)
+	case "container":
+		return c.containers(ctx, namespace, firstNonEmpty(known["pod_name"], known["pod"], known["name"]))
+	case "workload", "source":
+		return c.deployments(ctx, namespace)
+	case "destination":
+		return c.services(ctx, firstNonEmpty(known["destination_namespace"], namespace))
+	case "istio":
+		return c.sailNames(ctx, sail.KindIstio)
+	case "revision", "from_revision", "to_revision":
+		return c.sailNames(ctx, sail.KindIstioRevision)
+	case "tag":
+		return c.sailNames(ctx, sail.KindIstioRevisionTag)
+	case "name":
+		// get_istio_status, get_istio_values and the write tools name an Istio
+		return c.sailNames(ctx, sail.KindIstio)
+	}
+	return nil
+}
+
+// templateCandidates completes a variable of one of the resource templates
+func (c *Completer) templateCandidates(ctx context.Context, template, variable string, known map[string]string) []string {
+	switch variable {
+	case "format":
+		return []string{"yaml", "json"}
+	case "previous":
+		return []string{"true", "false"}
+	}
+
+	if strings.HasPrefix(template, resources.SailScheme+"://") {
+		kindName := strings.SplitN(strings.TrimPrefix(template, resources.SailScheme+"://"), "/", 2)[0]
+		if kind, ok := sail.LookupKind(kindName); ok && variable == "name" {
+			return c.sailNames(ctx, kind)
+		}
+		return nil
+	}
+
+	switch variable {
+	case "namespace":
+		return c.namespaces(ctx)
+	case "name":
+		return c.pods(ctx, known["namespace"])
+	case "container":
+		return c.containers(ctx, known["namespace"], known["name"])
+	}
+	return nil
+}
+
+func (c *Completer) namespaces(ctx context.Context) []string {
+	return c.cached(ctx, "namespaces", func(ctx context.Context) ([]string, error) {
+		list, err := c.k8sClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, ns := range list.Items {
+			names = append(names, ns.Name)
+		}
+		return names, nil
+	})
+}
+
+// pods lists pod names in a namespace, or in all namespaces when none is chosen yet
+func (c *Completer) pods(ctx context.Context, namespace string) []string {
+	return c.cached(ctx, "pods/"+namespace, func(ctx context.Context) ([]string, error) {
+		list, err := c.k8sClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, pod := range list.Items {
+			names = append(names, pod.Name)
+		}
+		return names, nil
+	})
+}
+
+// containers lists the containers, including init containers, of the chosen pod
+func (c *Completer) containers(ctx context.Context, namespace, pod string) []string {
+	if namespace == "" || pod == "" {
+		return nil
+	}
+	return c.cached(ctx, "containers/"+namespace+"/"+pod, func(ctx context.Context) ([]string, error) {
+		p, err := c.k8sClient.CoreV1().Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, container := range p.Spec.Containers {
+			names = append(names, container.Name)
+		}
+		for _, container := range p.Spec.InitContainers {
+			names = append(names, container.Name)
+		}
+		return names, nil
+	})
+}
+
+func (c *Completer) deployments(ctx context.Context, namespace string) []string {
+	return c.cached(ctx, "deployments/"+namespace, func(ctx context.Context) ([]string, error) {
+		list, err := c.k8sClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, d := range list.Items {
+			names = append(names, d.Name)
+		}
+		return names, nil
+	})
+}
+
+func (c *Completer) services(ctx context.Context, namespace string) []string {
+	return c.cached(ctx, "services/"+namespace, func(ctx context.Context) ([]string, error) {
+		list, err := c.k8sClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, s := range list.Items {
+			names = append(names, s.Name)
+		}
+		return names, nil
+	})
+}
+
+// sailNames lists the installed resources of a Sail kind
+func (c *Completer) sailNames(ctx context.Context, kind sail.ResourceKind) []string {
+	return c.cached(ctx, "sail/"+kind.Kind, func(ctx context.Context) ([]string, error) {
+		gvr, err := c.registry.GVR(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		list, err := c.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, item := range list.Items {
+			names = append(names, item.GetName())
+		}
+		return names, nil
+	})
+}
+
+// cached returns the values stored under key, listing them again once they
+// are older than cacheTTL. Listing errors yield no values.
+func (c *Completer) cached(ctx context.Context, key string, list func(context.Context) ([]string, error)) []string {
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetched) < cacheTTL {
+		return entry.values
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	values, err := list(ctx)
+	if err != nil {
+		if !sail.IsNotInstalled(err) {
+			log.Printf("Completion lookup %s failed: %v", key, err)
+		}
+		return nil
+	}
+	sort.Strings(values)
+
+	c.mu.Lock()
+	c.cache[key] = cachedValues{values: values, fetched: time.Now()}
+	c.mu.Unlock()
+	return values
+}
+
+// filter keeps the candidates starting with prefix, case-insensitively
+func filter(candidates []string, prefix string) mcp.CompletionResultDetails {
+	prefix = strings.ToLower(prefix)
+	values := []string{}
+	for _, v := range candidates {
+		if strings.HasPrefix(strings.ToLower(v), prefix) {
+			values = append(values, v)
+		}
+	}
+
+	details := mcp.CompletionResultDetails{Values: values, Total: len(values)}
+	if len(values) > maxValues {
+		details.Values = values[:maxValues]
+		details.HasMore = true
+	}
+	return details
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,284 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/logs"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const (
+	defaultAccessLogLines = 1000
+	// minFindingShare is the smallest share of a status code's responses a
+	// response flag needs to be reported as a finding
+	minFindingShare = 0.1
+	maxFindings     = 20
+)
+
+// AnalyzeAccessLogs parses Envoy access logs of a sidecar or gateway, in
+// Istio's TEXT or JSON format, and aggregates them per destination
+func AnalyzeAccessLogs(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.AnalyzeAccessLogsParams]) (*mcp.CallToolResultFor[types.AnalyzeAccessLogsResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.AnalyzeAccessLogsParams]) (*mcp.CallToolResultFor[types.AnalyzeAccessLogsResult], error) {
+		args := params.Arguments
+
+		var lines []string
+		var source string
+		if args.Logs != "" {
+			lines = strings.Split(args.Logs, "\n")
+			source = "provided logs"
+		} else {
+			if args.Namespace == "" || args.PodName == "" {
+				return &mcp.CallToolResultFor[types.AnalyzeAccessLogsResult]{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: "Error: namespace and pod_name are required unless logs is given",
+					}},
+				}, nil
+			}
+			container := args.Container
+			if container == "" {
+				container = "istio-proxy"
+			}
+			source = fmt.Sprintf("%s/%s (container: %s)", args.Namespace, args.PodName, container)
+
+			tail := args.Lines
+			if tail <= 0 {
+				tail = defaultAccessLogLines
+			}
+			logOptions := &corev1.PodLogOptions{Container: container, TailLines: &tail}
+			if args.SinceSeconds > 0 {
+				logOptions.SinceSeconds = &args.SinceSeconds
+			}
+
+			var err error
+			lines, err = readLogLines(ctx, k8sClient, args.Namespace, args.PodName, logOptions)
+			if err != nil {
+				return &mcp.CallToolResultFor[types.AnalyzeAccessLogsResult]{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Error reading logs of %s: %v", source, err),
+					}},
+				}, nil
+			}
+		}
+
+		var entries []logs.AccessEntry
+		read := 0
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			read++
+			if entry, ok := logs.ParseAccessLog(line); ok {
+				entries = append(entries, entry)
+			}
+		}
+
+		result := summarizeAccessLogs(entries)
+		result.Status = "success"
+		result.Source = source
+		result.LinesRead = read
+		result.Skipped = read - len(entries)
+
+		return &mcp.CallToolResultFor[types.AnalyzeAccessLogsResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatAccessLogAnalysis(result)},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// isAccessLogError reports whether an entry counts as an error: a 5xx, or
+// no response code with a response flag, as for failed TCP connections
+func isAccessLogError(e logs.AccessEntry) bool {
+	return e.ResponseCode >= 500 || (e.ResponseCode == 0 && len(e.ResponseFlags) > 0)
+}
+
+// summarizeAccessLogs aggregates entries per destination and derives
+// findings such as "60% of 503s to reviews.default are UF"
+func summarizeAccessLogs(entries []logs.AccessEntry) types.AnalyzeAccessLogsResult {
+	type codeKey struct {
+		destination string
+		code        int
+	}
+	byDestination := map[string][]logs.AccessEntry{}
+	totalFlags := map[string]int{}
+	codeTotals := map[codeKey]int{}
+	codeFlags := map[codeKey]map[string]int{}
+	for _, e := range entries {
+		dest := e.Destination()
+		byDestination[dest] = append(byDestination[dest], e)
+		for _, f := range e.ResponseFlags {
+			totalFlags[f]++
+		}
+		if !isAccessLogError(e) && len(e.ResponseFlags) == 0 {
+			continue
+		}
+		key := codeKey{dest, e.ResponseCode}
+		codeTotals[key]++
+		if codeFlags[key] == nil {
+			codeFlags[key] = map[string]int{}
+		}
+		for _, f := range e.ResponseFlags {
+			codeFlags[key][f]++
+		}
+	}
+
+	result := types.AnalyzeAccessLogsResult{
+		Entries:       len(entries),
+		Destinations:  []types.AccessLogDestination{},
+		ResponseFlags: flagCounts(totalFlags),
+	}
+	for dest, destEntries := range byDestination {
+		d := types.AccessLogDestination{
+			Destination: dest,
+			Requests:    len(destEntries),
+			StatusCodes: map[string]int{},
+		}
+		flags := map[string]int{}
+		var durations []time.Duration
+		for _, e := range destEntries {
+			d.StatusCodes[strconv.Itoa(e.ResponseCode)]++
+			if isAccessLogError(e) {
+				d.Errors++
+			}
+			for _, f := range e.ResponseFlags {
+				flags[f]++
+			}
+			durations = append(durations, e.Duration)
+		}
+		d.ErrorRate = float64(d.Errors) / float64(d.Requests)
+		d.ResponseFlags = flagCounts(flags)
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		d.DurationP50Ms = percentile(durations, 0.50).Milliseconds()
+		d.DurationP95Ms = percentile(durations, 0.95).Milliseconds()
+		d.DurationMaxMs = durations[len(durations)-1].Milliseconds()
+		result.Destinations = append(result.Destinations, d)
+	}
+	sort.Slice(result.Destinations, func(i, j int) bool {
+		a, b := result.Destinations[i], result.Destinations[j]
+		if a.Errors != b.Errors {
+			return a.Errors > b.Errors
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Destination < b.Destination
+	})
+
+	// Findings for the most frequent failing codes first
+	keys := make([]codeKey, 0, len(codeTotals))
+	for key := range codeTotals {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if codeTotals[keys[i]] != codeTotals[keys[j]] {
+			return codeTotals[keys[i]] > codeTotals[keys[j]]
+		}
+		if keys[i].destination != keys[j].destination {
+			return keys[i].destination < keys[j].destination
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		total := codeTotals[key]
+		what := fmt.Sprintf("%ds", key.code)
+		if key.code == 0 {
+			what = "responses without a status code"
+		}
+		for _, fc := range flagCounts(codeFlags[key]) {
+			share := float64(fc.Count) / float64(total)
+			if share < minFindingShare || len(result.Findings) >= maxFindings {
+				continue
+			}
+			result.Findings = append(result.Findings, fmt.Sprintf("%.0f%% of %d %s to %s are %s (%s)",
+				share*100, total, what, key.destination, fc.Flag, fc.Meaning))
+		}
+		if len(codeFlags[key]) == 0 && key.code >= 500 && len(result.Findings) < maxFindings {
+			result.Findings = append(result.Findings, fmt.Sprintf("%d %s to %s carry no response flag, so the upstream service returned them itself",
+				total, what, key.destination))
+		}
+	}
+	return result
+}
+
+// flagCounts turns flag counts into a list, most frequent first
+func flagCounts(counts map[string]int) []types.ResponseFlagCount {
+	var list []types.ResponseFlagCount
+	for flag, count := range counts {
+		list = append(list, types.ResponseFlagCount{Flag: flag, Meaning: logs.ResponseFlagMeaning(flag), Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Flag < list[j].Flag
+	})
+	return list
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// formatAccessLogAnalysis formats the per-destination traffic summary
+func formatAccessLogAnalysis(result types.AnalyzeAccessLogsResult) string {
+	output := fmt.Sprintf("=== Access log analysis of %s ===\n", result.Source)
+	output += fmt.Sprintf("Lines read: %d, access log entries: %d, other lines: %d\n", result.LinesRead, result.Entries, result.Skipped)
+	if result.Entries == 0 {
+		return output + "\nNo access log entries found. Access logging may be disabled; enable it with meshConfig.accessLogFile: /dev/stdout or the Telemetry API."
+	}
+
+	if len(result.Findings) > 0 {
+		output += "\nFindings:\n"
+		for _, f := range result.Findings {
+			output += fmt.Sprintf("  • %s\n", f)
+		}
+	}
+
+	output += fmt.Sprintf("\nDestinations (%d):\n", len(result.Destinations))
+	for _, d := range result.Destinations {
+		icon := "✅"
+		if d.Errors > 0 {
+			icon = "❌"
+		}
+		output += fmt.Sprintf("\n%s %s: %d requests, %d errors (%.1f%%), p50 %dms, p95 %dms, max %dms\n",
+			icon, d.Destination, d.Requests, d.Errors, d.ErrorRate*100, d.DurationP50Ms, d.DurationP95Ms, d.DurationMaxMs)
+
+		codes := make([]string, 0, len(d.StatusCodes))
+		for code := range d.StatusCodes {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		var parts []string
+		for _, code := range codes {
+			parts = append(parts, fmt.Sprintf("%s×%d", code, d.StatusCodes[code]))
+		}
+		output += fmt.Sprintf("   Status codes: %s\n", strings.Join(parts, ", "))
+		for _, f := range d.ResponseFlags {
+			output += fmt.Sprintf("   %s ×%d: %s\n", f.Flag, f.Count, f.Meaning)
+		}
+	}
+
+	if len(result.ResponseFlags) > 0 {
+		output += "\nResponse flags seen:\n"
+		for _, f := range result.ResponseFlags {
+			output += fmt.Sprintf("  %s (%d): %s\n", f.Flag, f.Count, f.Meaning)
+		}
+	}
+	return output
+}
@@ -0,0 +1,583 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/logs"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const defaultDiagnoseLogLines = 50
+
+// Failure classifications of diagnose_pod, most decisive first: when several
+// apply, the pod is classified by the earliest in this list
+var diagnosisPriority = []string{
+	"image_pull_error",
+	"config_error",
+	"unschedulable",
+	"init_container_failure",
+	"oom_killed",
+	"liveness_probe_failure",
+	"sidecar_not_ready",
+	"crash_loop",
+	"readiness_probe_failure",
+	"restarted",
+}
+
+var diagnosisSummaries = map[string]string{
+	"image_pull_error":        "A container image cannot be pulled",
+	"config_error":            "A container cannot be created from its configuration",
+	"unschedulable":           "The pod cannot be scheduled on any node",
+	"init_container_failure":  "An init container fails, so the pod never starts",
+	"oom_killed":              "A container is killed for exceeding its memory limit",
+	"liveness_probe_failure":  "A container is restarted because its liveness probe fails",
+	"sidecar_not_ready":       "The istio-proxy sidecar is not ready, which blocks the application's traffic",
+	"crash_loop":              "A container keeps exiting with an error",
+	"readiness_probe_failure": "A container runs but its readiness probe fails",
+	"restarted":               "A container restarted in the past but is running now",
+	"healthy":                 "No problem found: all containers are running and ready",
+}
+
+// DiagnosePod explains why a pod is failing or restarting from its container
+// states, last terminations, events, probe and resource configuration and the
+// logs of the crashed container
+func DiagnosePod(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.DiagnosePodParams]) (*mcp.CallToolResultFor[types.DiagnosePodResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.DiagnosePodParams]) (*mcp.CallToolResultFor[types.DiagnosePodResult], error) {
+		args := params.Arguments
+		if args.Namespace == "" || args.PodName == "" {
+			return &mcp.CallToolResultFor[types.DiagnosePodResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: "Error: namespace and pod_name parameters are required"}},
+			}, nil
+		}
+
+		getCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		pod, err := k8sClient.CoreV1().Pods(args.Namespace).Get(getCtx, args.PodName, metav1.GetOptions{})
+		if err != nil {
+			return &mcp.CallToolResultFor[types.DiagnosePodResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error getting pod '%s' in namespace '%s': %v", args.PodName, args.Namespace, err),
+				}},
+			}, nil
+		}
+
+		result := types.DiagnosePodResult{
+			Status:     "success",
+			Namespace:  pod.Namespace,
+			Pod:        pod.Name,
+			Phase:      string(pod.Status.Phase),
+			NodeName:   pod.Spec.NodeName,
+			Containers: diagnoseContainers(pod),
+		}
+
+		// Events of the pod, as list_events with involved_kind=Pod would show them
+		fs := ""
+		appendFieldSelector(&fs, "regarding.kind", "Pod")
+		appendFieldSelector(&fs, "regarding.name", pod.Name)
+		el, err := k8sClient.EventsV1().Events(pod.Namespace).List(getCtx, metav1.ListOptions{FieldSelector: fs})
+		if err == nil {
+			for _, e := range el.Items {
+				result.Events = append(result.Events, eventInfo(e))
+			}
+		}
+
+		// Previous logs of the container that crashed
+		result.LogsContainer = args.Container
+		if result.LogsContainer == "" {
+			result.LogsContainer = crashedContainer(result.Containers)
+		}
+		var logLines []string
+		if result.LogsContainer != "" {
+			tail := args.LogLines
+			if tail <= 0 {
+				tail = defaultDiagnoseLogLines
+			}
+			logLines, err = readLogLines(ctx, k8sClient, pod.Namespace, pod.Name, &corev1.PodLogOptions{
+				Container: result.LogsContainer,
+				Previous:  true,
+				TailLines: &tail,
+			})
+			if err != nil {
+				result.LogsError = err.Error()
+			}
+			result.PreviousLogs = strings.Join(logLines, "\n")
+		}
+
+		classifyPod(pod, &result, logLines)
+
+		return &mcp.CallToolResultFor[types.DiagnosePodResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatPodDiagnosis(result)},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// diagnoseContainers describes the init and regular containers of a pod
+func diagnoseContainers(pod *corev1.Pod) []types.ContainerDiagnosis {
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		statuses[s.Name] = s
+	}
+
+	var containers []types.ContainerDiagnosis
+	describe := func(c corev1.Container, init bool) {
+		d := types.ContainerDiagnosis{
+			Name:     c.Name,
+			Init:     init,
+			Requests: resourceStrings(c.Resources.Requests),
+			Limits:   resourceStrings(c.Resources.Limits),
+			Probes:   probeInfos(c),
+		}
+		status, ok := statuses[c.Name]
+		if !ok {
+			d.State = "waiting"
+			containers = append(containers, d)
+			return
+		}
+		d.Ready = status.Ready
+		d.RestartCount = status.RestartCount
+		switch {
+		case status.State.Running != nil:
+			d.State = "running"
+		case status.State.Waiting != nil:
+			d.State = "waiting"
+			d.Reason = status.State.Waiting.Reason
+			d.Message = status.State.Waiting.Message
+		case status.State.Terminated != nil:
+			d.State = "terminated"
+			d.Reason = status.State.Terminated.Reason
+			d.Message = status.State.Terminated.Message
+		}
+		if t := status.LastTerminationState.Terminated; t != nil {
+			d.LastTermination = terminationInfo(t)
+		} else if t := status.State.Terminated; t != nil && t.ExitCode != 0 {
+			d.LastTermination = terminationInfo(t)
+		}
+		containers = append(containers, d)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		describe(c, true)
+	}
+	for _, c := range pod.Spec.Containers {
+		describe(c, false)
+	}
+	return containers
+}
+
+func terminationInfo(t *corev1.ContainerStateTerminated) *types.ContainerTermination {
+	info := &types.ContainerTermination{
+		Reason:   t.Reason,
+		ExitCode: t.ExitCode,
+		Signal:   t.Signal,
+		Message:  strings.TrimSpace(t.Message),
+	}
+	if !t.StartedAt.IsZero() {
+		info.StartedAt = t.StartedAt.Time.Format(time.RFC3339)
+	}
+	if !t.FinishedAt.IsZero() {
+		info.FinishedAt = t.FinishedAt.Time.Format(time.RFC3339)
+	}
+	return info
+}
+
+func resourceStrings(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(list))
+	for name, quantity := range list {
+		out[string(name)] = quantity.String()
+	}
+	return out
+}
+
+func probeInfos(c corev1.Container) []types.ProbeInfo {
+	var probes []types.ProbeInfo
+	for _, p := range []struct {
+		kind  string
+		probe *corev1.Probe
+	}{{"liveness", c.LivenessProbe}, {"readiness", c.ReadinessProbe}, {"startup", c.StartupProbe}} {
+		if p.probe == nil {
+			continue
+		}
+		probes = append(probes, types.ProbeInfo{
+			Type:                p.kind,
+			Handler:             probeHandler(p.probe),
+			InitialDelaySeconds: p.probe.InitialDelaySeconds,
+			PeriodSeconds:       p.probe.PeriodSeconds,
+			TimeoutSeconds:      p.probe.TimeoutSeconds,
+			FailureThreshold:    p.probe.FailureThreshold,
+		})
+	}
+	return probes
+}
+
+func probeHandler(p *corev1.Probe) string {
+	switch {
+	case p.HTTPGet != nil:
+		return fmt.Sprintf("httpGet :%s%s", p.HTTPGet.Port.String(), p.HTTPGet.Path)
+	case p.TCPSocket != nil:
+		return fmt.Sprintf("tcpSocket :%s", p.TCPSocket.Port.String())
+	case p.GRPC != nil:
+		return fmt.Sprintf("grpc :%d", p.GRPC.Port)
+	case p.Exec != nil:
+		return "exec " + strings.Join(p.Exec.Command, " ")
+	}
+	return "unknown"
+}
+
+// crashedContainer picks the container whose previous logs explain the
+// failure: the one that terminated abnormally with the most restarts
+func crashedContainer(containers []types.ContainerDiagnosis) string {
+	best, restarts := "", int32(-1)
+	for _, c := range containers {
+		if c.LastTermination != nil && c.RestartCount > restarts {
+			best, restarts = c.Name, c.RestartCount
+		}
+	}
+	return best
+}
+
+// classifyPod records findings and recommendations and classifies the failure
+func classifyPod(pod *corev1.Pod, result *types.DiagnosePodResult, logLines []string) {
+	found := map[string]bool{}
+	add := func(category, severity, container, message string) {
+		found[category] = true
+		result.Findings = append(result.Findings, types.DiagnosisFinding{
+			Severity: severity, Category: category, Container: container, Message: message,
+		})
+	}
+	recommend := func(r string) {
+		for _, existing := range result.Recommendations {
+			if existing == r {
+				return
+			}
+		}
+		result.Recommendations = append(result.Recommendations, r)
+	}
+
+	hasSidecar := false
+	for _, c := range result.Containers {
+		if c.Name == "istio-proxy" {
+			hasSidecar = true
+		}
+	}
+
+	for _, c := range result.Containers {
+		switch c.Reason {
+		case "ImagePullBackOff", "ErrImagePull", "InvalidImageName", "ErrImageNeverPull":
+			add("image_pull_error", "error", c.Name, fmt.Sprintf("%s: %s", c.Reason, c.Message))
+			recommend("Check the image name and tag, that the registry is reachable from the node and that imagePullSecrets grant access")
+		case "CreateContainerConfigError", "CreateContainerError", "RunContainerError":
+			add("config_error", "error", c.Name, fmt.Sprintf("%s: %s", c.Reason, c.Message))
+			recommend("Check that the ConfigMaps, Secrets and volumes the container references exist")
+		}
+
+		// Only a container waiting in CrashLoopBackOff or stopped is failing
+		// now; one running again after an earlier exit merely restarted
+		failing := c.Reason == "CrashLoopBackOff" || c.State == "terminated"
+		if t := c.LastTermination; t != nil {
+			switch {
+			case t.Reason == "OOMKilled":
+				limit := c.Limits["memory"]
+				if limit == "" {
+					limit = "none set; the node ran out of memory"
+				}
+				add("oom_killed", "error", c.Name, fmt.Sprintf("Killed for exceeding its memory (limit: %s), %d restarts", limit, c.RestartCount))
+				if c.Name == "istio-proxy" {
+					recommend("Raise the sidecar memory limit with the sidecar.istio.io/proxyMemoryLimit annotation, or reduce the configuration pushed to it with a Sidecar resource")
+				} else {
+					recommend(fmt.Sprintf("Raise the memory limit of container '%s' or find what grows its memory use", c.Name))
+				}
+			case c.Init && t.ExitCode != 0:
+				add("init_container_failure", "error", c.Name, fmt.Sprintf("Init container exited with code %d (%s)", t.ExitCode, t.Reason))
+				if c.Name == "istio-init" || c.Name == "istio-validation" {
+					recommend("The Istio init container failed: check the istio-cni node agent on this node when CNI is used, or that the pod may run the NET_ADMIN init container otherwise")
+				}
+			case !failing:
+				if c.RestartCount > 0 && !c.Init {
+					addRestarted(c, t, add)
+				}
+			case (t.ExitCode == 137 || t.ExitCode == 143) && hasProbe(c, "liveness"):
+				// Events about failed probes expire after an hour; the exit code remains
+				add("liveness_probe_failure", "warning", c.Name, fmt.Sprintf("Killed by a signal (exit code %d) and has a liveness probe, so the kubelet likely restarted it after failed probes; %d restarts", t.ExitCode, c.RestartCount))
+				recommend("Make sure the liveness probe allows for slow starts (add a startupProbe or raise initialDelaySeconds and failureThreshold)")
+			case t.ExitCode != 0 && !c.Init:
+				message := fmt.Sprintf("Last exited with code %d (%s)", t.ExitCode, t.Reason)
+				if t.Message != "" {
+					message += ": " + t.Message
+				}
+				add("crash_loop", "error", c.Name, fmt.Sprintf("%s, %d restarts", message, c.RestartCount))
+			}
+		}
+		if c.Reason == "CrashLoopBackOff" && c.LastTermination == nil {
+			add("crash_loop", "error", c.Name, fmt.Sprintf("In CrashLoopBackOff with %d restarts", c.RestartCount))
+		}
+
+		if c.Name == "istio-proxy" && !c.Ready && pod.Status.Phase == corev1.PodRunning {
+			add("sidecar_not_ready", "error", c.Name, "The istio-proxy sidecar is not ready, so the application receives no mesh traffic")
+			recommend("Check that istiod is reachable from the pod and look at the istio-proxy logs for xDS connection errors")
+		}
+
+		checkContainerConfig(c, add, recommend)
+	}
+
+	if pod.Status.QOSClass == corev1.PodQOSBestEffort {
+		add("resources", "warning", "", "No container sets resource requests, so the pod is BestEffort and evicted first under node pressure")
+		recommend("Set CPU and memory requests so the scheduler reserves capacity for the pod")
+	}
+
+	for _, e := range result.Events {
+		if e.Type != "Warning" {
+			continue
+		}
+		msg := e.Message
+		switch {
+		case e.Reason == "FailedScheduling":
+			add("unschedulable", "error", "", msg)
+			recommend("Check node capacity, taints and tolerations, node selectors and affinity against the pod's requests")
+		case e.Reason == "Unhealthy" && strings.HasPrefix(msg, "Liveness probe failed"):
+			add("liveness_probe_failure", "error", "", msg)
+			recommend("Make sure the liveness probe allows for slow starts (add a startupProbe or raise initialDelaySeconds and failureThreshold)")
+		case e.Reason == "Unhealthy" && strings.HasPrefix(msg, "Readiness probe failed"):
+			if strings.Contains(msg, ":15021/healthz/ready") {
+				add("sidecar_not_ready", "error", "istio-proxy", msg)
+				recommend("Check that istiod is reachable from the pod and look at the istio-proxy logs for xDS connection errors")
+			} else {
+				add("readiness_probe_failure", "warning", "", msg)
+			}
+		case e.Reason == "Killing" && strings.Contains(msg, "failed liveness probe"):
+			add("liveness_probe_failure", "error", "", msg)
+		case e.Reason == "FailedMount" || e.Reason == "FailedAttachVolume":
+			add("config_error", "error", "", msg)
+		case e.Reason == "FailedCreatePodSandBox" && strings.Contains(msg, "istio-cni"):
+			add("init_container_failure", "error", "", msg)
+			recommend("The istio-cni plugin failed to set up the pod network: check the istio-cni node agent on this node")
+		}
+	}
+
+	// An app that fails to reach the network right after start, while the
+	// sidecar is not yet ready, exits before the proxy can serve it
+	if hasSidecar && found["crash_loop"] && containsAny(logLines, "connection refused", "no healthy upstream", "dial tcp") {
+		add("sidecar_not_ready", "warning", result.LogsContainer, "The previous logs show connection failures, which happens when the application starts before the sidecar is ready")
+		recommend("Set holdApplicationUntilProxyStarts: true in the pod's proxy.istio.io/config annotation so the application waits for the sidecar")
+	}
+
+	// Repeated errors in the crashed container's logs
+	var parsed []logs.Line
+	for _, l := range logLines {
+		parsed = append(parsed, logs.Parse(l))
+	}
+	for _, s := range logs.Summarize(parsed, 3) {
+		if s.Level == logs.LevelError.String() {
+			add("log_error", "info", result.LogsContainer, fmt.Sprintf("Previous logs: %s (×%d)", s.Example, s.Count))
+		}
+	}
+
+	result.Classification = "healthy"
+	for _, category := range diagnosisPriority {
+		if found[category] {
+			result.Classification = category
+			break
+		}
+	}
+	result.Summary = diagnosisSummaries[result.Classification]
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		return severityRank(result.Findings[i].Severity) < severityRank(result.Findings[j].Severity)
+	})
+}
+
+// addRestarted reports a container that restarted and runs again, with how
+// it last exited
+func addRestarted(c types.ContainerDiagnosis, t *types.ContainerTermination, add func(category, severity, container, message string)) {
+	if t.ExitCode == 0 {
+		add("restarted", "info", c.Name, fmt.Sprintf("Restarted %d times, last exit was clean", c.RestartCount))
+		return
+	}
+	message := fmt.Sprintf("Restarted %d times and running again, last exited with code %d (%s)", c.RestartCount, t.ExitCode, t.Reason)
+	if (t.ExitCode == 137 || t.ExitCode == 143) && hasProbe(c, "liveness") {
+		message += ", likely after failed liveness probes"
+	}
+	if t.FinishedAt != "" {
+		message += " at " + t.FinishedAt
+	}
+	add("restarted", "warning", c.Name, message)
+}
+
+// checkContainerConfig reports risky probe and resource settings
+func checkContainerConfig(c types.ContainerDiagnosis, add func(category, severity, container, message string), recommend func(string)) {
+	var liveness, readiness, startup *types.ProbeInfo
+	for i := range c.Probes {
+		switch c.Probes[i].Type {
+		case "liveness":
+			liveness = &c.Probes[i]
+		case "readiness":
+			readiness = &c.Probes[i]
+		case "startup":
+			startup = &c.Probes[i]
+		}
+	}
+
+	if liveness != nil {
+		if startup == nil && liveness.InitialDelaySeconds == 0 {
+			add("probe_config", "warning", c.Name, "Liveness probe starts immediately and there is no startupProbe; a slow start gets the container killed")
+		}
+		if liveness.TimeoutSeconds <= 1 {
+			add("probe_config", "info", c.Name, "Liveness probe times out after 1s; a busy container may fail it spuriously")
+		}
+		if readiness != nil && liveness.Handler == readiness.Handler && liveness.FailureThreshold <= readiness.FailureThreshold {
+			add("probe_config", "info", c.Name, "Liveness and readiness probes check the same endpoint; an overloaded container gets restarted instead of just taken out of rotation")
+		}
+	}
+	if !c.Init && c.Name != "istio-proxy" {
+		if c.Limits["memory"] == "" {
+			add("resources", "info", c.Name, "No memory limit set")
+		}
+	}
+}
+
+func hasProbe(c types.ContainerDiagnosis, kind string) bool {
+	for _, p := range c.Probes {
+		if p.Type == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(lines []string, needles ...string) bool {
+	for _, l := range lines {
+		lower := strings.ToLower(l)
+		for _, n := range needles {
+			if strings.Contains(lower, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "error":
+		return 0
+	case "warning":
+		return 1
+	}
+	return 2
+}
+
+// formatPodDiagnosis formats a pod diagnosis
+func formatPodDiagnosis(result types.DiagnosePodResult) string {
+	output := fmt.Sprintf("=== Diagnosis of pod '%s' in namespace '%s' ===\n", result.Pod, result.Namespace)
+	output += fmt.Sprintf("Phase: %s", result.Phase)
+	if result.NodeName != "" {
+		output += fmt.Sprintf(", node: %s", result.NodeName)
+	}
+	icon := "❌"
+	if result.Classification == "healthy" {
+		icon = "✅"
+	} else if result.Classification == "restarted" || result.Classification == "readiness_probe_failure" {
+		icon = "⚠️"
+	}
+	output += fmt.Sprintf("\n\n%s Classification: %s\n%s\n", icon, result.Classification, result.Summary)
+
+	output += "\nContainers:\n"
+	for _, c := range result.Containers {
+		kind := ""
+		if c.Init {
+			kind = " (init)"
+		}
+		output += fmt.Sprintf("  • %s%s: %s", c.Name, kind, c.State)
+		if c.Reason != "" {
+			output += fmt.Sprintf(" (%s)", c.Reason)
+		}
+		output += fmt.Sprintf(", ready: %t, restarts: %d\n", c.Ready, c.RestartCount)
+		if t := c.LastTermination; t != nil {
+			output += fmt.Sprintf("    Last termination: %s, exit code %d", t.Reason, t.ExitCode)
+			if t.FinishedAt != "" {
+				output += fmt.Sprintf(" at %s", t.FinishedAt)
+			}
+			output += "\n"
+		}
+		if len(c.Limits) > 0 || len(c.Requests) > 0 {
+			output += fmt.Sprintf("    Requests: %s, limits: %s\n", formatResourceMap(c.Requests), formatResourceMap(c.Limits))
+		}
+		for _, p := range c.Probes {
+			output += fmt.Sprintf("    %s probe: %s, delay %ds, period %ds, timeout %ds, threshold %d\n",
+				p.Type, p.Handler, p.InitialDelaySeconds, p.PeriodSeconds, p.TimeoutSeconds, p.FailureThreshold)
+		}
+	}
+
+	if len(result.Findings) > 0 {
+		output += "\nFindings:\n"
+		for _, f := range result.Findings {
+			icon := "ℹ️"
+			switch f.Severity {
+			case "error":
+				icon = "❌"
+			case "warning":
+				icon = "⚠️"
+			}
+			target := ""
+			if f.Container != "" {
+				target = f.Container + ": "
+			}
+			output += fmt.Sprintf("  %s [%s] %s%s\n", icon, f.Category, target, f.Message)
+		}
+	}
+
+	if len(result.Recommendations) > 0 {
+		output += "\nRecommendations:\n"
+		for _, r := range result.Recommendations {
+			output += fmt.Sprintf("  • %s\n", r)
+		}
+	}
+
+	if len(result.Events) > 0 {
+		output += fmt.Sprintf("\nEvents (%d):\n", len(result.Events))
+		for _, e := range result.Events {
+			output += fmt.Sprintf("  %-8s %-20s ×%-4d %s\n", e.Type, e.Reason, e.Count, truncateString(e.Message, 100))
+		}
+	}
+
+	if result.LogsContainer != "" {
+		output += fmt.Sprintf("\nPrevious logs of '%s':\n", result.LogsContainer)
+		switch {
+		case result.LogsError != "":
+			output += fmt.Sprintf("  (unavailable: %s)\n", result.LogsError)
+		case result.PreviousLogs == "":
+			output += "  (empty)\n"
+		default:
+			output += result.PreviousLogs + "\n"
+		}
+	}
+	return output
+}
+
+func formatResourceMap(m map[string]string) string {
+	if len(m) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, m[k]))
+	}
+	return strings.Join(parts, " ")
+}
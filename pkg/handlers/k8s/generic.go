@@ -0,0 +1,563 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const (
+	defaultResourceLimit = 100
+	// maxDescribed is how many resources a selector may describe at once
+	maxDescribed = 10
+	// maxDescribedFields caps the spec and status fields shown per resource
+	maxDescribedFields = 60
+	// maxRelatedEvents is how many of the newest events are shown per resource
+	maxRelatedEvents = 20
+)
+
+// GetResource gets or lists resources of any kind the cluster serves
+func GetResource(dynamicClient dynamic.Interface, mapper *ResourceMapper) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetResourceParams]) (*mcp.CallToolResultFor[types.GetResourceResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetResourceParams]) (*mcp.CallToolResultFor[types.GetResourceResult], error) {
+		args := params.Arguments
+		if args.Output != "" && args.Output != "summary" && args.Output != "yaml" && args.Output != "json" {
+			return &mcp.CallToolResultFor[types.GetResourceResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error: unsupported output '%s', use summary, yaml or json", args.Output),
+				}},
+			}, nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		mapping, err := mapper.Mapping(args.Kind)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.GetResourceResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
+		}
+		limit := args.Limit
+		if limit <= 0 {
+			limit = defaultResourceLimit
+		}
+
+		items, more, err := getObjects(ctx, dynamicClient, mapping, args.Namespace, args.Name, args.LabelSelector, args.FieldSelector, limit)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.GetResourceResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error getting %s: %v", mapping.Resource.Resource, err),
+				}},
+			}, nil
+		}
+
+		result := types.GetResourceResult{
+			Status:     "success",
+			APIVersion: mapping.GroupVersionKind.GroupVersion().String(),
+			Kind:       mapping.GroupVersionKind.Kind,
+			Resource:   mapping.Resource.Resource,
+			Namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+			Items:      []types.ResourceSummary{},
+			Count:      len(items),
+			More:       more,
+		}
+		for _, obj := range items {
+			result.Items = append(result.Items, resourceSummary(obj))
+		}
+
+		var output string
+		switch args.Output {
+		case "yaml", "json":
+			output, err = encodeManifests(items, args.Output)
+			if err != nil {
+				return &mcp.CallToolResultFor[types.GetResourceResult]{
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+				}, nil
+			}
+		default:
+			output = formatResourceSummaries(result)
+		}
+
+		return &mcp.CallToolResultFor[types.GetResourceResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// DescribeResource describes resources of any kind the cluster serves like
+// kubectl describe: metadata, owners, spec and status fields, conditions and
+// the events about each resource
+func DescribeResource(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, mapper *ResourceMapper) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.DescribeResourceParams]) (*mcp.CallToolResultFor[types.DescribeResourceResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.DescribeResourceParams]) (*mcp.CallToolResultFor[types.DescribeResourceResult], error) {
+		args := params.Arguments
+		if args.Name == "" && args.LabelSelector == "" {
+			return &mcp.CallToolResultFor[types.DescribeResourceResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: "Error: name or label_selector is required",
+				}},
+			}, nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		mapping, err := mapper.Mapping(args.Kind)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.DescribeResourceResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
+		}
+
+		items, more, err := getObjects(ctx, dynamicClient, mapping, args.Namespace, args.Name, args.LabelSelector, "", maxDescribed)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.DescribeResourceResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error getting %s: %v", mapping.Resource.Resource, err),
+				}},
+			}, nil
+		}
+
+		result := types.DescribeResourceResult{Status: "success", Count: len(items), More: more}
+		for _, obj := range items {
+			d := describeObject(obj)
+			d.Events = relatedEvents(ctx, k8sClient, obj)
+			result.Resources = append(result.Resources, d)
+		}
+
+		var output string
+		if len(result.Resources) == 0 {
+			output = fmt.Sprintf("No %s found", mapping.Resource.Resource)
+		}
+		for i, d := range result.Resources {
+			if i > 0 {
+				output += "\n" + strings.Repeat("-", 60) + "\n\n"
+			}
+			output += formatResourceDescription(d)
+		}
+		if more {
+			output += fmt.Sprintf("\n... more %s match; narrow the label_selector to describe them\n", mapping.Resource.Resource)
+		}
+
+		return &mcp.CallToolResultFor[types.DescribeResourceResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// getObjects gets the named object, or lists objects by selector up to
+// limit, reporting whether more exist. The namespace is ignored for
+// cluster-scoped resources.
+func getObjects(ctx context.Context, dynamicClient dynamic.Interface, mapping *meta.RESTMapping, namespace, name, labelSelector, fieldSelector string, limit int64) ([]*unstructured.Unstructured, bool, error) {
+	var client dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		client = dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+	} else {
+		namespace = ""
+	}
+
+	if name != "" {
+		if namespace == "" && mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			return nil, false, fmt.Errorf("namespace is required to get %s '%s'", mapping.Resource.Resource, name)
+		}
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		return []*unstructured.Unstructured{redactSecret(obj)}, false, nil
+	}
+
+	list, err := client.List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+		FieldSelector: fieldSelector,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	items := make([]*unstructured.Unstructured, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, redactSecret(&list.Items[i]))
+	}
+	return items, list.GetContinue() != "", nil
+}
+
+// redactSecret replaces the values of a Secret's data and stringData with
+// their size, as kubectl describe shows them, so Secret contents never reach
+// the client. The last-applied-configuration annotation, which repeats
+// them, is dropped. Other objects are returned unchanged.
+func redactSecret(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj.GetKind() != "Secret" || obj.GroupVersionKind().Group != "" {
+		return obj
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := obj.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, value := range values {
+			size := 0
+			if s, ok := value.(string); ok {
+				size = len(s)
+				if field == "data" {
+					size = base64.StdEncoding.DecodedLen(len(s)) - strings.Count(s, "=")
+				}
+			}
+			values[key] = fmt.Sprintf("<redacted: %d bytes>", size)
+		}
+	}
+	if annotations := obj.GetAnnotations(); annotations["kubectl.kubernetes.io/last-applied-configuration"] != "" {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		obj.SetAnnotations(annotations)
+	}
+	return obj
+}
+
+// resourceSummary summarises an object for a listing
+func resourceSummary(obj *unstructured.Unstructured) types.ResourceSummary {
+	s := types.ResourceSummary{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Status:     objectStatus(obj),
+		Age:        formatAge(obj.GetCreationTimestamp().Time),
+	}
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		s.Owner = owner.Kind + "/" + owner.Name
+	}
+	return s
+}
+
+// objectStatus derives a one-word status the way most kinds report it: a
+// phase, a Ready, Available or Complete condition, or replica counts
+func objectStatus(obj *unstructured.Unstructured) string {
+	if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != "" {
+		return phase
+	}
+
+	conditions := objectConditions(obj)
+	for _, want := range []string{"Ready", "Available", "Complete", "Failed", "Established"} {
+		for _, c := range conditions {
+			if c.Type != want {
+				continue
+			}
+			switch {
+			case c.Status == "True":
+				return want
+			case want == "Complete" || want == "Failed":
+				continue
+			case c.Reason != "":
+				return fmt.Sprintf("Not%s (%s)", want, c.Reason)
+			default:
+				return "Not" + want
+			}
+		}
+	}
+
+	if desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		return fmt.Sprintf("%d/%d ready", ready, desired)
+	}
+	if desired, found, _ := unstructured.NestedInt64(obj.Object, "status", "desiredReplicas"); found {
+		current, _, _ := unstructured.NestedInt64(obj.Object, "status", "currentReplicas")
+		return fmt.Sprintf("%d/%d replicas", current, desired)
+	}
+	return ""
+}
+
+// objectConditions reads status.conditions in the common metav1.Condition shape
+func objectConditions(obj *unstructured.Unstructured) []types.ResourceCondition {
+	raw, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	var conditions []types.ResourceCondition
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		c := types.ResourceCondition{}
+		c.Type, _ = m["type"].(string)
+		c.Status, _ = m["status"].(string)
+		c.Reason, _ = m["reason"].(string)
+		c.Message, _ = m["message"].(string)
+		c.LastTransitionTime, _ = m["lastTransitionTime"].(string)
+		if c.Type != "" {
+			conditions = append(conditions, c)
+		}
+	}
+	return conditions
+}
+
+// describeObject builds the describe-style summary of an object
+func describeObject(obj *unstructured.Unstructured) types.ResourceDescription {
+	d := types.ResourceDescription{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		UID:        string(obj.GetUID()),
+		Created:    obj.GetCreationTimestamp().Format(time.RFC3339),
+		Age:        formatAge(obj.GetCreationTimestamp().Time),
+		Status:     objectStatus(obj),
+		Labels:     obj.GetLabels(),
+		Conditions: objectConditions(obj),
+	}
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	if len(annotations) > 0 {
+		d.Annotations = annotations
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		d.Owners = append(d.Owners, types.OwnerReferenceInfo{
+			Kind:       ref.Kind,
+			Name:       ref.Name,
+			Controller: ref.Controller != nil && *ref.Controller,
+		})
+	}
+
+	if spec, ok := obj.Object["spec"].(map[string]interface{}); ok {
+		d.Spec = map[string]string{}
+		flattenFields(spec, "", d.Spec)
+	}
+	if status, ok := obj.Object["status"].(map[string]interface{}); ok {
+		d.StatusFields = map[string]string{}
+		flattenFields(status, "", d.StatusFields)
+		delete(d.StatusFields, "conditions")
+	}
+	// Kinds without spec and status, like ConfigMaps, keep their payload at the top
+	if d.Spec == nil && d.StatusFields == nil {
+		d.Spec = map[string]string{}
+		for key, value := range obj.Object {
+			if key != "apiVersion" && key != "kind" && key != "metadata" {
+				flattenFields(map[string]interface{}{key: value}, "", d.Spec)
+			}
+		}
+	}
+	return d
+}
+
+// flattenFields collects the scalar fields of a nested object by dotted path,
+// up to maxDescribedFields. Lists of scalars are joined; lists of objects are
+// shown by their names when they have them, otherwise by their length.
+func flattenFields(obj map[string]interface{}, prefix string, fields map[string]string) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if len(fields) >= maxDescribedFields {
+			return
+		}
+		path := prefix + key
+		switch value := obj[key].(type) {
+		case map[string]interface{}:
+			if len(value) == 0 {
+				continue
+			}
+			flattenFields(value, path+".", fields)
+		case []interface{}:
+			if len(value) == 0 {
+				continue
+			}
+			var parts []string
+			for _, item := range value {
+				switch item := item.(type) {
+				case map[string]interface{}:
+					if name, ok := item["name"].(string); ok {
+						parts = append(parts, name)
+					}
+				case string, bool, int64, float64:
+					parts = append(parts, fmt.Sprint(item))
+				}
+			}
+			if len(parts) == len(value) {
+				fields[path] = truncateString(strings.Join(parts, ", "), 120)
+			} else {
+				fields[path] = fmt.Sprintf("[%d items]", len(value))
+			}
+		case nil:
+			continue
+		default:
+			fields[path] = truncateString(fmt.Sprint(value), 120)
+		}
+	}
+}
+
+// relatedEvents returns the newest events about an object, oldest first.
+// Failures only leave the events out; the description is still useful.
+func relatedEvents(ctx context.Context, k8sClient *kubernetes.Clientset, obj *unstructured.Unstructured) []types.EventInfo {
+	fs := ""
+	appendFieldSelector(&fs, "regarding.kind", obj.GetKind())
+	appendFieldSelector(&fs, "regarding.name", obj.GetName())
+	list, err := k8sClient.EventsV1().Events(obj.GetNamespace()).List(ctx, metav1.ListOptions{FieldSelector: fs})
+	if err != nil {
+		return nil
+	}
+
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		_, a := eventTimes(items[i])
+		_, b := eventTimes(items[j])
+		return a.Before(b)
+	})
+	if len(items) > maxRelatedEvents {
+		items = items[len(items)-maxRelatedEvents:]
+	}
+	var events []types.EventInfo
+	for _, e := range items {
+		if e.Regarding.UID != "" && e.Regarding.UID != obj.GetUID() {
+			continue // an earlier object of the same name
+		}
+		events = append(events, eventInfo(e))
+	}
+	return events
+}
+
+// encodeManifests encodes objects as YAML documents or a JSON list, without
+// their managed fields
+func encodeManifests(items []*unstructured.Unstructured, format string) (string, error) {
+	objects := make([]map[string]interface{}, 0, len(items))
+	for _, obj := range items {
+		obj = obj.DeepCopy()
+		obj.SetManagedFields(nil)
+		objects = append(objects, obj.Object)
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(objects, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return string(data), nil
+	}
+	var docs []string
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		docs = append(docs, string(data))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// formatResourceSummaries formats a generic resource listing
+func formatResourceSummaries(result types.GetResourceResult) string {
+	if len(result.Items) == 0 {
+		return fmt.Sprintf("No %s found", result.Resource)
+	}
+
+	output := fmt.Sprintf("Found %d %s (%s):\n\n", result.Count, result.Resource, result.APIVersion)
+	if result.Namespaced {
+		output += fmt.Sprintf("%-40s %-20s %-24s %-30s %s\n", "NAME", "NAMESPACE", "STATUS", "OWNER", "AGE")
+	} else {
+		output += fmt.Sprintf("%-40s %-20s %-24s %-30s %s\n", "NAME", "", "STATUS", "OWNER", "AGE")
+	}
+	output += strings.Repeat("-", 120) + "\n"
+	for _, item := range result.Items {
+		output += fmt.Sprintf("%-40s %-20s %-24s %-30s %s\n",
+			truncateString(item.Name, 39),
+			truncateString(item.Namespace, 19),
+			truncateString(item.Status, 23),
+			truncateString(item.Owner, 29),
+			item.Age,
+		)
+	}
+	if result.More {
+		output += fmt.Sprintf("\n... more %s exist; raise limit or narrow the selectors\n", result.Resource)
+	}
+	return output
+}
+
+// formatResourceDescription formats one resource like kubectl describe
+func formatResourceDescription(d types.ResourceDescription) string {
+	output := fmt.Sprintf("Name:         %s\n", d.Name)
+	if d.Namespace != "" {
+		output += fmt.Sprintf("Namespace:    %s\n", d.Namespace)
+	}
+	output += fmt.Sprintf("Kind:         %s (%s)\n", d.Kind, d.APIVersion)
+	output += fmt.Sprintf("Created:      %s (%s ago)\n", d.Created, d.Age)
+	if d.Status != "" {
+		output += fmt.Sprintf("Status:       %s\n", d.Status)
+	}
+	output += formatStringMap("Labels", d.Labels)
+	output += formatStringMap("Annotations", d.Annotations)
+
+	if len(d.Owners) > 0 {
+		output += "Owned by:\n"
+		for _, o := range d.Owners {
+			controller := ""
+			if o.Controller {
+				controller = " (controller)"
+			}
+			output += fmt.Sprintf("  %s/%s%s\n", o.Kind, o.Name, controller)
+		}
+	}
+
+	output += formatStringMap("Spec", d.Spec)
+	output += formatStringMap("Status fields", d.StatusFields)
+
+	if len(d.Conditions) > 0 {
+		output += "Conditions:\n"
+		for _, c := range d.Conditions {
+			output += fmt.Sprintf("  %-22s %-6s", c.Type, c.Status)
+			if c.Reason != "" {
+				output += " " + c.Reason
+			}
+			if c.Message != "" {
+				output += ": " + truncateString(c.Message, 120)
+			}
+			output += "\n"
+		}
+	}
+
+	if len(d.Events) == 0 {
+		output += "Events:       <none>\n"
+		return output
+	}
+	output += "Events:\n"
+	for _, e := range d.Events {
+		output += fmt.Sprintf("  %-8s %-20s x%-4d %s  %s\n", e.Type, e.Reason, e.Count, e.LastSeen, truncateString(e.Message, 120))
+	}
+	return output
+}
+
+// formatStringMap formats a titled map, one sorted key per line
+func formatStringMap(title string, m map[string]string) string {
+	if len(m) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	output := title + ":\n"
+	for _, key := range keys {
+		output += fmt.Sprintf("  %s: %s\n", key, truncateString(m[key], 120))
+	}
+	return output
+}
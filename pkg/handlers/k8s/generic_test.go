@@ -0,0 +1,45 @@
+package k8s
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRedactSecret(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name": "cacerts",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"key.pem":"c2VjcmV0"}}`,
+				"owner": "platform",
+			},
+		},
+		"data":       map[string]interface{}{"key.pem": "c2VjcmV0", "empty": ""},
+		"stringData": map[string]interface{}{"token": "abc"},
+	}}
+
+	got := redactSecret(secret)
+	data := got.Object["data"].(map[string]interface{})
+	if data["key.pem"] != "<redacted: 6 bytes>" || data["empty"] != "<redacted: 0 bytes>" {
+		t.Errorf("data = %v, want sizes only", data)
+	}
+	if stringData := got.Object["stringData"].(map[string]interface{}); stringData["token"] != "<redacted: 3 bytes>" {
+		t.Errorf("stringData = %v, want sizes only", stringData)
+	}
+	annotations := got.GetAnnotations()
+	if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok || annotations["owner"] != "platform" {
+		t.Errorf("annotations = %v, want only the last-applied configuration dropped", annotations)
+	}
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data":       map[string]interface{}{"mesh": "trustDomain: cluster.local"},
+	}}
+	if data := redactSecret(configMap).Object["data"].(map[string]interface{}); data["mesh"] != "trustDomain: cluster.local" {
+		t.Errorf("ConfigMap data = %v, want unchanged", data)
+	}
+}
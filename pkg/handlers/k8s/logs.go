@@ -0,0 +1,483 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/logs"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const (
+	// defaultFollowWindow and maxFollowWindow bound how long follow mode streams
+	defaultFollowWindow = 30 * time.Second
+	maxFollowWindow     = 10 * time.Minute
+	// followFlushInterval and followChunkLines decide when streamed lines are sent
+	followFlushInterval = time.Second
+	followChunkLines    = 20
+	// followResultLines is how many of the last streamed lines the final result repeats
+	followResultLines = 100
+	// followBufferBytes bounds the lines the final result keeps when there is
+	// no progress token and the chunks may never have reached the client
+	followBufferBytes = 256 * 1024
+	// defaultProcessedLogLines is the tail read when lines are filtered or
+	// summarised on the server and no lines limit was given
+	defaultProcessedLogLines = 5000
+	// defaultSummaryTop is how many signatures a summary lists by default
+	defaultSummaryTop = 10
+)
+
+// followPodLogs streams a pod's logs to the client in chunks until the
+// follow window ends, max_lines lines were read or a line matches until.
+// Chunks are sent as progress notifications when the client asked for
+// progress, and as log notifications otherwise. The SDK only sends log
+// notifications once the client has called logging/setLevel, so without a
+// progress token the final result carries every streamed line, up to
+// max_bytes or followBufferBytes. Lines the filter rejects are skipped and
+// do not count towards max_lines.
+func followPodLogs(ctx context.Context, cc *mcp.ServerSession, k8sClient *kubernetes.Clientset, params *mcp.CallToolParamsFor[types.GetPodLogsParams], filter *logs.Filter) (*mcp.CallToolResultFor[types.GetPodLogsResult], error) {
+	args := params.Arguments
+
+	var until *regexp.Regexp
+	if args.Until != "" {
+		var err error
+		if until, err = regexp.Compile(args.Until); err != nil {
+			return &mcp.CallToolResultFor[types.GetPodLogsResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error: invalid until pattern '%s': %v", args.Until, err),
+				}},
+			}, nil
+		}
+	}
+
+	window := defaultFollowWindow
+	if args.FollowSeconds > 0 {
+		window = time.Duration(args.FollowSeconds) * time.Second
+	}
+	if window > maxFollowWindow {
+		window = maxFollowWindow
+	}
+	ctx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	logOptions := &corev1.PodLogOptions{
+		Container: args.Container,
+		Follow:    true,
+		Previous:  args.Previous,
+	}
+	// Only new lines are followed unless history was asked for
+	tail := args.Lines
+	logOptions.TailLines = &tail
+	if args.SinceSeconds > 0 {
+		logOptions.SinceSeconds = &args.SinceSeconds
+		if args.Lines == 0 {
+			logOptions.TailLines = nil
+		}
+	}
+
+	stream, err := k8sClient.CoreV1().Pods(args.Namespace).GetLogs(args.PodName, logOptions).Stream(ctx)
+	if err != nil {
+		return &mcp.CallToolResultFor[types.GetPodLogsResult]{
+			Content: []mcp.Content{&mcp.TextContent{
+				Text: fmt.Sprintf("Error following logs for pod '%s' in namespace '%s': %v", args.PodName, args.Namespace, err),
+			}},
+		}, nil
+	}
+	defer stream.Close()
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				readErr <- nil
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	logger := fmt.Sprintf("pod-logs/%s/%s", args.Namespace, args.PodName)
+	if args.Container != "" {
+		logger += "/" + args.Container
+	}
+	sink := newLogChunkSink(cc, params, logger, args.MaxLines)
+	start := time.Now()
+	result := types.GetPodLogsResult{Status: "success"}
+	// kept is what the final result repeats: the last lines when they were
+	// streamed as progress, everything within the byte budget otherwise
+	var kept []string
+	keptBytes := 0
+	budget := followBufferBytes
+	if args.MaxBytes > 0 {
+		budget = args.MaxBytes
+	}
+	ticker := time.NewTicker(followFlushInterval)
+	defer ticker.Stop()
+
+	for result.StopReason == "" {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if err := <-readErr; err != nil && ctx.Err() == nil {
+					result.Error = err.Error()
+				}
+				result.StopReason = "stream_ended"
+				if ctx.Err() != nil {
+					result.StopReason = contextStopReason(ctx)
+				}
+				break
+			}
+			if !filter.Match(logs.Parse(line)) {
+				break
+			}
+			result.LinesStreamed++
+			kept = append(kept, line)
+			keptBytes += len(line) + 1
+			if sink.streamsProgress() {
+				if len(kept) > followResultLines {
+					kept = kept[1:]
+				}
+			} else {
+				for keptBytes > budget && len(kept) > 0 {
+					keptBytes -= len(kept[0]) + 1
+					kept = kept[1:]
+					result.Dropped++
+				}
+			}
+			sink.add(ctx, line)
+
+			if until != nil && until.MatchString(line) {
+				result.StopReason = "until"
+				result.MatchedLine = line
+			} else if args.MaxLines > 0 && int64(result.LinesStreamed) >= args.MaxLines {
+				result.StopReason = "max_lines"
+			}
+		case <-ticker.C:
+			sink.flush(ctx)
+		case <-ctx.Done():
+			result.StopReason = contextStopReason(ctx)
+		}
+	}
+	// The request context may be over; the last chunk still goes out
+	sink.flush(context.WithoutCancel(ctx))
+
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	result.Logs = strings.Join(kept, "\n")
+
+	return &mcp.CallToolResultFor[types.GetPodLogsResult]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatFollowSummary(args, result, window)},
+			&mcp.TextContent{Text: toJSONString(result)},
+		},
+	}, nil
+}
+
+// contextStopReason tells the end of the follow window from a cancelled request
+func contextStopReason(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return "cancelled"
+}
+
+// logChunkSink batches followed lines and sends them to the client
+type logChunkSink struct {
+	session  *mcp.ServerSession
+	token    any
+	logger   string
+	pending  []string
+	progress float64
+	total    float64
+}
+
+// newLogChunkSink creates a sink for a request; logger names the log
+// notifications and total is the expected number of lines, or 0
+func newLogChunkSink(cc *mcp.ServerSession, params interface{ GetProgressToken() any }, logger string, total int64) *logChunkSink {
+	return &logChunkSink{
+		session: cc,
+		token:   params.GetProgressToken(),
+		logger:  logger,
+		total:   float64(total),
+	}
+}
+
+// streamsProgress reports whether chunks go out as progress notifications,
+// which unlike log notifications do not depend on the client's log level
+func (s *logChunkSink) streamsProgress() bool {
+	return s.session != nil && s.token != nil
+}
+
+func (s *logChunkSink) add(ctx context.Context, line string) {
+	s.pending = append(s.pending, line)
+	if len(s.pending) >= followChunkLines {
+		s.flush(ctx)
+	}
+}
+
+// flush sends the pending lines. Notifications are best effort: a client
+// that cannot keep up still gets the final summary.
+func (s *logChunkSink) flush(ctx context.Context) {
+	if len(s.pending) == 0 || s.session == nil {
+		s.pending = nil
+		return
+	}
+	chunk := strings.Join(s.pending, "\n")
+	s.progress += float64(len(s.pending))
+	s.pending = nil
+
+	if s.token != nil {
+		_ = s.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: s.token,
+			Progress:      s.progress,
+			Total:         s.total,
+			Message:       chunk,
+		})
+		return
+	}
+	_ = s.session.Log(ctx, &mcp.LoggingMessageParams{
+		Level:  "info",
+		Logger: s.logger,
+		Data:   chunk,
+	})
+}
+
+// formatFollowSummary formats the final result of follow mode
+func formatFollowSummary(args types.GetPodLogsParams, result types.GetPodLogsResult, window time.Duration) string {
+	output := fmt.Sprintf("=== Followed logs for pod '%s' in namespace '%s' ===", args.PodName, args.Namespace)
+	if args.Container != "" {
+		output += fmt.Sprintf(" (container: %s)", args.Container)
+	}
+	output += "\n"
+
+	switch result.StopReason {
+	case "until":
+		output += fmt.Sprintf("Stopped: a line matched '%s'\n", args.Until)
+		output += fmt.Sprintf("Matched line: %s\n", result.MatchedLine)
+	case "max_lines":
+		output += fmt.Sprintf("Stopped: read %d lines\n", args.MaxLines)
+	case "timeout":
+		output += fmt.Sprintf("Stopped: follow window of %s ended", window)
+		if args.Until != "" {
+			output += fmt.Sprintf(" without a line matching '%s'", args.Until)
+		}
+		output += "\n"
+	case "stream_ended":
+		output += "Stopped: the log stream ended (container exited)\n"
+	case "cancelled":
+		output += "Stopped: request cancelled\n"
+	}
+	if result.Error != "" {
+		output += fmt.Sprintf("Stream error: %s\n", result.Error)
+	}
+	output += fmt.Sprintf("Lines streamed: %d in %s\n", result.LinesStreamed, result.Duration)
+	if result.Dropped > 0 {
+		output += fmt.Sprintf("⚠️ %d older lines left out of this result to stay under its size limit\n", result.Dropped)
+	}
+
+	if result.Logs != "" {
+		shown := strings.Count(result.Logs, "\n") + 1
+		if shown == result.LinesStreamed {
+			output += fmt.Sprintf("\nStreamed lines:\n\n%s", result.Logs)
+		} else {
+			output += fmt.Sprintf("\nLast %d lines:\n\n%s", shown, result.Logs)
+		}
+	}
+	return output
+}
+
+// needsLogProcessing reports whether get_pod_logs must parse the lines
+// rather than return them as read
+func needsLogProcessing(args types.GetPodLogsParams) bool {
+	return args.Include != "" || args.Exclude != "" || args.MinLevel != "" ||
+		args.Dedup || args.MaxBytes > 0 || args.Output == "summary"
+}
+
+// processPodLogs filters the lines read from a pod and either summarises
+// the warnings and errors among them or returns them, deduplicated and
+// trimmed to the byte budget when asked
+func processPodLogs(args types.GetPodLogsParams, filter *logs.Filter, raw []string) *mcp.CallToolResultFor[types.GetPodLogsResult] {
+	var matched []logs.Line
+	for _, r := range raw {
+		if line := logs.Parse(r); filter.Match(line) {
+			matched = append(matched, line)
+		}
+	}
+	result := types.GetPodLogsResult{
+		Status:       "success",
+		LinesRead:    len(raw),
+		LinesMatched: len(matched),
+	}
+
+	if args.Output == "summary" {
+		top := args.Top
+		if top <= 0 {
+			top = defaultSummaryTop
+		}
+		for _, s := range logs.Summarize(matched, top) {
+			sig := types.LogSignature{
+				Signature: s.Signature,
+				Level:     s.Level,
+				Count:     s.Count,
+				Example:   s.Example,
+			}
+			if !s.FirstSeen.IsZero() {
+				sig.FirstSeen = s.FirstSeen.UTC().Format(time.RFC3339)
+				sig.LastSeen = s.LastSeen.UTC().Format(time.RFC3339)
+			}
+			result.Summary = append(result.Summary, sig)
+		}
+		return &mcp.CallToolResultFor[types.GetPodLogsResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatLogSummary(args, result, matched)},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}
+	}
+
+	var out []string
+	if args.Dedup {
+		for _, e := range logs.Dedup(matched) {
+			text := e.Line.Raw
+			if e.Count > 1 {
+				text += fmt.Sprintf(" (×%d)", e.Count)
+			}
+			out = append(out, text)
+		}
+	} else {
+		for _, l := range matched {
+			out = append(out, l.Raw)
+		}
+	}
+	out, result.Dropped = logs.TailBytes(out, args.MaxBytes)
+	result.Logs = strings.Join(out, "\n")
+
+	return &mcp.CallToolResultFor[types.GetPodLogsResult]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatProcessedLogs(args, result, len(out))},
+			&mcp.TextContent{Text: toJSONString(result)},
+		},
+	}
+}
+
+// logTarget describes the pod and container whose logs were read
+func logTarget(args types.GetPodLogsParams) string {
+	target := fmt.Sprintf("pod '%s' in namespace '%s'", args.PodName, args.Namespace)
+	if args.Container != "" {
+		target += fmt.Sprintf(" (container: %s)", args.Container)
+	}
+	return target
+}
+
+// describeLogFilter lists the filters that were applied, for output headers
+func describeLogFilter(args types.GetPodLogsParams) string {
+	var parts []string
+	if args.Include != "" {
+		parts = append(parts, fmt.Sprintf("include '%s'", args.Include))
+	}
+	if args.Exclude != "" {
+		parts = append(parts, fmt.Sprintf("exclude '%s'", args.Exclude))
+	}
+	if args.MinLevel != "" {
+		parts = append(parts, fmt.Sprintf("level >= %s", args.MinLevel))
+	}
+	if args.Dedup {
+		parts = append(parts, "deduplicated")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatProcessedLogs formats filtered log lines
+func formatProcessedLogs(args types.GetPodLogsParams, result types.GetPodLogsResult, shown int) string {
+	output := fmt.Sprintf("=== Logs for %s ===\n", logTarget(args))
+	if filters := describeLogFilter(args); filters != "" {
+		output += fmt.Sprintf("Filters: %s\n", filters)
+	}
+	output += fmt.Sprintf("Lines read: %d, matched: %d\n", result.LinesRead, result.LinesMatched)
+	if result.Dropped > 0 {
+		output += fmt.Sprintf("⚠️ %d older lines dropped to stay under %d bytes\n", result.Dropped, args.MaxBytes)
+	}
+	if shown == 0 {
+		return output + "\nNo lines matched"
+	}
+	return output + fmt.Sprintf("Showing %d lines:\n\n%s", shown, result.Logs)
+}
+
+// formatLogSummary formats the level counts and top signatures of a log summary
+func formatLogSummary(args types.GetPodLogsParams, result types.GetPodLogsResult, matched []logs.Line) string {
+	output := fmt.Sprintf("=== Log summary for %s ===\n", logTarget(args))
+	if filters := describeLogFilter(args); filters != "" {
+		output += fmt.Sprintf("Filters: %s\n", filters)
+	}
+	output += fmt.Sprintf("Lines read: %d, matched: %d\n", result.LinesRead, result.LinesMatched)
+
+	counts := map[logs.Level]int{}
+	var first, last time.Time
+	for _, l := range matched {
+		counts[l.Level]++
+		if !l.Time.IsZero() {
+			if first.IsZero() {
+				first = l.Time
+			}
+			last = l.Time
+		}
+	}
+	if !first.IsZero() {
+		output += fmt.Sprintf("Time range: %s to %s\n", first.UTC().Format(time.RFC3339), last.UTC().Format(time.RFC3339))
+	}
+	output += fmt.Sprintf("Levels: error %d, warn %d, info %d, debug %d, unknown %d\n",
+		counts[logs.LevelError], counts[logs.LevelWarn], counts[logs.LevelInfo], counts[logs.LevelDebug], counts[logs.LevelUnknown])
+
+	if len(result.Summary) == 0 {
+		return output + "\n✅ No warnings or errors found"
+	}
+	output += fmt.Sprintf("\nTop %d warning and error signatures:\n", len(result.Summary))
+	for i, s := range result.Summary {
+		icon := "⚠️"
+		if s.Level == logs.LevelError.String() {
+			icon = "❌"
+		}
+		output += fmt.Sprintf("\n%d. %s [%s] ×%d: %s\n", i+1, icon, s.Level, s.Count, s.Signature)
+		if s.FirstSeen != "" {
+			output += fmt.Sprintf("   First seen: %s, last seen: %s\n", s.FirstSeen, s.LastSeen)
+		}
+		if s.Example != s.Signature {
+			output += fmt.Sprintf("   Example: %s\n", s.Example)
+		}
+	}
+	return output
+}
+
+// readLogLines reads a container's logs, giving up after 30 seconds
+func readLogLines(ctx context.Context, k8sClient *kubernetes.Clientset, namespace, pod string, logOptions *corev1.PodLogOptions) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	stream, err := k8sClient.CoreV1().Pods(namespace).GetLogs(pod, logOptions).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
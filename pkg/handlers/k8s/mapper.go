@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// ResourceMapper resolves the kinds, plurals and short names users type, as
+// kubectl does, to the API resource serving them. Discovery results are
+// cached and refreshed when a name is not found, so CRDs installed after the
+// server started are picked up.
+type ResourceMapper struct {
+	mapper *restmapper.DeferredDiscoveryRESTMapper
+	expand meta.RESTMapper
+}
+
+// NewResourceMapper creates a mapper; discovery runs on the first lookup
+func NewResourceMapper(discoveryClient discovery.DiscoveryInterface) *ResourceMapper {
+	cache := memory.NewMemCacheClient(discoveryClient)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(cache)
+	return &ResourceMapper{
+		mapper: mapper,
+		expand: restmapper.NewShortcutExpander(mapper, cache, nil),
+	}
+}
+
+// Mapping resolves a kind ("Deployment"), resource ("deployments"), short
+// name ("deploy") or any of them qualified by version and group
+// ("hpa.v2.autoscaling", "jobs.batch", "istios.sailoperator.io")
+func (m *ResourceMapper) Mapping(name string) (*meta.RESTMapping, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("kind is required")
+	}
+
+	mapping, err := m.lookup(name)
+	if meta.IsNoMatchError(err) {
+		// The kind may have been installed since discovery ran
+		m.mapper.Reset()
+		mapping, err = m.lookup(name)
+	}
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("the server doesn't have a resource type '%s'", name)
+	}
+	return mapping, err
+}
+
+func (m *ResourceMapper) lookup(name string) (*meta.RESTMapping, error) {
+	gvk, err := m.kindFor(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// kindFor tries name as a resource first, fully qualified when it has enough
+// dots, then as a kind, like kubectl's resource builder
+func (m *ResourceMapper) kindFor(name string) (schema.GroupVersionKind, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(strings.ToLower(name))
+	if fullySpecified != nil {
+		if gvk, err := m.expand.KindFor(*fullySpecified); err == nil {
+			return gvk, nil
+		}
+	}
+	gvk, err := m.expand.KindFor(groupResource.WithVersion(""))
+	if err == nil {
+		return gvk, nil
+	}
+
+	fullyKind, groupKind := schema.ParseKindArg(name)
+	if fullyKind != nil {
+		if mapping, kindErr := m.mapper.RESTMapping(fullyKind.GroupKind(), fullyKind.Version); kindErr == nil {
+			return mapping.GroupVersionKind, nil
+		}
+	}
+	if mapping, kindErr := m.mapper.RESTMapping(groupKind); kindErr == nil {
+		return mapping.GroupVersionKind, nil
+	}
+	return schema.GroupVersionKind{}, err
+}
@@ -10,26 +10,26 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/frherrer/mcp-sail-operator/pkg/progress"
 	"github.com/frherrer/mcp-sail-operator/pkg/types"
 )
 
-// CheckMeshWorkloads checks the status of workloads in the Istio mesh
-func CheckMeshWorkloads(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckMeshWorkloadsParams]) (*mcp.CallToolResultFor[types.CheckMeshWorkloadsResult], error) {
+// CheckMeshWorkloads checks the status of workloads in the Istio mesh, a page
+// of at most limit pods and budget bytes per call
+func CheckMeshWorkloads(k8sClient *kubernetes.Clientset, budget int) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckMeshWorkloadsParams]) (*mcp.CallToolResultFor[types.CheckMeshWorkloadsResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckMeshWorkloadsParams]) (*mcp.CallToolResultFor[types.CheckMeshWorkloadsResult], error) {
 		listOptions := metav1.ListOptions{}
 		if params.Arguments.LabelSelector != "" {
 			listOptions.LabelSelector = params.Arguments.LabelSelector
 		}
 
-		var podList *corev1.PodList
-		var err error
-
-		if params.Arguments.Namespace != "" {
-			podList, err = k8sClient.CoreV1().Pods(params.Arguments.Namespace).List(ctx, listOptions)
-		} else {
-			podList, err = k8sClient.CoreV1().Pods("").List(ctx, listOptions)
+		pager, err := newPager(params.Arguments.Cursor, params.Arguments.Limit, budget)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.CheckMeshWorkloadsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
 		}
-
+		items, err := fetchPage(ctx, pager, listOptions, progress.New(cc, params, 0), "pods", podLister(k8sClient, params.Arguments.Namespace))
 		if err != nil {
 			return &mcp.CallToolResultFor[types.CheckMeshWorkloadsResult]{
 				Content: []mcp.Content{&mcp.TextContent{
@@ -42,19 +42,26 @@ func CheckMeshWorkloads(k8sClient *kubernetes.Clientset) func(ctx context.Contex
 		injectedCount := 0
 		totalCount := 0
 
-		for _, pod := range podList.Items {
+		cut := len(items)
+		for i, pod := range items {
 			// Skip system pods
 			if isSystemPod(&pod) {
 				continue
 			}
 
-			totalCount++
 			workload := analyzePodMeshStatus(&pod)
+			// The table row and the issues section are about as long as the JSON
+			if !pager.fits(2 * len(toJSONString(workload))) {
+				cut = i
+				break
+			}
+			totalCount++
 			if workload.SidecarInjected {
 				injectedCount++
 			}
 			workloads = append(workloads, workload)
 		}
+		next, following, more := pager.done(cut)
 
 		// Format output
 		var output string
@@ -65,8 +72,16 @@ func CheckMeshWorkloads(k8sClient *kubernetes.Clientset) func(ctx context.Contex
 			}
 		} else {
 			output = fmt.Sprintf("=== Mesh Workloads Analysis ===\n\n")
-			output += fmt.Sprintf("Found %d workloads (%d with sidecars, %d without)\n\n", 
+			output += fmt.Sprintf("%d workloads on this page (%d with sidecars, %d without)",
 				totalCount, injectedCount, totalCount-injectedCount)
+			if more {
+				if following > 0 {
+					output += fmt.Sprintf("; %d more pods follow on later pages", following)
+				} else {
+					output += "; more pods follow on later pages"
+				}
+			}
+			output += "\n\n"
 			
 			output += fmt.Sprintf("%-30s %-15s %-12s %-8s %-10s %s\n", 
 				"NAME", "NAMESPACE", "MESH STATUS", "SIDECAR", "READY", "ISSUES")
@@ -121,11 +136,16 @@ func CheckMeshWorkloads(k8sClient *kubernetes.Clientset) func(ctx context.Contex
 				output += "\n✅ No issues found with mesh workloads"
 			}
 		}
+		if more {
+			output += "\n" + formatMore("pods", following, next)
+		}
 
+		result := types.CheckMeshWorkloadsResult{Status: "success", Workloads: workloads, Count: len(workloads), NextCursor: next, Remaining: following}
 		return &mcp.CallToolResultFor[types.CheckMeshWorkloadsResult]{
-			Content: []mcp.Content{&mcp.TextContent{
-				Text: output,
-			}},
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
 		}, nil
 	}
 }
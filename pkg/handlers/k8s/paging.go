@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/progress"
+)
+
+const (
+	// defaultListLimit is how many items list tools return per call when no limit is given
+	defaultListLimit = 500
+	// maxListLimit caps the limit a caller may ask for
+	maxListLimit = 5000
+	// listChunkSize is how many items are fetched per API request
+	listChunkSize = 500
+	// DefaultResponseBudget is how many bytes of items list tools return per
+	// call by default, counting both the text table and the JSON payload
+	DefaultResponseBudget = 64 * 1024
+)
+
+// pageCursor is the position a list continues from: a Kubernetes continue
+// token and how many items of the page it starts were already returned.
+// The skip lets a page the response budget cut short continue where it was
+// cut, since continue tokens only point between API pages. Listings that
+// filter and sort the whole selection continue at an offset into it instead.
+type pageCursor struct {
+	Continue string `json:"c,omitempty"`
+	Skip     int    `json:"s,omitempty"`
+	Offset   int    `json:"o,omitempty"`
+}
+
+// encode returns the opaque cursor handed to the client
+func (c pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor returned by an earlier call
+func decodeCursor(cursor string) (pageCursor, error) {
+	var c pageCursor
+	if cursor == "" {
+		return c, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.Skip < 0 || c.Offset < 0 {
+		return c, fmt.Errorf("invalid cursor; pass the next_cursor of the previous call unchanged")
+	}
+	return c, nil
+}
+
+// pager pages a list tool through Kubernetes continue tokens behind an MCP
+// style opaque cursor, and cuts a page short when the items no longer fit
+// the response budget
+type pager struct {
+	start  pageCursor
+	limit  int64
+	budget int
+
+	used      int
+	fetched   int    // items of this page, after the skipped ones
+	next      string // continue token after this page
+	remaining *int64 // items after this page, when the server counts them
+}
+
+// newPager creates a pager for one call; limit and budget fall back to the
+// defaults when not positive
+func newPager(cursor string, limit int64, budget int) (*pager, error) {
+	start, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	if budget <= 0 {
+		budget = DefaultResponseBudget
+	}
+	return &pager{start: start, limit: limit, budget: budget}, nil
+}
+
+// fetchPage fetches the page a pager starts at. Large pages are requested in
+// chunks of listChunkSize, reporting progress after each, so cancellation
+// is honoured between requests.
+func fetchPage[T any](ctx context.Context, p *pager, listOptions metav1.ListOptions, reporter *progress.Reporter, what string,
+	list func(ctx context.Context, listOptions metav1.ListOptions) ([]T, metav1.ListMeta, error)) ([]T, error) {
+	if p.start.Offset > 0 {
+		// Offsets index a filtered and sorted selection; reading them as
+		// continue tokens would restart at the first page
+		return nil, fmt.Errorf("the cursor belongs to a listing with filters or sort_by; pass the same filters or start again without the cursor")
+	}
+	want := p.limit + int64(p.start.Skip)
+	listOptions.Continue = p.start.Continue
+
+	var items []T
+	for {
+		listOptions.Limit = min(want-int64(len(items)), listChunkSize)
+		page, meta, err := list(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		p.next = meta.Continue
+		p.remaining = meta.RemainingItemCount
+
+		total := int(want)
+		if meta.Continue == "" {
+			total = len(items)
+		}
+		reporter.Advance(ctx, len(page), total, fmt.Sprintf("Listed %d %s", len(items), what))
+
+		if meta.Continue == "" || int64(len(items)) >= want {
+			break
+		}
+		listOptions.Continue = meta.Continue
+	}
+
+	if len(items) <= p.start.Skip {
+		items = nil
+	} else {
+		items = items[p.start.Skip:]
+	}
+	p.fetched = len(items)
+	return items, nil
+}
+
+// fits charges the size of one more item against the budget and reports
+// whether it may be returned. The first item always fits, so every call
+// makes progress.
+func (p *pager) fits(size int) bool {
+	if p.used > 0 && p.used+size > p.budget {
+		return false
+	}
+	p.used += size
+	return true
+}
+
+// done returns the cursor continuing after the first cut items of the
+// page and how many items are known to follow; more reports whether any do
+func (p *pager) done(cut int) (next string, following int64, more bool) {
+	if cut < p.fetched {
+		following = int64(p.fetched - cut)
+		if p.remaining != nil {
+			following += *p.remaining
+		}
+		return pageCursor{Continue: p.start.Continue, Skip: p.start.Skip + cut}.encode(), following, true
+	}
+	if p.next == "" {
+		return "", 0, false
+	}
+	if p.remaining != nil {
+		following = *p.remaining
+	}
+	return pageCursor{Continue: p.next}.encode(), following, true
+}
+
+// formatMore formats the marker ending a truncated listing
+func formatMore(what string, following int64, next string) string {
+	count := "More"
+	if following > 0 {
+		count = fmt.Sprintf("%d more", following)
+	}
+	return fmt.Sprintf("\n... %s %s not shown; call again with cursor \"%s\" to continue\n", count, what, next)
+}
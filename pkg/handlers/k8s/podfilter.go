@@ -0,0 +1,159 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// PodFilter holds the list_pods filters the API server cannot apply
+type PodFilter struct {
+	NotReady      bool
+	RestartsAbove *int32
+	OlderThan     time.Duration
+	YoungerThan   time.Duration
+	HasSidecar    *bool
+}
+
+// podPhases maps lower-case phase names to the phases pods report
+var podPhases = map[string]corev1.PodPhase{
+	"pending":   corev1.PodPending,
+	"running":   corev1.PodRunning,
+	"succeeded": corev1.PodSucceeded,
+	"failed":    corev1.PodFailed,
+	"unknown":   corev1.PodUnknown,
+}
+
+// PodListOptions builds the list options of a pod listing: the label
+// selector, and the field selector combined with the node and phase filters
+func PodListOptions(args types.ListPodsParams) (metav1.ListOptions, error) {
+	listOptions := metav1.ListOptions{LabelSelector: args.LabelSelector}
+	fs := args.FieldSelector
+	if args.NodeName != "" {
+		appendFieldSelector(&fs, "spec.nodeName", args.NodeName)
+	}
+	if args.Phase != "" {
+		phase, ok := podPhases[strings.ToLower(args.Phase)]
+		if !ok {
+			return listOptions, fmt.Errorf("unknown phase '%s', use Pending, Running, Succeeded, Failed or Unknown", args.Phase)
+		}
+		appendFieldSelector(&fs, "status.phase", string(phase))
+	}
+	listOptions.FieldSelector = fs
+	return listOptions, nil
+}
+
+// NewPodFilter creates the filter of a pod listing and checks its sort order
+func NewPodFilter(args types.ListPodsParams) (PodFilter, error) {
+	switch args.SortBy {
+	case "", "age", "restarts", "name":
+	default:
+		return PodFilter{}, fmt.Errorf("unknown sort_by '%s', use age, restarts or name", args.SortBy)
+	}
+	if args.OlderThanSeconds < 0 || args.YoungerThanSeconds < 0 {
+		return PodFilter{}, fmt.Errorf("older_than_seconds and younger_than_seconds must not be negative")
+	}
+	return PodFilter{
+		NotReady:      args.NotReady,
+		RestartsAbove: args.RestartsAbove,
+		OlderThan:     time.Duration(args.OlderThanSeconds) * time.Second,
+		YoungerThan:   time.Duration(args.YoungerThanSeconds) * time.Second,
+		HasSidecar:    args.HasSidecar,
+	}, nil
+}
+
+// Active reports whether the filter rejects any pod
+func (f PodFilter) Active() bool {
+	return f.NotReady || f.RestartsAbove != nil || f.OlderThan > 0 || f.YoungerThan > 0 || f.HasSidecar != nil
+}
+
+// Match reports whether a pod passes every filter
+func (f PodFilter) Match(pod *corev1.Pod, now time.Time) bool {
+	if f.NotReady && (pod.Status.Phase == corev1.PodSucceeded || isPodReady(pod)) {
+		return false
+	}
+	if f.RestartsAbove != nil && podRestarts(pod) <= *f.RestartsAbove {
+		return false
+	}
+	age := now.Sub(pod.CreationTimestamp.Time)
+	if f.OlderThan > 0 && age <= f.OlderThan {
+		return false
+	}
+	if f.YoungerThan > 0 && age >= f.YoungerThan {
+		return false
+	}
+	if f.HasSidecar != nil && hasIstioProxy(pod) != *f.HasSidecar {
+		return false
+	}
+	return true
+}
+
+// SortPods orders pods by age (newest first), restarts (most first) or
+// name; ties fall back to namespace and name, and an empty order keeps the
+// listing's order
+func SortPods(pods []corev1.Pod, by string) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		a, b := &pods[i], &pods[j]
+		switch by {
+		case "age":
+			if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+				return b.CreationTimestamp.Before(&a.CreationTimestamp)
+			}
+		case "restarts":
+			if ra, rb := podRestarts(a), podRestarts(b); ra != rb {
+				return ra > rb
+			}
+		case "name":
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case "":
+			return false
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
+
+// isPodReady reports whether the pod's Ready condition is True
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podRestarts sums the restarts of a pod's containers
+func podRestarts(pod *corev1.Pod) int32 {
+	restarts := int32(0)
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarts += cs.RestartCount
+	}
+	return restarts
+}
+
+// hasIstioProxy reports whether a pod runs the istio-proxy sidecar, as a
+// regular container or as a native sidecar init container
+func hasIstioProxy(pod *corev1.Pod) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == "istio-proxy" {
+			return true
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == "istio-proxy" && c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			return true
+		}
+	}
+	return false
+}
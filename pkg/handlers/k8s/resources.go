@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,29 +18,49 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/frherrer/mcp-sail-operator/pkg/logs"
+	"github.com/frherrer/mcp-sail-operator/pkg/progress"
 	"github.com/frherrer/mcp-sail-operator/pkg/types"
 )
 
-// ListPods lists pods in the cluster with optional namespace and label filtering
-func ListPods(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListPodsParams]) (*mcp.CallToolResultFor[types.ListPodsResult], error) {
+// ListPods lists pods in the cluster with optional namespace, label and field
+// selectors, computed status filters and sorting, a page of at most limit
+// pods and budget bytes per call
+func ListPods(k8sClient *kubernetes.Clientset, budget int) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListPodsParams]) (*mcp.CallToolResultFor[types.ListPodsResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListPodsParams]) (*mcp.CallToolResultFor[types.ListPodsResult], error) {
-		// Basic timeout to avoid long MCP hangs
-		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
-		listOptions := metav1.ListOptions{}
-		if params.Arguments.LabelSelector != "" {
-			listOptions.LabelSelector = params.Arguments.LabelSelector
+		listOptions, err := PodListOptions(params.Arguments)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.ListPodsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
+		}
+		filter, err := NewPodFilter(params.Arguments)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.ListPodsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
+		}
+		pager, err := newPager(params.Arguments.Cursor, params.Arguments.Limit, budget)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.ListPodsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
 		}
 
-		var podList *corev1.PodList
-		var err error
-
-		if params.Arguments.Namespace != "" {
-			podList, err = k8sClient.CoreV1().Pods(params.Arguments.Namespace).List(ctx, listOptions)
+		// Filters and sorting need every pod of the selection, so they scan
+		// it whole; plain listings fetch one API page per call
+		reporter := progress.New(cc, params, 0)
+		var page podPage
+		if filter.Active() || params.Arguments.SortBy != "" {
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			page, err = scanPods(ctx, k8sClient, params.Arguments, listOptions, filter, pager, reporter)
 		} else {
-			podList, err = k8sClient.CoreV1().Pods("").List(ctx, listOptions)
+			// Basic timeout to avoid long MCP hangs
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			page, err = pagePods(ctx, k8sClient, params.Arguments.Namespace, listOptions, pager, reporter)
 		}
-
 		if err != nil {
 			return &mcp.CallToolResultFor[types.ListPodsResult]{
 				Content: []mcp.Content{&mcp.TextContent{
@@ -48,53 +69,9 @@ func ListPods(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp
 			}, nil
 		}
 
-		var pods []types.PodInfo
-		for _, pod := range podList.Items {
-			podInfo := types.PodInfo{
-				Name:      pod.Name,
-				Namespace: pod.Namespace,
-				Phase:     string(pod.Status.Phase),
-				NodeName:  pod.Spec.NodeName,
-				PodIP:     pod.Status.PodIP,
-				Labels:    pod.Labels,
-				CreatedAt: pod.CreationTimestamp.String(),
-				Age:       formatAge(pod.CreationTimestamp.Time),
-			}
-
-			// Calculate ready containers
-			readyCount := 0
-			totalCount := len(pod.Status.ContainerStatuses)
-			restarts := int32(0)
-
-			for _, containerStatus := range pod.Status.ContainerStatuses {
-				if containerStatus.Ready {
-					readyCount++
-				}
-				restarts += containerStatus.RestartCount
-			}
-
-			podInfo.Ready = fmt.Sprintf("%d/%d", readyCount, totalCount)
-			podInfo.Restarts = restarts
-
-			// Determine overall status
-			if pod.Status.Phase == corev1.PodRunning && readyCount == totalCount {
-				podInfo.Status = "Running"
-			} else if pod.Status.Phase == corev1.PodPending {
-				podInfo.Status = "Pending"
-			} else if pod.Status.Phase == corev1.PodFailed {
-				podInfo.Status = "Failed"
-			} else if pod.Status.Phase == corev1.PodSucceeded {
-				podInfo.Status = "Completed"
-			} else {
-				podInfo.Status = string(pod.Status.Phase)
-			}
-
-			pods = append(pods, podInfo)
-		}
-
 		// Format output
 		var output string
-		if len(pods) == 0 {
+		if len(page.pods) == 0 {
 			output = "No pods found"
 			if params.Arguments.Namespace != "" {
 				output += fmt.Sprintf(" in namespace '%s'", params.Arguments.Namespace)
@@ -102,27 +79,27 @@ func ListPods(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp
 			if params.Arguments.LabelSelector != "" {
 				output += fmt.Sprintf(" with label selector '%s'", params.Arguments.LabelSelector)
 			}
+			if listOptions.FieldSelector != "" {
+				output += fmt.Sprintf(" with field selector '%s'", listOptions.FieldSelector)
+			}
+			if filter.Active() {
+				output += " matching the filters"
+			}
 		} else {
-			output = fmt.Sprintf("Found %d pods:\n\n", len(pods))
+			output = fmt.Sprintf("Found %d pods:\n\n", len(page.pods))
 			output += fmt.Sprintf("%-30s %-15s %-10s %-8s %-10s %-10s %s\n",
 				"NAME", "NAMESPACE", "STATUS", "READY", "RESTARTS", "AGE", "NODE")
 			output += strings.Repeat("-", 100) + "\n"
-
-			for _, pod := range pods {
-				output += fmt.Sprintf("%-30s %-15s %-10s %-8s %-10d %-10s %s\n",
-					truncateString(pod.Name, 29),
-					pod.Namespace,
-					pod.Status,
-					pod.Ready,
-					pod.Restarts,
-					pod.Age,
-					pod.NodeName,
-				)
+			for _, pod := range page.pods {
+				output += formatPodRow(pod)
 			}
 		}
+		if page.more {
+			output += formatMore("pods", page.following, page.next)
+		}
 
 		// Include both human text and JSON payload (as text JSON for clients to parse)
-		result := types.ListPodsResult{Status: "success", Pods: pods, Count: len(pods)}
+		result := types.ListPodsResult{Status: "success", Pods: page.pods, Count: len(page.pods), NextCursor: page.next, Remaining: page.following}
 		jsonPayload := toJSONString(result)
 		return &mcp.CallToolResultFor[types.ListPodsResult]{
 			Content: []mcp.Content{
@@ -133,8 +110,158 @@ func ListPods(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp
 	}
 }
 
-// ListServices lists services in the cluster with optional namespace and label filtering
-func ListServices(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListServicesParams]) (*mcp.CallToolResultFor[types.ListServicesResult], error) {
+// podPage is the pods one list_pods call returns
+type podPage struct {
+	pods      []types.PodInfo
+	next      string // cursor of the next page
+	following int64  // pods known to follow
+	more      bool
+}
+
+// pagePods returns the pods of one API page, cut short by the response budget
+func pagePods(ctx context.Context, k8sClient *kubernetes.Clientset, namespace string, listOptions metav1.ListOptions, pager *pager, reporter *progress.Reporter) (podPage, error) {
+	items, err := fetchPage(ctx, pager, listOptions, reporter, "pods", podLister(k8sClient, namespace))
+	if err != nil {
+		return podPage{}, err
+	}
+
+	var page podPage
+	cut := len(items)
+	for i := range items {
+		podInfo := podInfoFor(&items[i])
+		if !pager.fits(len(formatPodRow(podInfo)) + len(toJSONString(podInfo))) {
+			cut = i
+			break
+		}
+		page.pods = append(page.pods, podInfo)
+	}
+	page.next, page.following, page.more = pager.done(cut)
+	return page, nil
+}
+
+// scanPods lists every pod of the selection, filters and sorts them and
+// returns the page at the cursor's offset. Later pages list the selection
+// again, so pods that change in between may move across pages.
+func scanPods(ctx context.Context, k8sClient *kubernetes.Clientset, args types.ListPodsParams, listOptions metav1.ListOptions, filter PodFilter, pager *pager, reporter *progress.Reporter) (podPage, error) {
+	if pager.start.Continue != "" || pager.start.Skip > 0 {
+		return podPage{}, fmt.Errorf("the cursor belongs to a listing without filters or sort_by; start again without it")
+	}
+
+	list := podLister(k8sClient, args.Namespace)
+	var matched []corev1.Pod
+	listed := 0
+	now := time.Now()
+	listOptions.Limit = listChunkSize
+	for {
+		items, meta, err := list(ctx, listOptions)
+		if err != nil {
+			return podPage{}, err
+		}
+		listed += len(items)
+		for i := range items {
+			if filter.Match(&items[i], now) {
+				matched = append(matched, items[i])
+			}
+		}
+
+		total := listed
+		if meta.RemainingItemCount != nil {
+			total += int(*meta.RemainingItemCount)
+		}
+		reporter.Advance(ctx, len(items), total, fmt.Sprintf("Scanned %d pods, %d match", listed, len(matched)))
+
+		if meta.Continue == "" {
+			break
+		}
+		listOptions.Continue = meta.Continue
+	}
+	SortPods(matched, args.SortBy)
+
+	var page podPage
+	end := min(pager.start.Offset, len(matched))
+	for i := end; i < len(matched) && int64(i-pager.start.Offset) < pager.limit; i++ {
+		podInfo := podInfoFor(&matched[i])
+		if !pager.fits(len(formatPodRow(podInfo)) + len(toJSONString(podInfo))) {
+			break
+		}
+		page.pods = append(page.pods, podInfo)
+		end = i + 1
+	}
+	if end < len(matched) {
+		page.next = pageCursor{Offset: end}.encode()
+		page.following = int64(len(matched) - end)
+		page.more = true
+	}
+	return page, nil
+}
+
+// podInfoFor summarises a pod for list_pods
+func podInfoFor(pod *corev1.Pod) types.PodInfo {
+	podInfo := types.PodInfo{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Phase:     string(pod.Status.Phase),
+		NodeName:  pod.Spec.NodeName,
+		PodIP:     pod.Status.PodIP,
+		Labels:    pod.Labels,
+		CreatedAt: pod.CreationTimestamp.String(),
+		Age:       formatAge(pod.CreationTimestamp.Time),
+	}
+
+	// Calculate ready containers
+	readyCount := 0
+	totalCount := len(pod.Status.ContainerStatuses)
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Ready {
+			readyCount++
+		}
+	}
+
+	podInfo.Ready = fmt.Sprintf("%d/%d", readyCount, totalCount)
+	podInfo.Restarts = podRestarts(pod)
+
+	// Determine overall status
+	if pod.Status.Phase == corev1.PodRunning && readyCount == totalCount {
+		podInfo.Status = "Running"
+	} else if pod.Status.Phase == corev1.PodPending {
+		podInfo.Status = "Pending"
+	} else if pod.Status.Phase == corev1.PodFailed {
+		podInfo.Status = "Failed"
+	} else if pod.Status.Phase == corev1.PodSucceeded {
+		podInfo.Status = "Completed"
+	} else {
+		podInfo.Status = string(pod.Status.Phase)
+	}
+	return podInfo
+}
+
+// formatPodRow formats one line of the pod table
+func formatPodRow(pod types.PodInfo) string {
+	return fmt.Sprintf("%-30s %-15s %-10s %-8s %-10d %-10s %s\n",
+		truncateString(pod.Name, 29),
+		pod.Namespace,
+		pod.Status,
+		pod.Ready,
+		pod.Restarts,
+		pod.Age,
+		pod.NodeName,
+	)
+}
+
+// podLister lists the pods of a namespace, or of all namespaces when it is empty
+func podLister(k8sClient *kubernetes.Clientset, namespace string) func(ctx context.Context, listOptions metav1.ListOptions) ([]corev1.Pod, metav1.ListMeta, error) {
+	return func(ctx context.Context, listOptions metav1.ListOptions) ([]corev1.Pod, metav1.ListMeta, error) {
+		list, err := k8sClient.CoreV1().Pods(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, metav1.ListMeta{}, err
+		}
+		return list.Items, list.ListMeta, nil
+	}
+}
+
+// ListServices lists services in the cluster with optional namespace and label
+// filtering, a page of at most limit services and budget bytes per call
+func ListServices(k8sClient *kubernetes.Clientset, budget int) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListServicesParams]) (*mcp.CallToolResultFor[types.ListServicesResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListServicesParams]) (*mcp.CallToolResultFor[types.ListServicesResult], error) {
 		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
@@ -143,15 +270,20 @@ func ListServices(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc
 			listOptions.LabelSelector = params.Arguments.LabelSelector
 		}
 
-		var serviceList *corev1.ServiceList
-		var err error
-
-		if params.Arguments.Namespace != "" {
-			serviceList, err = k8sClient.CoreV1().Services(params.Arguments.Namespace).List(ctx, listOptions)
-		} else {
-			serviceList, err = k8sClient.CoreV1().Services("").List(ctx, listOptions)
+		pager, err := newPager(params.Arguments.Cursor, params.Arguments.Limit, budget)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.ListServicesResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
 		}
-
+		items, err := fetchPage(ctx, pager, listOptions, nil, "services",
+			func(ctx context.Context, listOptions metav1.ListOptions) ([]corev1.Service, metav1.ListMeta, error) {
+				list, err := k8sClient.CoreV1().Services(params.Arguments.Namespace).List(ctx, listOptions)
+				if err != nil {
+					return nil, metav1.ListMeta{}, err
+				}
+				return list.Items, list.ListMeta, nil
+			})
 		if err != nil {
 			return &mcp.CallToolResultFor[types.ListServicesResult]{
 				Content: []mcp.Content{&mcp.TextContent{
@@ -161,7 +293,9 @@ func ListServices(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc
 		}
 
 		var services []types.ServiceInfo
-		for _, svc := range serviceList.Items {
+		var rows string
+		cut := len(items)
+		for i, svc := range items {
 			serviceInfo := types.ServiceInfo{
 				Name:      svc.Name,
 				Namespace: svc.Namespace,
@@ -200,8 +334,15 @@ func ListServices(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc
 				serviceInfo.Ports = append(serviceInfo.Ports, servicePort)
 			}
 
+			row := formatServiceRow(serviceInfo)
+			if !pager.fits(len(row) + len(toJSONString(serviceInfo))) {
+				cut = i
+				break
+			}
 			services = append(services, serviceInfo)
+			rows += row
 		}
+		next, following, more := pager.done(cut)
 
 		// Format output
 		var output string
@@ -218,33 +359,13 @@ func ListServices(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc
 			output += fmt.Sprintf("%-30s %-15s %-12s %-15s %-20s %s\n",
 				"NAME", "NAMESPACE", "TYPE", "CLUSTER-IP", "EXTERNAL-IP", "PORTS")
 			output += strings.Repeat("-", 110) + "\n"
-
-			for _, svc := range services {
-				externalIP := "<none>"
-				if len(svc.ExternalIP) > 0 {
-					externalIP = strings.Join(svc.ExternalIP, ",")
-				}
-
-				ports := ""
-				for i, port := range svc.Ports {
-					if i > 0 {
-						ports += ","
-					}
-					ports += fmt.Sprintf("%d/%s", port.Port, port.Protocol)
-				}
-
-				output += fmt.Sprintf("%-30s %-15s %-12s %-15s %-20s %s\n",
-					truncateString(svc.Name, 29),
-					svc.Namespace,
-					svc.Type,
-					svc.ClusterIP,
-					truncateString(externalIP, 19),
-					ports,
-				)
-			}
+			output += rows
+		}
+		if more {
+			output += formatMore("services", following, next)
 		}
 
-		svcResult := types.ListServicesResult{Status: "success", Services: services, Count: len(services)}
+		svcResult := types.ListServicesResult{Status: "success", Services: services, Count: len(services), NextCursor: next, Remaining: following}
 		jsonPayload := toJSONString(svcResult)
 		return &mcp.CallToolResultFor[types.ListServicesResult]{
 			Content: []mcp.Content{
@@ -255,8 +376,34 @@ func ListServices(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc
 	}
 }
 
-// ListDeployments lists deployments in the cluster with optional namespace and label filtering
-func ListDeployments(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListDeploymentsParams]) (*mcp.CallToolResultFor[types.ListDeploymentsResult], error) {
+// formatServiceRow formats one line of the service table
+func formatServiceRow(svc types.ServiceInfo) string {
+	externalIP := "<none>"
+	if len(svc.ExternalIP) > 0 {
+		externalIP = strings.Join(svc.ExternalIP, ",")
+	}
+
+	ports := ""
+	for i, port := range svc.Ports {
+		if i > 0 {
+			ports += ","
+		}
+		ports += fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+	}
+
+	return fmt.Sprintf("%-30s %-15s %-12s %-15s %-20s %s\n",
+		truncateString(svc.Name, 29),
+		svc.Namespace,
+		svc.Type,
+		svc.ClusterIP,
+		truncateString(externalIP, 19),
+		ports,
+	)
+}
+
+// ListDeployments lists deployments in the cluster with optional namespace and
+// label filtering, a page of at most limit deployments and budget bytes per call
+func ListDeployments(k8sClient *kubernetes.Clientset, budget int) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListDeploymentsParams]) (*mcp.CallToolResultFor[types.ListDeploymentsResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListDeploymentsParams]) (*mcp.CallToolResultFor[types.ListDeploymentsResult], error) {
 		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
@@ -265,15 +412,20 @@ func ListDeployments(k8sClient *kubernetes.Clientset) func(ctx context.Context,
 			listOptions.LabelSelector = params.Arguments.LabelSelector
 		}
 
-		var deploymentList *appsv1.DeploymentList
-		var err error
-
-		if params.Arguments.Namespace != "" {
-			deploymentList, err = k8sClient.AppsV1().Deployments(params.Arguments.Namespace).List(ctx, listOptions)
-		} else {
-			deploymentList, err = k8sClient.AppsV1().Deployments("").List(ctx, listOptions)
+		pager, err := newPager(params.Arguments.Cursor, params.Arguments.Limit, budget)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.ListDeploymentsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
 		}
-
+		items, err := fetchPage(ctx, pager, listOptions, nil, "deployments",
+			func(ctx context.Context, listOptions metav1.ListOptions) ([]appsv1.Deployment, metav1.ListMeta, error) {
+				list, err := k8sClient.AppsV1().Deployments(params.Arguments.Namespace).List(ctx, listOptions)
+				if err != nil {
+					return nil, metav1.ListMeta{}, err
+				}
+				return list.Items, list.ListMeta, nil
+			})
 		if err != nil {
 			return &mcp.CallToolResultFor[types.ListDeploymentsResult]{
 				Content: []mcp.Content{&mcp.TextContent{
@@ -283,7 +435,9 @@ func ListDeployments(k8sClient *kubernetes.Clientset) func(ctx context.Context,
 		}
 
 		var deployments []types.DeploymentInfo
-		for _, deploy := range deploymentList.Items {
+		var rows string
+		cut := len(items)
+		for i, deploy := range items {
 			deploymentInfo := types.DeploymentInfo{
 				Name:      deploy.Name,
 				Namespace: deploy.Namespace,
@@ -300,8 +454,22 @@ func ListDeployments(k8sClient *kubernetes.Clientset) func(ctx context.Context,
 				deploymentInfo.Strategy = string(deploy.Spec.Strategy.Type)
 			}
 
+			row := fmt.Sprintf("%-30s %-15s %-8s %-10d %-10d %s\n",
+				truncateString(deploymentInfo.Name, 29),
+				deploymentInfo.Namespace,
+				deploymentInfo.Ready,
+				deploymentInfo.UpToDate,
+				deploymentInfo.Available,
+				deploymentInfo.Age,
+			)
+			if !pager.fits(len(row) + len(toJSONString(deploymentInfo))) {
+				cut = i
+				break
+			}
 			deployments = append(deployments, deploymentInfo)
+			rows += row
 		}
+		next, following, more := pager.done(cut)
 
 		// Format output
 		var output string
@@ -318,20 +486,13 @@ func ListDeployments(k8sClient *kubernetes.Clientset) func(ctx context.Context,
 			output += fmt.Sprintf("%-30s %-15s %-8s %-10s %-10s %s\n",
 				"NAME", "NAMESPACE", "READY", "UP-TO-DATE", "AVAILABLE", "AGE")
 			output += strings.Repeat("-", 90) + "\n"
-
-			for _, deploy := range deployments {
-				output += fmt.Sprintf("%-30s %-15s %-8s %-10d %-10d %s\n",
-					truncateString(deploy.Name, 29),
-					deploy.Namespace,
-					deploy.Ready,
-					deploy.UpToDate,
-					deploy.Available,
-					deploy.Age,
-				)
-			}
+			output += rows
+		}
+		if more {
+			output += formatMore("deployments", following, next)
 		}
 
-		depResult := types.ListDeploymentsResult{Status: "success", Deployments: deployments, Count: len(deployments)}
+		depResult := types.ListDeploymentsResult{Status: "success", Deployments: deployments, Count: len(deployments), NextCursor: next, Remaining: following}
 		jsonPayload := toJSONString(depResult)
 		return &mcp.CallToolResultFor[types.ListDeploymentsResult]{
 			Content: []mcp.Content{
@@ -342,8 +503,9 @@ func ListDeployments(k8sClient *kubernetes.Clientset) func(ctx context.Context,
 	}
 }
 
-// ListConfigMaps lists configmaps in the cluster with optional namespace and label filtering
-func ListConfigMaps(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListConfigMapsParams]) (*mcp.CallToolResultFor[types.ListConfigMapsResult], error) {
+// ListConfigMaps lists configmaps in the cluster with optional namespace and
+// label filtering, a page of at most limit configmaps and budget bytes per call
+func ListConfigMaps(k8sClient *kubernetes.Clientset, budget int) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListConfigMapsParams]) (*mcp.CallToolResultFor[types.ListConfigMapsResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListConfigMapsParams]) (*mcp.CallToolResultFor[types.ListConfigMapsResult], error) {
 		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
@@ -352,15 +514,20 @@ func ListConfigMaps(k8sClient *kubernetes.Clientset) func(ctx context.Context, c
 			listOptions.LabelSelector = params.Arguments.LabelSelector
 		}
 
-		var configMapList *corev1.ConfigMapList
-		var err error
-
-		if params.Arguments.Namespace != "" {
-			configMapList, err = k8sClient.CoreV1().ConfigMaps(params.Arguments.Namespace).List(ctx, listOptions)
-		} else {
-			configMapList, err = k8sClient.CoreV1().ConfigMaps("").List(ctx, listOptions)
+		pager, err := newPager(params.Arguments.Cursor, params.Arguments.Limit, budget)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.ListConfigMapsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
 		}
-
+		items, err := fetchPage(ctx, pager, listOptions, nil, "configmaps",
+			func(ctx context.Context, listOptions metav1.ListOptions) ([]corev1.ConfigMap, metav1.ListMeta, error) {
+				list, err := k8sClient.CoreV1().ConfigMaps(params.Arguments.Namespace).List(ctx, listOptions)
+				if err != nil {
+					return nil, metav1.ListMeta{}, err
+				}
+				return list.Items, list.ListMeta, nil
+			})
 		if err != nil {
 			return &mcp.CallToolResultFor[types.ListConfigMapsResult]{
 				Content: []mcp.Content{&mcp.TextContent{
@@ -370,7 +537,9 @@ func ListConfigMaps(k8sClient *kubernetes.Clientset) func(ctx context.Context, c
 		}
 
 		var configMaps []types.ConfigMapInfo
-		for _, cm := range configMapList.Items {
+		var rows string
+		cut := len(items)
+		for i, cm := range items {
 			var keys []string
 			for key := range cm.Data {
 				keys = append(keys, key)
@@ -385,8 +554,20 @@ func ListConfigMaps(k8sClient *kubernetes.Clientset) func(ctx context.Context, c
 				CreatedAt: cm.CreationTimestamp.String(),
 			}
 
+			row := fmt.Sprintf("%-30s %-15s %-5d %s\n",
+				truncateString(configMapInfo.Name, 29),
+				configMapInfo.Namespace,
+				configMapInfo.DataCount,
+				truncateString(strings.Join(keys, ","), 40),
+			)
+			if !pager.fits(len(row) + len(toJSONString(configMapInfo))) {
+				cut = i
+				break
+			}
 			configMaps = append(configMaps, configMapInfo)
+			rows += row
 		}
+		next, following, more := pager.done(cut)
 
 		// Format output
 		var output string
@@ -403,23 +584,13 @@ func ListConfigMaps(k8sClient *kubernetes.Clientset) func(ctx context.Context, c
 			output += fmt.Sprintf("%-30s %-15s %-5s %s\n",
 				"NAME", "NAMESPACE", "DATA", "KEYS")
 			output += strings.Repeat("-", 80) + "\n"
-
-			for _, cm := range configMaps {
-				keys := strings.Join(cm.Keys, ",")
-				if len(keys) > 40 {
-					keys = keys[:37] + "..."
-				}
-
-				output += fmt.Sprintf("%-30s %-15s %-5d %s\n",
-					truncateString(cm.Name, 29),
-					cm.Namespace,
-					cm.DataCount,
-					keys,
-				)
-			}
+			output += rows
+		}
+		if more {
+			output += formatMore("configmaps", following, next)
 		}
 
-		cmResult := types.ListConfigMapsResult{Status: "success", ConfigMaps: configMaps, Count: len(configMaps)}
+		cmResult := types.ListConfigMapsResult{Status: "success", ConfigMaps: configMaps, Count: len(configMaps), NextCursor: next, Remaining: following}
 		jsonPayload := toJSONString(cmResult)
 		return &mcp.CallToolResultFor[types.ListConfigMapsResult]{
 			Content: []mcp.Content{
@@ -470,30 +641,31 @@ func ListEvents(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *m
 			}, nil
 		}
 
+		var since time.Time
+		if params.Arguments.SinceSeconds > 0 {
+			since = time.Now().Add(-time.Duration(params.Arguments.SinceSeconds) * time.Second)
+		}
+
+		// Oldest first, as kubectl get events --sort-by=.lastTimestamp
+		items := el.Items
+		sort.SliceStable(items, func(i, j int) bool {
+			_, a := eventTimes(items[i])
+			_, b := eventTimes(items[j])
+			return a.Before(b)
+		})
+
 		var events []types.EventInfo
-		for _, e := range el.Items {
+		for _, e := range items {
 			if params.Arguments.Type != "" && string(e.Type) != params.Arguments.Type {
 				continue
 			}
 			if params.Arguments.Reason != "" && e.Reason != params.Arguments.Reason {
 				continue
 			}
-			info := types.EventInfo{
-				Type:              string(e.Type),
-				Reason:            e.Reason,
-				Message:           e.Note,
-				Count:             e.DeprecatedCount,
-				InvolvedKind:      e.Regarding.Kind,
-				InvolvedName:      e.Regarding.Name,
-				InvolvedNamespace: e.Regarding.Namespace,
-			}
-			if !e.DeprecatedFirstTimestamp.IsZero() {
-				info.FirstSeen = e.DeprecatedFirstTimestamp.Time.Format(time.RFC3339)
-			}
-			if !e.DeprecatedLastTimestamp.IsZero() {
-				info.LastSeen = e.DeprecatedLastTimestamp.Time.Format(time.RFC3339)
+			if _, last := eventTimes(e); !since.IsZero() && last.Before(since) {
+				continue
 			}
-			events = append(events, info)
+			events = append(events, eventInfo(e))
 		}
 
 		// Build text output
@@ -528,6 +700,59 @@ func ListEvents(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *m
 	}
 }
 
+// eventInfo summarises an event
+func eventInfo(e eventsv1.Event) types.EventInfo {
+	info := types.EventInfo{
+		Type:              string(e.Type),
+		Reason:            e.Reason,
+		Message:           e.Note,
+		Count:             eventCount(e),
+		InvolvedKind:      e.Regarding.Kind,
+		InvolvedName:      e.Regarding.Name,
+		InvolvedNamespace: e.Regarding.Namespace,
+	}
+	first, last := eventTimes(e)
+	if !first.IsZero() {
+		info.FirstSeen = first.Format(time.RFC3339)
+	}
+	if !last.IsZero() {
+		info.LastSeen = last.Format(time.RFC3339)
+	}
+	return info
+}
+
+// eventTimes returns when an event was first and last observed. Events
+// written through events.k8s.io carry eventTime and series, those written
+// through the core API the deprecated timestamps; creation is the fallback.
+func eventTimes(e eventsv1.Event) (time.Time, time.Time) {
+	first := e.DeprecatedFirstTimestamp.Time
+	if first.IsZero() {
+		first = e.EventTime.Time
+	}
+	if first.IsZero() {
+		first = e.CreationTimestamp.Time
+	}
+	last := e.DeprecatedLastTimestamp.Time
+	if e.Series != nil && e.Series.LastObservedTime.After(last) {
+		last = e.Series.LastObservedTime.Time
+	}
+	if last.IsZero() {
+		last = first
+	}
+	return first, last
+}
+
+// eventCount returns how often an event was observed
+func eventCount(e eventsv1.Event) int32 {
+	if e.Series != nil && e.Series.Count > e.DeprecatedCount {
+		return e.Series.Count
+	}
+	if e.DeprecatedCount == 0 {
+		return 1
+	}
+	return e.DeprecatedCount
+}
+
 func appendFieldSelector(current *string, key, value string) {
 	if *current == "" {
 		*current = fmt.Sprintf("%s=%s", key, value)
@@ -564,16 +789,6 @@ func truncateString(s string, maxLen int) string {
 // GetPodLogs gets logs from a specific pod and container
 func GetPodLogs(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetPodLogsParams]) (*mcp.CallToolResultFor[types.GetPodLogsResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetPodLogsParams]) (*mcp.CallToolResultFor[types.GetPodLogsResult], error) {
-		// For follow mode, don't hard-timeout the stream immediately
-		if params.Arguments.Follow {
-			var cancel context.CancelFunc
-			ctx, cancel = context.WithCancel(ctx)
-			defer cancel()
-		} else {
-			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
-			defer cancel()
-		}
 		// Validate required parameters
 		if params.Arguments.Namespace == "" {
 			return &mcp.CallToolResultFor[types.GetPodLogsResult]{
@@ -591,10 +806,35 @@ func GetPodLogs(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *m
 			}, nil
 		}
 
-		// Set up log options
+		filter, err := logs.NewFilter(params.Arguments.Include, params.Arguments.Exclude, params.Arguments.MinLevel)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.GetPodLogsResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				}},
+			}, nil
+		}
+		if o := params.Arguments.Output; o != "" && o != "lines" && o != "summary" {
+			return &mcp.CallToolResultFor[types.GetPodLogsResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error: invalid output '%s', use lines or summary", o),
+				}},
+			}, nil
+		}
+
+		// Follow mode streams chunks and bounds itself
+		if params.Arguments.Follow {
+			return followPodLogs(ctx, cc, k8sClient, params, filter)
+		}
+		processing := needsLogProcessing(params.Arguments)
+
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		// Set up log options; summaries need timestamps for first and last seen
 		logOptions := &corev1.PodLogOptions{
-			Follow:   params.Arguments.Follow,
-			Previous: params.Arguments.Previous,
+			Previous:   params.Arguments.Previous,
+			Timestamps: params.Arguments.Output == "summary",
 		}
 
 		// Set container if specified
@@ -602,9 +842,13 @@ func GetPodLogs(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *m
 			logOptions.Container = params.Arguments.Container
 		}
 
-		// Set tail lines if specified (default to 50 if not specified)
+		// Set tail lines if specified (default to 50 if not specified,
+		// or to more when the lines are filtered on the server)
 		if params.Arguments.Lines > 0 {
 			logOptions.TailLines = &params.Arguments.Lines
+		} else if processing {
+			defaultLines := int64(defaultProcessedLogLines)
+			logOptions.TailLines = &defaultLines
 		} else {
 			defaultLines := int64(50)
 			logOptions.TailLines = &defaultLines
@@ -634,10 +878,6 @@ func GetPodLogs(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *m
 		scanner := bufio.NewScanner(podLogs)
 		for scanner.Scan() {
 			logLines = append(logLines, scanner.Text())
-			if params.Arguments.Follow {
-				// In MCP context, we still need to return a value; collect until context cancelled
-				// If follow is used with MCP, clients should provide their own streaming channel; we will still aggregate
-			}
 		}
 
 		if err := scanner.Err(); err != nil {
@@ -649,6 +889,10 @@ func GetPodLogs(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *m
 			}, nil
 		}
 
+		if processing {
+			return processPodLogs(params.Arguments, filter, logLines), nil
+		}
+
 		// Format output
 		var output string
 		if len(logLines) == 0 {
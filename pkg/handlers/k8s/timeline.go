@@ -0,0 +1,447 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const (
+	defaultTimelineWindow  = time.Hour
+	defaultTimelineEntries = 300
+)
+
+// timelineEntry is a timeline entry before formatting
+type timelineEntry struct {
+	first, last time.Time
+	source      string
+	object      string
+	warning     bool
+	reason      string
+	message     string
+	count       int
+}
+
+// timeline collects entries within a window, merging repeats of the same
+// occurrence on the same object
+type timeline struct {
+	since   time.Time
+	entries map[string]*timelineEntry
+}
+
+func (t *timeline) add(e timelineEntry) {
+	if e.last.IsZero() {
+		e.last = e.first
+	}
+	if e.last.Before(t.since) {
+		return
+	}
+	if e.count == 0 {
+		e.count = 1
+	}
+	key := strings.Join([]string{e.source, e.object, e.reason, e.message}, "\x00")
+	existing, ok := t.entries[key]
+	if !ok {
+		t.entries[key] = &e
+		return
+	}
+	existing.count += e.count
+	if e.first.Before(existing.first) {
+		existing.first = e.first
+	}
+	if e.last.After(existing.last) {
+		existing.last = e.last
+	}
+}
+
+// BuildIncidentTimeline merges events, Sail condition transitions, pod and
+// container starts and terminations and ReplicaSet rollouts of a namespace or
+// an Istio control plane into one chronological timeline
+func BuildIncidentTimeline(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.BuildIncidentTimelineParams]) (*mcp.CallToolResultFor[types.BuildIncidentTimelineResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.BuildIncidentTimelineParams]) (*mcp.CallToolResultFor[types.BuildIncidentTimelineResult], error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		args := params.Arguments
+
+		if (args.Namespace == "") == (args.Istio == "") {
+			return &mcp.CallToolResultFor[types.BuildIncidentTimelineResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: "Error: exactly one of namespace or istio is required"}},
+			}, nil
+		}
+
+		window := defaultTimelineWindow
+		if args.SinceSeconds > 0 {
+			window = time.Duration(args.SinceSeconds) * time.Second
+		}
+		tl := &timeline{since: time.Now().Add(-window), entries: map[string]*timelineEntry{}}
+		result := types.BuildIncidentTimelineResult{Status: "success", Since: tl.since.UTC().Format(time.RFC3339)}
+
+		// The Sail resources in scope and the namespaces they run in
+		var sailObjects []*unstructured.Unstructured
+		var namespaces []string
+		if args.Istio != "" {
+			result.Scope = "Istio " + args.Istio
+			objs, namespace, err := istioTree(ctx, dynamicClient, registry, args.Istio)
+			if err != nil {
+				return &mcp.CallToolResultFor[types.BuildIncidentTimelineResult]{
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+				}, nil
+			}
+			sailObjects, namespaces = objs, []string{namespace}
+		} else {
+			result.Scope = "namespace " + args.Namespace
+			namespaces = []string{args.Namespace}
+			objs, notes := sailObjectsInNamespace(ctx, dynamicClient, registry, args.Namespace)
+			sailObjects = objs
+			result.Notes = append(result.Notes, notes...)
+		}
+
+		for _, obj := range sailObjects {
+			addConditionTransitions(tl, obj)
+			// Sail resources are cluster-scoped; their events are in "default"
+			fs := ""
+			appendFieldSelector(&fs, "regarding.kind", obj.GetKind())
+			appendFieldSelector(&fs, "regarding.name", obj.GetName())
+			if el, err := k8sClient.EventsV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: fs}); err == nil {
+				for _, e := range el.Items {
+					addEvent(tl, e)
+				}
+			}
+		}
+
+		for _, ns := range namespaces {
+			if el, err := k8sClient.EventsV1().Events(ns).List(ctx, metav1.ListOptions{}); err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("Events in %s not read: %v", ns, err))
+			} else {
+				for _, e := range el.Items {
+					addEvent(tl, e)
+				}
+			}
+			if pods, err := k8sClient.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{}); err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("Pods in %s not read: %v", ns, err))
+			} else {
+				for i := range pods.Items {
+					addPodLifecycle(tl, &pods.Items[i])
+				}
+			}
+			if rsList, err := k8sClient.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{}); err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("ReplicaSets in %s not read: %v", ns, err))
+			} else {
+				for i := range rsList.Items {
+					addRollout(tl, &rsList.Items[i])
+				}
+			}
+		}
+
+		entries := make([]*timelineEntry, 0, len(tl.entries))
+		for _, e := range tl.entries {
+			if args.WarningsOnly && !e.warning {
+				continue
+			}
+			entries = append(entries, e)
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			if !entries[i].first.Equal(entries[j].first) {
+				return entries[i].first.Before(entries[j].first)
+			}
+			return entries[i].object < entries[j].object
+		})
+		maxEntries := args.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultTimelineEntries
+		}
+		if len(entries) > maxEntries {
+			result.Dropped = len(entries) - maxEntries
+			entries = entries[result.Dropped:]
+		}
+
+		groups := map[string]*types.TimelineObject{}
+		result.Entries = []types.TimelineEntry{}
+		for _, e := range entries {
+			entry := types.TimelineEntry{
+				Time:    e.first.UTC().Format(time.RFC3339),
+				Source:  e.source,
+				Object:  e.object,
+				Type:    "Normal",
+				Reason:  e.reason,
+				Message: e.message,
+				Count:   e.count,
+			}
+			if e.warning {
+				entry.Type = "Warning"
+			}
+			if e.last.After(e.first) {
+				entry.LastTime = e.last.UTC().Format(time.RFC3339)
+			}
+			result.Entries = append(result.Entries, entry)
+
+			g, ok := groups[e.object]
+			if !ok {
+				g = &types.TimelineObject{Object: e.object, FirstTime: entry.Time}
+				groups[e.object] = g
+			}
+			g.Entries++
+			if e.warning {
+				g.Warnings++
+			}
+			// RFC 3339 times in UTC compare as strings
+			if last := e.last.UTC().Format(time.RFC3339); last > g.LastTime {
+				g.LastTime = last
+			}
+		}
+		result.Objects = []types.TimelineObject{}
+		for _, g := range groups {
+			result.Objects = append(result.Objects, *g)
+		}
+		sort.Slice(result.Objects, func(i, j int) bool {
+			a, b := result.Objects[i], result.Objects[j]
+			if a.Warnings != b.Warnings {
+				return a.Warnings > b.Warnings
+			}
+			return a.Object < b.Object
+		})
+
+		return &mcp.CallToolResultFor[types.BuildIncidentTimelineResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatIncidentTimeline(result)},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// istioTree returns an Istio resource, the IstioRevisions it owns and the
+// IstioRevisionTags pointing at it, with its control plane namespace
+func istioTree(ctx context.Context, dynamicClient dynamic.Interface, registry *sail.Registry, name string) ([]*unstructured.Unstructured, string, error) {
+	gvr, err := registry.GVR(ctx, sail.KindIstio)
+	if err != nil {
+		return nil, "", err
+	}
+	istio, err := dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get Istio '%s': %w", name, err)
+	}
+	objects := []*unstructured.Unstructured{istio}
+	namespace, _, _ := unstructured.NestedString(istio.Object, "spec", "namespace")
+	if namespace == "" {
+		namespace = "istio-system"
+	}
+
+	revisions := map[string]bool{}
+	if gvr, err := registry.GVR(ctx, sail.KindIstioRevision); err == nil {
+		if list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				for _, ref := range list.Items[i].GetOwnerReferences() {
+					if ref.Kind == sail.KindIstio.Kind && ref.Name == name {
+						objects = append(objects, &list.Items[i])
+						revisions[list.Items[i].GetName()] = true
+					}
+				}
+			}
+		}
+	}
+	if gvr, err := registry.GVR(ctx, sail.KindIstioRevisionTag); err == nil {
+		if list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{}); err == nil {
+			for i := range list.Items {
+				tag, err := sail.Decode[sail.IstioRevisionTag](&list.Items[i])
+				if err != nil {
+					continue
+				}
+				ref := tag.Spec.TargetRef
+				if (ref.Kind == sail.KindIstio.Kind && ref.Name == name) || (ref.Kind == sail.KindIstioRevision.Kind && revisions[ref.Name]) {
+					objects = append(objects, &list.Items[i])
+				}
+			}
+		}
+	}
+	return objects, namespace, nil
+}
+
+// sailObjectsInNamespace returns the Sail resources deploying into a namespace
+func sailObjectsInNamespace(ctx context.Context, dynamicClient dynamic.Interface, registry *sail.Registry, namespace string) ([]*unstructured.Unstructured, []string) {
+	var objects []*unstructured.Unstructured
+	var notes []string
+	for _, kind := range sail.Kinds {
+		gvr, err := registry.GVR(ctx, kind)
+		if err != nil {
+			if !sail.IsNotInstalled(err) {
+				notes = append(notes, fmt.Sprintf("%s not read: %v", kind.Kind, err))
+			}
+			continue
+		}
+		list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s not read: %v", kind.Kind, err))
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			ns, _, _ := unstructured.NestedString(obj.Object, "spec", "namespace")
+			if ns == "" && kind == sail.KindIstioRevisionTag {
+				ns, _, _ = unstructured.NestedString(obj.Object, "status", "istiodNamespace")
+			}
+			if ns == namespace {
+				objects = append(objects, obj)
+			}
+		}
+	}
+	return objects, notes
+}
+
+// addConditionTransitions adds the condition transitions of a Sail resource
+func addConditionTransitions(tl *timeline, obj *unstructured.Unstructured) {
+	o, err := sail.Decode[sail.Object](obj)
+	if err != nil {
+		return
+	}
+	object := obj.GetKind() + "/" + obj.GetName()
+	for _, c := range o.Status.Conditions {
+		if c.LastTransitionTime.IsZero() {
+			continue
+		}
+		message := c.Type + "=" + c.Status
+		if c.Reason != "" {
+			message += " (" + c.Reason + ")"
+		}
+		if c.Message != "" {
+			message += ": " + c.Message
+		}
+		tl.add(timelineEntry{
+			first:   c.LastTransitionTime.Time,
+			source:  "condition",
+			object:  object,
+			warning: c.Status != "True",
+			reason:  c.Type,
+			message: message,
+		})
+	}
+}
+
+// addEvent adds a Kubernetes event
+func addEvent(tl *timeline, e eventsv1.Event) {
+	first, last := eventTimes(e)
+	object := e.Regarding.Kind + "/"
+	if e.Regarding.Namespace != "" {
+		object += e.Regarding.Namespace + "/"
+	}
+	object += e.Regarding.Name
+	tl.add(timelineEntry{
+		first:   first,
+		last:    last,
+		source:  "event",
+		object:  object,
+		warning: e.Type == corev1.EventTypeWarning,
+		reason:  e.Reason,
+		message: e.Note,
+		count:   int(eventCount(e)),
+	})
+}
+
+// addPodLifecycle adds pod starts and container starts and terminations
+func addPodLifecycle(tl *timeline, pod *corev1.Pod) {
+	object := "Pod/" + pod.Namespace + "/" + pod.Name
+	if pod.Status.StartTime != nil {
+		tl.add(timelineEntry{first: pod.Status.StartTime.Time, source: "pod", object: object, reason: "PodStarted", message: "Pod started on node " + pod.Spec.NodeName})
+	}
+	if pod.DeletionTimestamp != nil {
+		tl.add(timelineEntry{first: pod.DeletionTimestamp.Time, source: "pod", object: object, reason: "PodTerminating", message: "Pod deletion requested"})
+	}
+
+	for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if r := s.State.Running; r != nil && s.RestartCount > 0 {
+			tl.add(timelineEntry{
+				first:   r.StartedAt.Time,
+				source:  "container",
+				object:  object,
+				reason:  "ContainerRestarted",
+				message: fmt.Sprintf("Container %s started again (restart %d)", s.Name, s.RestartCount),
+			})
+		}
+		for _, t := range []*corev1.ContainerStateTerminated{s.LastTerminationState.Terminated, s.State.Terminated} {
+			if t == nil || t.FinishedAt.IsZero() {
+				continue
+			}
+			tl.add(timelineEntry{
+				first:   t.FinishedAt.Time,
+				source:  "container",
+				object:  object,
+				warning: t.ExitCode != 0,
+				reason:  "ContainerTerminated",
+				message: fmt.Sprintf("Container %s terminated: %s, exit code %d", s.Name, t.Reason, t.ExitCode),
+			})
+		}
+	}
+}
+
+// addRollout adds the creation of a ReplicaSet, which marks a Deployment rollout
+func addRollout(tl *timeline, rs *appsv1.ReplicaSet) {
+	object := "ReplicaSet/" + rs.Namespace + "/" + rs.Name
+	for _, ref := range rs.OwnerReferences {
+		if ref.Kind == "Deployment" {
+			object = "Deployment/" + rs.Namespace + "/" + ref.Name
+		}
+	}
+	message := fmt.Sprintf("ReplicaSet %s created", rs.Name)
+	if rev := rs.Annotations["deployment.kubernetes.io/revision"]; rev != "" {
+		message += " (revision " + rev + ")"
+	}
+	var images []string
+	for _, c := range rs.Spec.Template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	message += ": " + strings.Join(images, ", ")
+	tl.add(timelineEntry{first: rs.CreationTimestamp.Time, source: "rollout", object: object, reason: "Rollout", message: message})
+}
+
+// formatIncidentTimeline formats the timeline and its per-object grouping
+func formatIncidentTimeline(result types.BuildIncidentTimelineResult) string {
+	output := fmt.Sprintf("=== Incident timeline for %s since %s ===\n", result.Scope, result.Since)
+	for _, n := range result.Notes {
+		output += fmt.Sprintf("⚠️ %s\n", n)
+	}
+	if len(result.Entries) == 0 {
+		return output + "\nNothing happened in this window"
+	}
+	if result.Dropped > 0 {
+		output += fmt.Sprintf("(%d older entries not shown)\n", result.Dropped)
+	}
+
+	output += "\n"
+	for _, e := range result.Entries {
+		icon := "  "
+		if e.Type == "Warning" {
+			icon = "⚠️"
+		}
+		line := fmt.Sprintf("%s %s [%s] %s %s", e.Time, icon, e.Source, e.Object, e.Reason)
+		if e.Message != "" {
+			line += ": " + truncateString(e.Message, 160)
+		}
+		if e.Count > 1 {
+			line += fmt.Sprintf(" (×%d)", e.Count)
+		}
+		if e.LastTime != "" {
+			line += fmt.Sprintf(" until %s", e.LastTime)
+		}
+		output += line + "\n"
+	}
+
+	output += fmt.Sprintf("\nBy object (%d):\n", len(result.Objects))
+	for _, o := range result.Objects {
+		output += fmt.Sprintf("  %s: %d entries, %d warnings, %s to %s\n", o.Object, o.Entries, o.Warnings, o.FirstTime, o.LastTime)
+	}
+	return output
+}
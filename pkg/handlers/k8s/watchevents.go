@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/events"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const (
+	defaultEventWatch = time.Minute
+	maxEventWatch     = 10 * time.Minute
+	// watchedEventsShown is how many aggregated events the final summary lists
+	watchedEventsShown = 30
+)
+
+// WatchEvents watches events for a while, streaming new events, repeat
+// summaries and Warning storm alerts as progress (or log) notifications, and
+// returns the deduplicated events when the window ends
+func WatchEvents(k8sClient *kubernetes.Clientset) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.WatchEventsParams]) (*mcp.CallToolResultFor[types.WatchEventsResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.WatchEventsParams]) (*mcp.CallToolResultFor[types.WatchEventsResult], error) {
+		args := params.Arguments
+
+		window := defaultEventWatch
+		if args.DurationSeconds > 0 {
+			window = time.Duration(args.DurationSeconds) * time.Second
+		}
+		if window > maxEventWatch {
+			window = maxEventWatch
+		}
+		ctx, cancel := context.WithTimeout(ctx, window)
+		defer cancel()
+
+		fs := ""
+		if args.InvolvedKind != "" {
+			appendFieldSelector(&fs, "regarding.kind", args.InvolvedKind)
+		}
+		if args.InvolvedName != "" {
+			appendFieldSelector(&fs, "regarding.name", args.InvolvedName)
+		}
+
+		logger := "events"
+		if args.Namespace != "" {
+			logger += "/" + args.Namespace
+		}
+		sink := newLogChunkSink(cc, params, logger, 0)
+		// Lines are sent as they happen; the last repeat summary comes
+		// after the window ended
+		notifyCtx := context.WithoutCancel(ctx)
+		emit := func(line string) {
+			sink.add(notifyCtx, line)
+			sink.flush(notifyCtx)
+		}
+
+		result, err := events.Watch(ctx, k8sClient, events.Options{
+			Namespace:      args.Namespace,
+			FieldSelector:  fs,
+			WarningsOnly:   args.WarningsOnly,
+			StormThreshold: args.StormThreshold,
+			StormWindow:    time.Duration(args.StormWindowSeconds) * time.Second,
+		}, emit)
+		if err != nil && result.Occurrences == 0 {
+			return &mcp.CallToolResultFor[types.WatchEventsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error watching events: %v", err)}},
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[types.WatchEventsResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatWatchedEvents(args, result, window)},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// formatWatchedEvents formats the summary of an event watch
+func formatWatchedEvents(args types.WatchEventsParams, result types.WatchEventsResult, window time.Duration) string {
+	scope := "all namespaces"
+	if args.Namespace != "" {
+		scope = fmt.Sprintf("namespace '%s'", args.Namespace)
+	}
+	output := fmt.Sprintf("=== Events watched in %s ===\n", scope)
+	switch result.StopReason {
+	case "timeout":
+		output += fmt.Sprintf("Stopped: watch window of %s ended\n", window)
+	case "cancelled":
+		output += "Stopped: request cancelled\n"
+	case "error":
+		output += fmt.Sprintf("Stopped: %s\n", result.Error)
+	}
+	output += fmt.Sprintf("Occurrences: %d, unique events: %d, in %s\n", result.Occurrences, result.Unique, result.Duration)
+
+	if len(result.Alerts) > 0 {
+		output += fmt.Sprintf("\n🚨 Warning storms (%d):\n", len(result.Alerts))
+		for _, a := range result.Alerts {
+			output += fmt.Sprintf("  • %s: %d %s events within %ds at %s\n    %s\n",
+				a.Component, a.Count, a.Reason, a.WindowSeconds, a.Time, truncateString(a.Example, 160))
+		}
+	}
+
+	if len(result.Events) == 0 {
+		return output + "\nNo events during the watch"
+	}
+	output += "\nEvents, most frequent first:\n"
+	for i, e := range result.Events {
+		if i == watchedEventsShown {
+			output += fmt.Sprintf("  ... %d more\n", len(result.Events)-watchedEventsShown)
+			break
+		}
+		icon := "  "
+		if e.Type == "Warning" {
+			icon = "⚠️"
+		}
+		output += fmt.Sprintf("  %s ×%-4d %s %s: %s\n", icon, e.Count, e.Reason, e.Object, truncateString(e.Message, 120))
+	}
+	return output
+}
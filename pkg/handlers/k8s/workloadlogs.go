@@ -0,0 +1,271 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/logs"
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+const (
+	defaultWorkloadLogLines = 100
+	defaultWorkloadLogBytes = 256 * 1024
+	// workloadLogConcurrency is how many container logs are read at once
+	workloadLogConcurrency = 8
+)
+
+// workloadLogLine is one timestamped line of a container
+type workloadLogLine struct {
+	time time.Time
+	text string // prefixed with the pod (and container)
+}
+
+// GetWorkloadLogs reads the logs of every pod and container of a workload
+// concurrently and interleaves them by timestamp
+func GetWorkloadLogs(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetWorkloadLogsParams]) (*mcp.CallToolResultFor[types.GetWorkloadLogsResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetWorkloadLogsParams]) (*mcp.CallToolResultFor[types.GetWorkloadLogsResult], error) {
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		args := params.Arguments
+
+		namespace, selector, err := resolveWorkloadSelector(ctx, k8sClient, dynamicClient, registry, args)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.GetWorkloadLogsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			}, nil
+		}
+
+		podList, err := k8sClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return &mcp.CallToolResultFor[types.GetWorkloadLogsResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error listing pods: %v", err)}},
+			}, nil
+		}
+		if len(podList.Items) == 0 {
+			return &mcp.CallToolResultFor[types.GetWorkloadLogsResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("No pods found in namespace '%s' with selector '%s'", namespace, selector),
+				}},
+			}, nil
+		}
+
+		// One stream per pod and container
+		type target struct{ pod, container string }
+		var targets []target
+		for _, pod := range podList.Items {
+			for _, c := range pod.Spec.Containers {
+				if args.Container == "" || c.Name == args.Container {
+					targets = append(targets, target{pod.Name, c.Name})
+				}
+			}
+		}
+		if len(targets) == 0 {
+			return &mcp.CallToolResultFor[types.GetWorkloadLogsResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("No container '%s' in the %d matching pods", args.Container, len(podList.Items)),
+				}},
+			}, nil
+		}
+
+		tail := args.Lines
+		if tail <= 0 {
+			tail = defaultWorkloadLogLines
+		}
+
+		streams := make([]types.WorkloadLogStream, len(targets))
+		lines := make([][]workloadLogLine, len(targets))
+		sem := make(chan struct{}, workloadLogConcurrency)
+		var wg sync.WaitGroup
+		for i, t := range targets {
+			wg.Add(1)
+			go func(i int, t target) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				prefix := fmt.Sprintf("[%s/%s]", t.pod, t.container)
+				if args.Container != "" {
+					prefix = fmt.Sprintf("[%s]", t.pod)
+				}
+				logOptions := &corev1.PodLogOptions{
+					Container:  t.container,
+					Timestamps: true,
+					TailLines:  &tail,
+					Previous:   args.Previous,
+				}
+				if args.SinceSeconds > 0 {
+					logOptions.SinceSeconds = &args.SinceSeconds
+				}
+				streams[i] = types.WorkloadLogStream{Pod: t.pod, Container: t.container}
+				streamLines, err := readTimestampedLogs(ctx, k8sClient, namespace, t.pod, logOptions, prefix)
+				if err != nil {
+					streams[i].Error = err.Error()
+				}
+				lines[i] = streamLines
+				streams[i].Lines = len(streamLines)
+			}(i, t)
+		}
+		wg.Wait()
+
+		var merged []workloadLogLine
+		for _, l := range lines {
+			merged = append(merged, l...)
+		}
+		sort.SliceStable(merged, func(a, b int) bool { return merged[a].time.Before(merged[b].time) })
+
+		maxBytes := args.MaxBytes
+		if maxBytes <= 0 {
+			maxBytes = defaultWorkloadLogBytes
+		}
+		// Keep the newest lines that fit in the budget
+		texts := make([]string, len(merged))
+		for i, l := range merged {
+			texts[i] = l.text
+		}
+		kept, dropped := logs.TailBytes(texts, maxBytes)
+		size := 0
+		for _, text := range kept {
+			size += len(text) + 1
+		}
+
+		result := types.GetWorkloadLogsResult{
+			Status:    "success",
+			Namespace: namespace,
+			Selector:  selector,
+			Streams:   streams,
+			Lines:     len(kept),
+			Bytes:     size,
+			Dropped:   dropped,
+			Logs:      strings.Join(kept, "\n"),
+		}
+
+		return &mcp.CallToolResultFor[types.GetWorkloadLogsResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatWorkloadLogs(result, maxBytes)},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// resolveWorkloadSelector turns the workload arguments into a namespace and label selector
+func resolveWorkloadSelector(ctx context.Context, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry, args types.GetWorkloadLogsParams) (string, string, error) {
+	set := 0
+	for _, v := range []string{args.LabelSelector, args.Deployment, args.DaemonSet, args.IstioRevision} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", "", fmt.Errorf("exactly one of label_selector, deployment, daemonset or istio_revision is required")
+	}
+
+	if args.IstioRevision != "" {
+		gvr, err := registry.GVR(ctx, sail.KindIstioRevision)
+		if err != nil {
+			return "", "", err
+		}
+		obj, err := dynamicClient.Resource(gvr).Get(ctx, args.IstioRevision, metav1.GetOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get IstioRevision '%s': %w", args.IstioRevision, err)
+		}
+		rev, err := sail.Decode[sail.IstioRevision](obj)
+		if err != nil {
+			return "", "", err
+		}
+		namespace := rev.Spec.Namespace
+		if namespace == "" {
+			namespace = "istio-system"
+		}
+		return namespace, "app=istiod,istio.io/rev=" + args.IstioRevision, nil
+	}
+
+	if args.Namespace == "" {
+		return "", "", fmt.Errorf("namespace is required")
+	}
+
+	var labelSelector *metav1.LabelSelector
+	switch {
+	case args.LabelSelector != "":
+		return args.Namespace, args.LabelSelector, nil
+	case args.Deployment != "":
+		d, err := k8sClient.AppsV1().Deployments(args.Namespace).Get(ctx, args.Deployment, metav1.GetOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get Deployment '%s': %w", args.Deployment, err)
+		}
+		labelSelector = d.Spec.Selector
+	case args.DaemonSet != "":
+		ds, err := k8sClient.AppsV1().DaemonSets(args.Namespace).Get(ctx, args.DaemonSet, metav1.GetOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get DaemonSet '%s': %w", args.DaemonSet, err)
+		}
+		labelSelector = ds.Spec.Selector
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid workload selector: %w", err)
+	}
+	return args.Namespace, selector.String(), nil
+}
+
+// readTimestampedLogs reads a container's logs fetched with Timestamps set and
+// prefixes each line. Lines without a parseable timestamp keep the previous one.
+func readTimestampedLogs(ctx context.Context, k8sClient *kubernetes.Clientset, namespace, pod string, logOptions *corev1.PodLogOptions, prefix string) ([]workloadLogLine, error) {
+	stream, err := k8sClient.CoreV1().Pods(namespace).GetLogs(pod, logOptions).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var lines []workloadLogLine
+	var last time.Time
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if ts, rest, ok := strings.Cut(line, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				last = t
+				line = rest
+			}
+		}
+		lines = append(lines, workloadLogLine{
+			time: last,
+			text: fmt.Sprintf("%s %s %s", last.UTC().Format("2006-01-02T15:04:05.000Z"), prefix, line),
+		})
+	}
+	return lines, scanner.Err()
+}
+
+// formatWorkloadLogs formats interleaved workload logs with a per-stream header
+func formatWorkloadLogs(result types.GetWorkloadLogsResult, maxBytes int) string {
+	output := fmt.Sprintf("=== Logs for selector '%s' in namespace '%s' ===\n", result.Selector, result.Namespace)
+	output += fmt.Sprintf("Streams: %d\n", len(result.Streams))
+	for _, s := range result.Streams {
+		if s.Error != "" {
+			output += fmt.Sprintf("  ❌ %s/%s: %s\n", s.Pod, s.Container, s.Error)
+		} else {
+			output += fmt.Sprintf("  • %s/%s: %d lines\n", s.Pod, s.Container, s.Lines)
+		}
+	}
+	if result.Dropped > 0 {
+		output += fmt.Sprintf("⚠️ %d older lines dropped to stay under %d bytes\n", result.Dropped, maxBytes)
+	}
+	output += fmt.Sprintf("Showing %d lines (%d bytes), oldest first:\n\n", result.Lines, result.Bytes)
+	output += result.Logs
+	return output
+}
@@ -0,0 +1,217 @@
+// Package prompts serves troubleshooting playbooks as MCP prompts. Each
+// playbook lists the tool calls to make, in order, and how to read their
+// results, so every assistant follows the same procedure.
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Playbook is a prompt and the runbook text it renders
+type Playbook struct {
+	Prompt *mcp.Prompt
+	// Defaults fills optional arguments the client left empty
+	Defaults map[string]string
+	text     *template.Template
+}
+
+func newPlaybook(prompt *mcp.Prompt, defaults map[string]string, text string) Playbook {
+	return Playbook{
+		Prompt:   prompt,
+		Defaults: defaults,
+		text:     template.Must(template.New(prompt.Name).Option("missingkey=zero").Parse(text)),
+	}
+}
+
+// GetPlaybook renders a playbook with the arguments supplied by the client
+func GetPlaybook(p Playbook) mcp.PromptHandler {
+	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.GetPromptParams) (*mcp.GetPromptResult, error) {
+		args := map[string]string{}
+		for k, v := range p.Defaults {
+			args[k] = v
+		}
+		for _, a := range p.Prompt.Arguments {
+			if v := strings.TrimSpace(params.Arguments[a.Name]); v != "" {
+				args[a.Name] = v
+			} else if a.Required {
+				return nil, fmt.Errorf("argument %q is required", a.Name)
+			}
+		}
+
+		var text strings.Builder
+		if err := p.text.Execute(&text, args); err != nil {
+			return nil, fmt.Errorf("failed to render prompt %s: %w", p.Prompt.Name, err)
+		}
+
+		return &mcp.GetPromptResult{
+			Description: p.Prompt.Description,
+			Messages: []*mcp.PromptMessage{{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: text.String()},
+			}},
+		}, nil
+	}
+}
+
+// Playbooks lists every troubleshooting playbook served by the server
+var Playbooks = []Playbook{
+	newPlaybook(&mcp.Prompt{
+		Name:        "debug-sidecar-not-injected",
+		Title:       "Debug sidecar not injected",
+		Description: "Find out why the pods of a workload run without an istio-proxy sidecar",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "namespace", Description: "Namespace of the workload", Required: true},
+			{Name: "workload", Description: "Workload name, matched against the app label of its pods", Required: true},
+			{Name: "revision", Description: "Revision or tag the namespace is expected to use"},
+		},
+	}, nil, `The pods of workload {{.workload}} in namespace {{.namespace}} do not get an istio-proxy sidecar. Work through these steps in order and stop as soon as the cause is found.
+
+1. Confirm the symptom: call check_mesh_workloads with namespace={{.namespace}} and label_selector=app={{.workload}}.
+   - If every pod reports sidecar_injected=true the sidecar is present; the problem is elsewhere (check sidecar_ready and the proxy logs instead).
+
+2. Check how the namespace selects a revision: call get_namespace_details with namespace={{.namespace}}.
+   - istio-injection=enabled selects the default revision; istio.io/rev=<name> selects a revision or tag.
+   - Having both labels is a misconfiguration: istio-injection wins and istio.io/rev is ignored.
+   - No label at all means injection was never enabled for the namespace.{{if .revision}}
+   - The namespace is expected to use {{.revision}}; flag any other value.{{end}}
+
+3. Check the injector itself: call check_injection_webhooks{{if .revision}} with revision={{.revision}}{{end}}.
+   - The revision or tag from step 2 must map to an IstioRevision; an unmapped tag means the IstioRevisionTag is missing or points elsewhere.
+   - ready_endpoints=0 means istiod is down, so injection silently fails for pods created meanwhile; continue with the istiod-crashlooping playbook.
+   - Overlapping selectors are reported as double injection risks; an expired caBundle makes the API server reject the webhook call.
+
+4. Check the pods themselves: call list_pods with namespace={{.namespace}} and label_selector=app={{.workload}}.
+   - A pod label sidecar.istio.io/inject=false opts the pod out regardless of the namespace label.
+   - A pod label istio.io/rev=<name> only selects a revision when the namespace has neither istio-injection nor istio.io/rev.
+   - Pods created before the namespace was labelled keep running without a sidecar; they must be restarted.
+
+5. Look for webhook errors: call list_events with namespace={{.namespace}} and type=Warning.
+   - FailedCreate events mentioning the sidecar injector webhook mean the API server could not reach istiod.
+
+Report the cause, the evidence from the tool results, and the fix. If the fix is a restart, name the Deployments to restart; restart_workloads_for_revision can do it when writes are enabled.`),
+
+	newPlaybook(&mcp.Prompt{
+		Name:        "istiod-crashlooping",
+		Title:       "istiod crashlooping",
+		Description: "Find out why istiod keeps restarting or never becomes ready",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "namespace", Description: "Control plane namespace, defaults to istio-system"},
+			{Name: "revision", Description: "IstioRevision whose istiod is failing; all revisions when empty"},
+		},
+	}, map[string]string{"namespace": "istio-system"}, `istiod in namespace {{.namespace}}{{if .revision}} (revision {{.revision}}){{end}} is crashlooping or not ready. Work through these steps in order.
+
+1. Find the pods: call list_pods with namespace={{.namespace}} and label_selector=app=istiod{{if .revision}},istio.io/rev={{.revision}}{{end}}.
+   - Note the restart count and status of each pod. CrashLoopBackOff with a growing restart count is a crash; Running but not ready is a failing readiness probe.
+
+2. Read why the last container died: call get_pod_logs with namespace={{.namespace}}, the pod_name from step 1, container=discovery and previous=true.
+   - Look for the last error lines before the exit: invalid mesh config, failed CA or certificate loading, missing CRDs, or "killed" with no error (usually OOM).
+   - If there is no previous container, read the current logs with previous=false.
+
+3. Check the Kubernetes side: call list_events with namespace={{.namespace}}, involved_kind=Pod and type=Warning.
+   - OOMKilled, FailedScheduling, failed probes and image pull errors show up here and not in the logs.
+
+4. Check what the operator reports: call get_istio_status, then check_sailoperator_health.
+   - A revision stuck Reconciling or with Ready=False usually carries the same error in its condition message.
+   - A Stale resource means the operator has not processed the latest spec change.
+
+5. If the crash followed a configuration change, call get_istio_values{{if .revision}} with to_revision={{.revision}}{{end}} and look at the diff for the values that changed (for example pilot.env, meshConfig or pilot.resources).
+
+Report the root cause with the log lines or events that prove it, and the smallest change that fixes it.`),
+
+	newPlaybook(&mcp.Prompt{
+		Name:        "canary-upgrade-walkthrough",
+		Title:       "Canary upgrade walkthrough",
+		Description: "Move the control plane to a new Istio version with a revision-based canary and revision tags",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "version", Description: "Istio version to upgrade to, e.g. v1.24.3", Required: true},
+			{Name: "istio", Description: "Name of the Istio resource, defaults to default"},
+			{Name: "tag", Description: "Revision tag the workload namespaces use, defaults to default"},
+			{Name: "namespace", Description: "Workload namespace to move to the new revision first"},
+		},
+	}, map[string]string{"istio": "default", "tag": "default"}, `Guide a canary upgrade of Istio {{.istio}} to {{.version}}. Do not move on to the next step until the current one is verified, and ask before every change.
+
+1. Check the starting point: call get_istio_status with name={{.istio}} and check_sailoperator_health.
+   - Everything must be Healthy before upgrading. Note the active revision and the current version.
+   - call check_injection_webhooks and note which revision the tag {{.tag}} maps to.
+
+2. Make sure the upgrade is revision-based: if the update strategy is InPlace, call set_update_strategy with name={{.istio}} and type=RevisionBased. With InPlace, the new version replaces istiod directly and there is no canary.
+
+3. Start the new revision: call patch_istio_version with name={{.istio}} and version={{.version}}.
+   - The operator creates a new IstioRevision next to the old one. Call get_istio_status until the new revision is Ready; both revisions run side by side.
+   - call get_istio_values with name={{.istio}} and compare the old and new revisions with from_revision and to_revision to review the rendered changes.
+
+4. Move a first namespace: label {{if .namespace}}namespace {{.namespace}}{{else}}a low-risk namespace{{end}} with istio.io/rev=<new revision> instead of the tag, then call restart_workloads_for_revision with revision=<new revision>{{if .namespace}} and namespace={{.namespace}}{{end}}.
+   - call check_mesh_workloads for that namespace: every pod must be injected and sidecar_ready.
+   - Watch the application's own health before continuing.
+
+5. Move everyone: call switch_revision_tag with tag={{.tag}}, target_kind=IstioRevision and target_name=<new revision>, then restart_workloads_for_revision for the new revision so the remaining namespaces pick up the new proxy.
+
+6. Finish: call check_mesh_workloads across all namespaces and look for pods still running the old proxy. When none are left, the old revision becomes unused and is removed after its grace period.
+
+Rollback at any point: switch the tag back to the old revision and restart the workloads again.`),
+
+	newPlaybook(&mcp.Prompt{
+		Name:        "503-between-services",
+		Title:       "503 between services",
+		Description: "Find out why requests from one workload to a service fail with HTTP 503",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "namespace", Description: "Namespace of the calling workload", Required: true},
+			{Name: "source", Description: "Calling workload, matched against the app label of its pods", Required: true},
+			{Name: "destination", Description: "Name of the destination service", Required: true},
+			{Name: "destination_namespace", Description: "Namespace of the destination service, defaults to the source namespace"},
+		},
+	}, nil, `Requests from {{.source}} in namespace {{.namespace}} to service {{.destination}}{{if .destination_namespace}} in namespace {{.destination_namespace}}{{end}} fail with HTTP 503. Work through these steps in order.
+
+1. Check the destination has healthy backends: call list_services with namespace={{if .destination_namespace}}{{.destination_namespace}}{{else}}{{.namespace}}{{end}}, find {{.destination}} and note its selector and ports, then call list_pods in the same namespace with that selector as label_selector.
+   - No ready pods behind the service means the proxy has nowhere to send traffic (response flag UH).
+   - Port names or appProtocol matter: a port Istio treats as TCP while the client speaks HTTP, or the reverse, breaks routing.
+
+2. Check both sides are in the mesh: call check_mesh_workloads for the source namespace with label_selector=app={{.source}}, and for the destination namespace.
+   - A source with a sidecar calling a destination without one fails when strict mTLS is enforced; the reverse fails when the destination requires mTLS.
+
+3. Read the source proxy's view: call list_pods with namespace={{.namespace}} and label_selector=app={{.source}}, then get_pod_logs with container=istio-proxy for one of the pods.
+   - Each access log line carries a response flag after the status code: UF (upstream connection failure), UH (no healthy upstream), NR (no route), URX (retries exhausted), UC (upstream connection closed), UT (upstream timeout).
+   - NR points at routing configuration (VirtualService, Sidecar egress hosts); UF and UC with mTLS errors point at a TLS mode mismatch.
+
+4. Read the destination proxy's view: call get_pod_logs with container=istio-proxy for a destination pod and look for the same requests.
+   - If they never arrive, the failure is on the source side or in between; if they arrive with a 503, the application or its proxy returned it.
+
+5. Check the control plane is healthy and the proxies are up to date: call check_sailoperator_health and check_mesh_workloads. Stale or unready istiod leaves proxies with old endpoints.
+
+Report the failing hop, the response flag or log line that proves it, and the configuration change that fixes it.`),
+
+	newPlaybook(&mcp.Prompt{
+		Name:        "ambient-pod-not-captured",
+		Title:       "Ambient pod not captured",
+		Description: "Find out why a pod in an ambient namespace is not captured by ztunnel",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "namespace", Description: "Namespace of the workload", Required: true},
+			{Name: "workload", Description: "Workload name, matched against the app label of its pods", Required: true},
+		},
+	}, nil, `The pods of workload {{.workload}} in namespace {{.namespace}} should be in the ambient mesh but their traffic is not captured by ztunnel. Work through these steps in order.
+
+1. Check the namespace opts in: call get_namespace_details with namespace={{.namespace}}.
+   - It needs istio.io/dataplane-mode=ambient. An istio-injection or istio.io/rev label as well makes new pods get sidecars instead.
+
+2. Check the pods: call list_pods with namespace={{.namespace}} and label_selector=app={{.workload}}.
+   - A pod label istio.io/dataplane-mode=none opts the pod out. Pods with an istio-proxy container are sidecar pods and are skipped by ztunnel.
+   - Note the node_name of each pod for the next step.
+
+3. Check the node agents cover those nodes: call get_istiocni_status and get_ztunnel_status.
+   - Both must have a ready pod on every node from step 2. A node missing either is explained by taints, tolerations or node selection in the result.
+   - Pods started before istio-cni was ready on their node are not captured until they are restarted.
+
+4. Check the CNI plugin is wired in: the get_istiocni_status result shows the chaining configuration. On clusters whose primary CNI rewrites its configuration, istio-cni must be chained into the file the primary CNI actually uses.
+
+5. Read the agents' logs for the pod: call list_pods with namespace=istio-system (or the IstioCNI and ZTunnel namespaces) to find the istio-cni-node and ztunnel pods on the pod's node, then get_pod_logs for each and search for the pod name.
+   - istio-cni logs show whether the pod was added to the mesh; ztunnel logs show whether it received a workload certificate and proxies its connections.
+
+6. Check the control plane: call get_istio_status and check that the Istio uses the ambient profile, then check_sailoperator_health.
+
+Report why the pod is not captured, the evidence from the tool results, and the fix (often a label change or a pod restart).`),
+}
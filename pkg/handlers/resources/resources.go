@@ -0,0 +1,292 @@
+// Package resources serves Sail Operator and Kubernetes objects as MCP resources
+package resources
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+)
+
+const (
+	// SailScheme is the URI scheme of Sail Operator resources, e.g. sail://istio/default
+	SailScheme = "sail"
+	// KubernetesScheme is the URI scheme of Kubernetes resources, e.g. k8s://istio-system/pods/istiod-abc
+	KubernetesScheme = "k8s"
+
+	mimeYAML = "application/yaml"
+	mimeJSON = "application/json"
+	mimeText = "text/plain"
+
+	// defaultLogTailLines is how many log lines a logs resource returns without ?tail
+	defaultLogTailLines = 200
+	// maxListedPods caps the istiod pods listed per control plane namespace
+	maxListedPods = 20
+)
+
+// SailURI returns the resource URI of a Sail Operator object
+func SailURI(kind sail.ResourceKind, name string) string {
+	return fmt.Sprintf("%s://%s/%s", SailScheme, strings.ToLower(kind.Kind), name)
+}
+
+// PodURI returns the resource URI of a pod
+func PodURI(namespace, name string) string {
+	return fmt.Sprintf("%s://%s/pods/%s", KubernetesScheme, namespace, name)
+}
+
+// PodLogsURI returns the resource URI of a pod's logs
+func PodLogsURI(namespace, name string) string {
+	return PodURI(namespace, name) + "/logs"
+}
+
+// SailTemplates returns one resource template per Sail Operator kind
+func SailTemplates() []*mcp.ResourceTemplate {
+	var templates []*mcp.ResourceTemplate
+	for _, k := range sail.Kinds {
+		templates = append(templates, &mcp.ResourceTemplate{
+			Name:        strings.ToLower(k.Kind),
+			Title:       fmt.Sprintf("Sail %s", k.Kind),
+			URITemplate: fmt.Sprintf("%s://%s/{name}{?format}", SailScheme, strings.ToLower(k.Kind)),
+			MIMEType:    mimeYAML,
+			Description: fmt.Sprintf("Snapshot of a %s resource as YAML, or as JSON with ?format=json", k.Kind),
+		})
+	}
+	return templates
+}
+
+// PodTemplate is the resource template of pod snapshots
+var PodTemplate = &mcp.ResourceTemplate{
+	Name:        "pod",
+	Title:       "Kubernetes pod",
+	URITemplate: KubernetesScheme + "://{namespace}/pods/{name}{?format}",
+	MIMEType:    mimeYAML,
+	Description: "Snapshot of a pod as YAML, or as JSON with ?format=json",
+}
+
+// PodLogsTemplate is the resource template of pod logs
+var PodLogsTemplate = &mcp.ResourceTemplate{
+	Name:        "pod-logs",
+	Title:       "Kubernetes pod logs",
+	URITemplate: KubernetesScheme + "://{namespace}/pods/{name}/logs{?container,tail,previous}",
+	MIMEType:    mimeText,
+	Description: fmt.Sprintf("Recent logs of a pod container; tail defaults to %d lines, previous=true reads the last terminated container", defaultLogTailLines),
+}
+
+// ReadSailResource serves snapshots of Sail Operator resources
+func ReadSailResource(dynamicClient dynamic.Interface, registry *sail.Registry) mcp.ResourceHandler {
+	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+		u, err := url.Parse(params.URI)
+		if err != nil || u.Scheme != SailScheme {
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+		kind, ok := sail.LookupKind(u.Host)
+		name := strings.TrimPrefix(u.Path, "/")
+		if !ok || name == "" || strings.Contains(name, "/") {
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+
+		gvr, err := registry.GVR(ctx, kind)
+		if err != nil {
+			if sail.IsNotInstalled(err) {
+				return nil, mcp.ResourceNotFoundError(params.URI)
+			}
+			return nil, err
+		}
+		obj, err := dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil, mcp.ResourceNotFoundError(params.URI)
+			}
+			return nil, fmt.Errorf("failed to get %s %s: %w", kind.Kind, name, err)
+		}
+
+		return snapshot(params.URI, obj.Object, u.Query().Get("format"))
+	}
+}
+
+// ReadPod serves pod snapshots and pod logs
+func ReadPod(k8sClient *kubernetes.Clientset) mcp.ResourceHandler {
+	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+		u, err := url.Parse(params.URI)
+		if err != nil || u.Scheme != KubernetesScheme {
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+		namespace := u.Host
+		parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+		if namespace == "" || len(parts) < 2 || parts[0] != "pods" || parts[1] == "" {
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+		name := parts[1]
+
+		switch {
+		case len(parts) == 2:
+			pod, err := k8sClient.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if errors.IsNotFound(err) {
+					return nil, mcp.ResourceNotFoundError(params.URI)
+				}
+				return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, name, err)
+			}
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert pod %s/%s: %w", namespace, name, err)
+			}
+			// Typed objects come back from the API without their type metadata
+			obj["apiVersion"] = "v1"
+			obj["kind"] = "Pod"
+			return snapshot(params.URI, obj, u.Query().Get("format"))
+		case len(parts) == 3 && parts[2] == "logs":
+			return readPodLogs(ctx, k8sClient, params.URI, namespace, name, u.Query())
+		default:
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+	}
+}
+
+// readPodLogs returns the tail of a pod container's logs as plain text
+func readPodLogs(ctx context.Context, k8sClient *kubernetes.Clientset, uri, namespace, name string, query url.Values) (*mcp.ReadResourceResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tail := int64(defaultLogTailLines)
+	if v := query.Get("tail"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("tail must be a positive number of lines, got '%s'", v)
+		}
+		tail = n
+	}
+	logOptions := &corev1.PodLogOptions{
+		Container: query.Get("container"),
+		TailLines: &tail,
+		Previous:  query.Get("previous") == "true",
+	}
+
+	stream, err := k8sClient.CoreV1().Pods(namespace).GetLogs(name, logOptions).Stream(ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		return nil, fmt.Errorf("failed to get logs for pod %s/%s: %w", namespace, name, err)
+	}
+	defer stream.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read logs for pod %s/%s: %w", namespace, name, err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: mimeText, Text: strings.Join(lines, "\n")}},
+	}, nil
+}
+
+// snapshot encodes an object as YAML or JSON, without its managed fields
+func snapshot(uri string, obj map[string]interface{}, format string) (*mcp.ReadResourceResult, error) {
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+	}
+
+	var data []byte
+	var mimeType string
+	var err error
+	switch format {
+	case "", "yaml":
+		data, err = yaml.Marshal(obj)
+		mimeType = mimeYAML
+	case "json":
+		data, err = json.MarshalIndent(obj, "", "  ")
+		mimeType = mimeJSON
+	default:
+		return nil, fmt.Errorf("unsupported format '%s', use yaml or json", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: mimeType, Text: string(data)}},
+	}, nil
+}
+
+// ListMeshResources returns concrete resources for every Sail Operator object
+// and for the istiod pods in the namespaces those objects install into; other
+// pods are reached through the k8s:// templates. Kinds that are not installed
+// and namespaces that cannot be listed are skipped.
+func ListMeshResources(ctx context.Context, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry) []*mcp.Resource {
+	var resources []*mcp.Resource
+	namespaces := map[string]bool{}
+
+	for _, k := range sail.Kinds {
+		gvr, err := registry.GVR(ctx, k)
+		if err != nil {
+			continue
+		}
+		list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			continue
+		}
+		for _, item := range list.Items {
+			resources = append(resources, &mcp.Resource{
+				URI:         SailURI(k, item.GetName()),
+				Name:        fmt.Sprintf("%s %s", k.Kind, item.GetName()),
+				MIMEType:    mimeYAML,
+				Description: fmt.Sprintf("Sail Operator %s resource", k.Kind),
+			})
+			if obj, err := sail.Decode[sail.Object](&item); err == nil && obj.Spec.Namespace != "" {
+				namespaces[obj.Spec.Namespace] = true
+			}
+		}
+	}
+
+	var sorted []string
+	for ns := range namespaces {
+		sorted = append(sorted, ns)
+	}
+	sort.Strings(sorted)
+
+	for _, ns := range sorted {
+		pods, err := k8sClient.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: "app=istiod", Limit: maxListedPods})
+		if err != nil {
+			continue
+		}
+		for _, pod := range pods.Items {
+			resources = append(resources,
+				&mcp.Resource{
+					URI:         PodURI(ns, pod.Name),
+					Name:        fmt.Sprintf("Pod %s/%s", ns, pod.Name),
+					MIMEType:    mimeYAML,
+					Description: "Kubernetes pod",
+				},
+				&mcp.Resource{
+					URI:         PodLogsURI(ns, pod.Name),
+					Name:        fmt.Sprintf("Logs %s/%s", ns, pod.Name),
+					MIMEType:    mimeText,
+					Description: fmt.Sprintf("Last %d log lines of the pod", defaultLogTailLines),
+				})
+		}
+	}
+
+	return resources
+}
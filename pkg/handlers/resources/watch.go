@@ -0,0 +1,409 @@
+package resources
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+)
+
+// Notifier delivers resources/updated notifications to the client
+type Notifier interface {
+	NotifyResourceUpdated(uri string) error
+}
+
+// Watcher runs the informers behind resource subscriptions and notifies the
+// client when a watched object changes in a way worth reporting: a Sail
+// resource's generation, state or conditions, or a pod's phase, readiness or
+// restarts. Restarts and replacements of istiod pods are also reported on
+// their IstioRevision and Istio while a Sail resource is subscribed.
+// Informers are only started once the client subscribes to something, and
+// each is stopped when the last subscription that needs it goes away.
+type Watcher struct {
+	k8sClient     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+	registry      *sail.Registry
+	notifier      Notifier
+
+	// ctx bounds the informers; each factory also has its own cancel
+	ctx context.Context
+
+	mu       sync.Mutex
+	watched  map[string]bool // subscribed URIs
+	sailSubs int             // sail:// subscriptions, which follow their control plane's pods
+
+	// The Sail informers locate the istiod namespaces and map istiod pods
+	// back to their revision, so every subscription needs them
+	sailFactory   dynamicinformer.DynamicSharedInformerFactory
+	sailCtx       context.Context
+	sailCancel    context.CancelFunc
+	sailInformers map[string]cache.SharedIndexInformer // by kind
+	controlPlanes map[string]string                    // istiod namespace by IstioRevision
+
+	podWatches map[string]*podWatch // by namespace
+}
+
+// podWatch is the pod informer of one namespace and how many subscriptions
+// use it; cancel is nil while the informer could not be started
+type podWatch struct {
+	refs   int
+	cancel context.CancelFunc
+}
+
+// NewWatcher creates a watcher; call Watch for each subscribed URI and
+// Unwatch when the subscription ends
+func NewWatcher(ctx context.Context, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry, notifier Notifier) *Watcher {
+	return &Watcher{
+		k8sClient:     k8sClient,
+		dynamicClient: dynamicClient,
+		registry:      registry,
+		notifier:      notifier,
+		ctx:           ctx,
+		watched:       make(map[string]bool),
+		sailInformers: make(map[string]cache.SharedIndexInformer),
+		controlPlanes: make(map[string]string),
+		podWatches:    make(map[string]*podWatch),
+	}
+}
+
+// Watch starts the informers needed to follow uri. Unknown URIs are ignored.
+func (w *Watcher) Watch(uri string) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watched[uri] {
+		return
+	}
+	switch {
+	case u.Scheme == KubernetesScheme && u.Host != "":
+		w.acquirePods(u.Host)
+	case u.Scheme == SailScheme:
+		w.sailSubs++
+		if w.sailSubs == 1 {
+			for _, ns := range w.controlPlanes {
+				w.acquirePods(ns)
+			}
+		}
+	default:
+		return
+	}
+	w.watched[uri] = true
+	w.ensureSailInformers()
+}
+
+// Unwatch releases the informers uri needed, stopping those no other
+// subscription uses
+func (w *Watcher) Unwatch(uri string) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.watched[uri] {
+		return
+	}
+	delete(w.watched, uri)
+	switch u.Scheme {
+	case KubernetesScheme:
+		w.releasePods(u.Host)
+	case SailScheme:
+		w.sailSubs--
+		if w.sailSubs == 0 {
+			for _, ns := range w.controlPlanes {
+				w.releasePods(ns)
+			}
+		}
+	}
+	if len(w.watched) == 0 && w.sailFactory != nil {
+		w.sailCancel()
+		w.sailFactory = nil
+		w.sailInformers = make(map[string]cache.SharedIndexInformer)
+		w.controlPlanes = make(map[string]string)
+	}
+}
+
+// ensureSailInformers starts an informer for every installed Sail kind that
+// is not watched yet. The caller holds mu.
+func (w *Watcher) ensureSailInformers() {
+	if w.sailFactory == nil {
+		w.sailFactory = dynamicinformer.NewDynamicSharedInformerFactory(w.dynamicClient, 10*time.Minute)
+		w.sailCtx, w.sailCancel = context.WithCancel(w.ctx)
+	}
+	ctx := w.sailCtx
+
+	started := false
+	for _, k := range sail.Kinds {
+		if _, ok := w.sailInformers[k.Kind]; ok {
+			continue
+		}
+		gvr, err := w.registry.GVR(w.ctx, k)
+		if err != nil {
+			continue
+		}
+		informer := w.sailFactory.ForResource(gvr).Informer()
+		if _, err := informer.AddEventHandler(w.sailHandler(ctx, k)); err != nil {
+			log.Printf("Failed to watch %s resources: %v", k.Kind, err)
+			continue
+		}
+		w.sailInformers[k.Kind] = informer
+		started = true
+	}
+	if started {
+		w.sailFactory.Start(ctx.Done())
+	}
+}
+
+// acquirePods counts one more user of a namespace's pod informer and starts
+// it if it is not running. The caller holds mu.
+func (w *Watcher) acquirePods(namespace string) {
+	pw := w.podWatches[namespace]
+	if pw == nil {
+		pw = &podWatch{}
+		w.podWatches[namespace] = pw
+	}
+	pw.refs++
+	if pw.cancel != nil {
+		return
+	}
+
+	// On failure the entry stays counted without an informer, so the
+	// subscriptions stay balanced and the next one tries again
+	factory := informers.NewSharedInformerFactoryWithOptions(w.k8sClient, 10*time.Minute, informers.WithNamespace(namespace))
+	informer := factory.Core().V1().Pods().Informer()
+	if _, err := informer.AddEventHandler(w.podHandler()); err != nil {
+		log.Printf("Failed to watch pods in namespace %s: %v", namespace, err)
+		return
+	}
+	ctx, cancel := context.WithCancel(w.ctx)
+	pw.cancel = cancel
+	factory.Start(ctx.Done())
+}
+
+// releasePods drops one user of a namespace's pod informer and stops it
+// after the last. The caller holds mu.
+func (w *Watcher) releasePods(namespace string) {
+	pw := w.podWatches[namespace]
+	if pw == nil {
+		return
+	}
+	pw.refs--
+	if pw.refs == 0 {
+		if pw.cancel != nil {
+			pw.cancel()
+		}
+		delete(w.podWatches, namespace)
+	}
+}
+
+// sailHandler notifies subscribers of a Sail resource when it changes. ctx is
+// the informers' context; events still queued after it ends are dropped.
+func (w *Watcher) sailHandler(ctx context.Context, kind sail.ResourceKind) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			w.followControlPlane(ctx, kind, u.GetName(), u)
+			if !isInInitialList {
+				w.notify(SailURI(kind, u.GetName()))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldU, ok1 := oldObj.(*unstructured.Unstructured)
+			newU, ok2 := newObj.(*unstructured.Unstructured)
+			if !ok1 || !ok2 {
+				return
+			}
+			w.followControlPlane(ctx, kind, newU.GetName(), newU)
+			if sailStateChanged(oldU, newU) {
+				w.notify(SailURI(kind, newU.GetName()))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if u, ok := deletedObject(obj).(*unstructured.Unstructured); ok {
+				w.followControlPlane(ctx, kind, u.GetName(), nil)
+				w.notify(SailURI(kind, u.GetName()))
+			}
+		},
+	}
+}
+
+// followControlPlane keeps track of the namespace an IstioRevision installs
+// istiod into, nil once it is deleted, and watches its pods while a Sail
+// resource is subscribed
+func (w *Watcher) followControlPlane(ctx context.Context, kind sail.ResourceKind, name string, u *unstructured.Unstructured) {
+	if kind != sail.KindIstioRevision {
+		return
+	}
+	var ns string
+	if u != nil {
+		ns, _, _ = unstructured.NestedString(u.Object, "spec", "namespace")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev := w.controlPlanes[name]
+	if ctx.Err() != nil || prev == ns {
+		return
+	}
+	if w.sailSubs > 0 {
+		if prev != "" {
+			w.releasePods(prev)
+		}
+		if ns != "" {
+			w.acquirePods(ns)
+		}
+	}
+	if ns == "" {
+		delete(w.controlPlanes, name)
+	} else {
+		w.controlPlanes[name] = ns
+	}
+}
+
+// podHandler notifies subscribers of a pod, and of its revision for istiod pods
+func (w *Watcher) podHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			pod, ok := obj.(*corev1.Pod)
+			if !ok || isInInitialList {
+				return
+			}
+			w.notify(PodURI(pod.Namespace, pod.Name))
+			w.notifyControlPlane(pod)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok1 := oldObj.(*corev1.Pod)
+			newPod, ok2 := newObj.(*corev1.Pod)
+			if !ok1 || !ok2 || !podStateChanged(oldPod, newPod) {
+				return
+			}
+			w.notify(PodURI(newPod.Namespace, newPod.Name))
+			if podRestarts(newPod) != podRestarts(oldPod) {
+				w.notifyControlPlane(newPod)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			pod, ok := deletedObject(obj).(*corev1.Pod)
+			if !ok {
+				return
+			}
+			w.notify(PodURI(pod.Namespace, pod.Name))
+			w.notifyControlPlane(pod)
+		},
+	}
+}
+
+// notifyControlPlane notifies the IstioRevision and Istio of an istiod pod
+func (w *Watcher) notifyControlPlane(pod *corev1.Pod) {
+	if pod.Labels["app"] != "istiod" {
+		return
+	}
+	revision := pod.Labels["istio.io/rev"]
+	if revision == "" {
+		revision = "default"
+	}
+	w.notify(SailURI(sail.KindIstioRevision, revision))
+
+	w.mu.Lock()
+	informer := w.sailInformers[sail.KindIstioRevision.Kind]
+	w.mu.Unlock()
+	if informer == nil {
+		return
+	}
+	obj, exists, err := informer.GetStore().GetByKey(revision)
+	if err != nil || !exists {
+		return
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		for _, owner := range u.GetOwnerReferences() {
+			if owner.Kind == sail.KindIstio.Kind {
+				w.notify(SailURI(sail.KindIstio, owner.Name))
+			}
+		}
+	}
+}
+
+func (w *Watcher) notify(uri string) {
+	if err := w.notifier.NotifyResourceUpdated(uri); err != nil {
+		log.Printf("Failed to notify resource update for %s: %v", uri, err)
+	}
+}
+
+// sailStateChanged reports changes to a Sail resource's generation, state or
+// conditions. Condition timestamps alone do not count.
+func sailStateChanged(oldU, newU *unstructured.Unstructured) bool {
+	if oldU.GetGeneration() != newU.GetGeneration() {
+		return true
+	}
+	oldObj, err1 := sail.Decode[sail.Object](oldU)
+	newObj, err2 := sail.Decode[sail.Object](newU)
+	if err1 != nil || err2 != nil {
+		return oldU.GetResourceVersion() != newU.GetResourceVersion()
+	}
+	if oldObj.Status.State != newObj.Status.State || oldObj.Status.ObservedGeneration != newObj.Status.ObservedGeneration {
+		return true
+	}
+	return !reflect.DeepEqual(conditionSummary(oldObj.Status.Conditions), conditionSummary(newObj.Status.Conditions))
+}
+
+func conditionSummary(conditions []sail.Condition) []string {
+	var summary []string
+	for _, c := range conditions {
+		summary = append(summary, strings.Join([]string{c.Type, c.Status, c.Reason, c.Message}, "|"))
+	}
+	return summary
+}
+
+// podStateChanged reports changes to a pod's phase, readiness or container restarts
+func podStateChanged(oldPod, newPod *corev1.Pod) bool {
+	return oldPod.Status.Phase != newPod.Status.Phase ||
+		podReady(oldPod) != podReady(newPod) ||
+		podRestarts(oldPod) != podRestarts(newPod) ||
+		oldPod.DeletionTimestamp.IsZero() != newPod.DeletionTimestamp.IsZero()
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func podRestarts(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarts += cs.RestartCount
+	}
+	return restarts
+}
+
+// deletedObject unwraps the tombstone the informer hands out when it missed a delete
+func deletedObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
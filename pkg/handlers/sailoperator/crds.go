@@ -0,0 +1,118 @@
+package sailoperator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// ListSailCRDs reports which Sail Operator CRDs are installed, their served and
+// preferred API versions and whether they are established
+func ListSailCRDs(registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListSailCRDsParams]) (*mcp.CallToolResultFor[types.ListSailCRDsResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListSailCRDsParams]) (*mcp.CallToolResultFor[types.ListSailCRDsResult], error) {
+		if params.Arguments.Refresh {
+			if err := registry.Refresh(ctx); err != nil {
+				return &mcp.CallToolResultFor[types.ListSailCRDsResult]{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Error discovering Sail Operator CRDs: %v", err),
+					}},
+				}, nil
+			}
+		}
+
+		crds, discoveredAt, err := registry.CRDs(ctx)
+		if err != nil {
+			return &mcp.CallToolResultFor[types.ListSailCRDsResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error discovering Sail Operator CRDs: %v", err),
+				}},
+			}, nil
+		}
+
+		output := "=== Sail Operator CRDs ===\n\n"
+		output += fmt.Sprintf("%-18s %-10s %-12s %-10s %-14s %s\n",
+			"KIND", "INSTALLED", "ESTABLISHED", "PREFERRED", "SERVED", "STORAGE")
+		output += strings.Repeat("-", 80) + "\n"
+
+		for _, crd := range crds {
+			installed := "No"
+			if crd.Installed {
+				installed = "Yes"
+			}
+			established := "Unknown"
+			if crd.Established != nil {
+				established = "No"
+				if *crd.Established {
+					established = "Yes"
+				}
+			} else if !crd.Installed {
+				established = "-"
+			}
+			preferred := crd.PreferredVersion
+			if preferred == "" {
+				preferred = "-"
+			}
+			served := strings.Join(crd.ServedVersions, ",")
+			if served == "" {
+				served = "-"
+			}
+			storage := crd.StorageVersion
+			if storage == "" {
+				storage = "-"
+			}
+
+			output += fmt.Sprintf("%-18s %-10s %-12s %-10s %-14s %s\n",
+				crd.Kind, installed, established, preferred, served, storage)
+		}
+
+		discoveryErrors := registry.DiscoveryErrors()
+		var problems []string
+		var groupVersions []string
+		for gv := range discoveryErrors {
+			groupVersions = append(groupVersions, gv)
+		}
+		sort.Strings(groupVersions)
+		for _, gv := range groupVersions {
+			problems = append(problems, fmt.Sprintf("%s: discovery failed: %s", gv, discoveryErrors[gv]))
+		}
+		for _, crd := range crds {
+			if crd.Error != "" {
+				problems = append(problems, fmt.Sprintf("%s: %s", crd.Kind, crd.Error))
+			}
+			if crd.Installed && crd.Established != nil && !*crd.Established {
+				problems = append(problems, fmt.Sprintf("%s: CRD %s is not established", crd.Kind, crd.CRDName))
+			}
+			if crd.Installed && crd.PreferredVersion == "" {
+				problems = append(problems, fmt.Sprintf("%s: CRD exists but no version is served", crd.Kind))
+			}
+		}
+		if len(problems) > 0 {
+			output += "\nIssues:\n"
+			for _, p := range problems {
+				output += fmt.Sprintf("  • %s\n", p)
+			}
+		}
+
+		output += fmt.Sprintf("\nDiscovered at: %s", discoveredAt.Format(time.RFC3339))
+
+		result := types.ListSailCRDsResult{
+			Status:          "success",
+			CRDs:            crds,
+			DiscoveryErrors: discoveryErrors,
+			DiscoveredAt:    discoveredAt.Format(time.RFC3339),
+		}
+		return &mcp.CallToolResultFor[types.ListSailCRDsResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
@@ -4,61 +4,121 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
+	"github.com/frherrer/mcp-sail-operator/pkg/health"
+	"github.com/frherrer/mcp-sail-operator/pkg/progress"
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
 	"github.com/frherrer/mcp-sail-operator/pkg/types"
 )
 
-// CheckSailOperatorHealth performs comprehensive health checks on Sail Operator managed resources
-func CheckSailOperatorHealth(dynamicClient dynamic.Interface) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckSailOperatorHealthParams]) (*mcp.CallToolResultFor[types.CheckSailOperatorHealthResult], error) {
+// defaultStaleAfter is how long a resource may stay unreconciled, or keep a
+// critical condition in a non-True state, before it is reported as Stale
+const defaultStaleAfter = 10 * time.Minute
+
+const (
+	// defaultHealthConcurrency is how many component types are checked at once
+	defaultHealthConcurrency = 4
+	// defaultComponentTimeout bounds the API calls made for a single component type
+	defaultComponentTimeout = 10 * time.Second
+)
+
+// CheckSailOperatorHealth performs comprehensive health checks on Sail Operator managed resources.
+// Resources are evaluated against the given health rules; nil uses the built-in defaults.
+func CheckSailOperatorHealth(dynamicClient dynamic.Interface, registry *sail.Registry, rules *health.RuleSet) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckSailOperatorHealthParams]) (*mcp.CallToolResultFor[types.CheckSailOperatorHealthResult], error) {
+	if rules == nil {
+		rules = health.DefaultRuleSet()
+	}
+
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckSailOperatorHealthParams]) (*mcp.CallToolResultFor[types.CheckSailOperatorHealthResult], error) {
 		var components []types.HealthCheckResult
 		var overallHealth = "Healthy"
 		var healthyCount, totalCount int
 
-		// Define components to check
-		componentChecks := map[string]schema.GroupVersionResource{
-			"Istio": {
-				Group:    "sailoperator.io",
-				Version:  "v1",
-				Resource: "istios",
-			},
-			"IstioRevision": {
-				Group:    "sailoperator.io",
-				Version:  "v1",
-				Resource: "istiorevisions",
-			},
-			"IstioCNI": {
-				Group:    "sailoperator.io",
-				Version:  "v1",
-				Resource: "istiocnis",
-			},
-			"ZTunnel": {
-				Group:    "sailoperator.io",
-				Version:  "v1alpha1",
-				Resource: "ztunnels",
-			},
+		// Define components to check, in the order they are reported
+		componentChecks := []sail.ResourceKind{
+			sail.KindIstio,
+			sail.KindIstioRevision,
+			sail.KindIstioCNI,
+			sail.KindZTunnel,
 		}
 
-		// Check each component type
-		for componentName, gvr := range componentChecks {
-			healthResult := checkComponentHealth(ctx, dynamicClient, componentName, gvr, params.Arguments.Namespace)
-			components = append(components, healthResult)
-			totalCount++
+		staleAfter := defaultStaleAfter
+		if params.Arguments.StaleAfterSeconds > 0 {
+			staleAfter = time.Duration(params.Arguments.StaleAfterSeconds) * time.Second
+		}
+		concurrency := defaultHealthConcurrency
+		if params.Arguments.Concurrency > 0 {
+			concurrency = params.Arguments.Concurrency
+		}
+		timeout := defaultComponentTimeout
+		if params.Arguments.TimeoutSeconds > 0 {
+			timeout = time.Duration(params.Arguments.TimeoutSeconds) * time.Second
+		}
+
+		// Check component types concurrently; results keep the order of componentChecks
+		components = make([]types.HealthCheckResult, len(componentChecks))
+		reporter := progress.New(cc, params, len(componentChecks))
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, component := range componentChecks {
+			wg.Add(1)
+			go func(i int, component sail.ResourceKind) {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					components[i] = types.HealthCheckResult{Component: component.Kind, Status: "Error", Reason: "Cancelled"}
+					return
+				}
+				defer func() { <-sem }()
+
+				componentCtx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				components[i] = checkComponentHealth(componentCtx, dynamicClient, registry, rules, component, params.Arguments.Namespace, staleAfter)
+				if components[i].Status == "Error" && componentCtx.Err() == context.DeadlineExceeded {
+					components[i].Status = "Timeout"
+					components[i].Reason = fmt.Sprintf("No response within %s", timeout)
+				}
+				reporter.Step(ctx, fmt.Sprintf("Checked %s: %s", component.Kind, components[i].Status))
+			}(i, component)
+		}
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			return &mcp.CallToolResultFor[types.CheckSailOperatorHealthResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Health check cancelled: %v", ctx.Err())}},
+			}, nil
+		}
 
-			if healthResult.Status == "Healthy" {
+		var progressingCount int
+		for _, healthResult := range components {
+			totalCount++
+			switch healthResult.Status {
+			case "Healthy":
 				healthyCount++
+			case "Reconciling", "Stale":
+				progressingCount++
 			}
 		}
 
-		// Determine overall health
-		if healthyCount == 0 {
+		// Determine overall health. Components that are only reconciling or
+		// stale are reported as such rather than folded into Degraded.
+		if healthyCount+progressingCount == totalCount && progressingCount > 0 {
+			overallHealth = "Reconciling"
+			for _, component := range components {
+				if component.Status == "Stale" {
+					overallHealth = "Stale"
+					break
+				}
+			}
+		} else if healthyCount == 0 {
 			overallHealth = "Unhealthy"
 		} else if healthyCount < totalCount {
 			overallHealth = "Degraded"
@@ -86,16 +146,30 @@ func CheckSailOperatorHealth(dynamicClient dynamic.Interface) func(ctx context.C
 }
 
 // checkComponentHealth checks the health of a specific component type
-func checkComponentHealth(ctx context.Context, dynamicClient dynamic.Interface, componentName string, gvr schema.GroupVersionResource, namespace string) types.HealthCheckResult {
+func checkComponentHealth(ctx context.Context, dynamicClient dynamic.Interface, registry *sail.Registry, rules *health.RuleSet, kind sail.ResourceKind, namespace string, staleAfter time.Duration) types.HealthCheckResult {
+	componentName := kind.Kind
 	result := types.HealthCheckResult{
 		Component: componentName,
 		Status:    "NotFound",
 		Issues:    []string{},
 	}
 
+	// Resolve the served API version
+	gvr, err := registry.GVR(ctx, kind)
+	if err != nil {
+		if sail.IsNotInstalled(err) {
+			result.Reason = "CRD not installed"
+			result.Issues = append(result.Issues, fmt.Sprintf("%s CRD (%s) is not installed or not served", componentName, kind.CRDName()))
+			return result
+		}
+		result.Status = "Error"
+		result.Reason = "Discovery failed"
+		result.Issues = append(result.Issues, fmt.Sprintf("Failed to discover %s API version: %v", componentName, err))
+		return result
+	}
+
 	// List resources
 	var resourceList *unstructured.UnstructuredList
-	var err error
 
 	if namespace != "" {
 		resourceList, err = dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
@@ -104,14 +178,9 @@ func checkComponentHealth(ctx context.Context, dynamicClient dynamic.Interface,
 	}
 
 	if err != nil {
-		if errors.IsNotFound(err) {
-			result.Reason = "CRD not installed"
-			result.Issues = append(result.Issues, fmt.Sprintf("%s CRD is not installed or not accessible", componentName))
-			return result
-		}
 		result.Status = "Error"
 		result.Reason = "Query failed"
-		result.Issues = append(result.Issues, fmt.Sprintf("Failed to query %s resources: %v", componentName, err))
+		result.Issues = append(result.Issues, fmt.Sprintf("Failed to query %s resources (%s): %v", componentName, gvr.GroupVersion(), err))
 		return result
 	}
 
@@ -123,47 +192,66 @@ func checkComponentHealth(ctx context.Context, dynamicClient dynamic.Interface,
 	}
 
 	// Analyze health of found resources
-	var healthyResources, totalResources int
+	statusCounts := make(map[string]int)
 	var resourceIssues []string
 	var allConditions []types.ResourceCondition
 
+	now := time.Now()
 	for _, item := range resourceList.Items {
-		totalResources++
-		isHealthy, issues, conditions := analyzeResourceHealth(&item)
-		
-		if isHealthy {
-			healthyResources++
-		}
-		
-		resourceIssues = append(resourceIssues, issues...)
+		resourceHealth, conditions := analyzeResourceHealth(&item, rules, now, staleAfter)
+		statusCounts[resourceHealth.Status]++
+
+		result.Resources = append(result.Resources, resourceHealth)
+		resourceIssues = append(resourceIssues, resourceHealth.Issues...)
 		allConditions = append(allConditions, conditions...)
 	}
 
 	result.Conditions = allConditions
 
-	// Determine overall component status
-	if healthyResources == totalResources {
+	// Determine overall component status. Unhealthy resources take precedence,
+	// then resources whose status can no longer be trusted (Stale), then
+	// resources the operator is still working on (Reconciling).
+	totalResources := len(resourceList.Items)
+	healthyResources := statusCounts["Healthy"]
+	switch {
+	case healthyResources == totalResources:
 		result.Status = "Healthy"
 		result.Reason = fmt.Sprintf("All %d resources healthy", totalResources)
-	} else if healthyResources > 0 {
+	case statusCounts["Unhealthy"] > 0 && healthyResources > 0:
 		result.Status = "Degraded"
 		result.Reason = fmt.Sprintf("%d/%d resources healthy", healthyResources, totalResources)
 		result.Issues = resourceIssues
-	} else {
+	case statusCounts["Unhealthy"] > 0:
 		result.Status = "Unhealthy"
 		result.Reason = fmt.Sprintf("0/%d resources healthy", totalResources)
 		result.Issues = resourceIssues
+	case statusCounts["Stale"] > 0:
+		result.Status = "Stale"
+		result.Reason = fmt.Sprintf("%d/%d resources stale", statusCounts["Stale"], totalResources)
+		result.Issues = resourceIssues
+	case statusCounts["Reconciling"] > 0:
+		result.Status = "Reconciling"
+		result.Reason = fmt.Sprintf("%d/%d resources reconciling", statusCounts["Reconciling"], totalResources)
+		result.Issues = resourceIssues
+	default:
+		result.Status = "Degraded"
+		result.Reason = fmt.Sprintf("%d/%d resources healthy", healthyResources, totalResources)
+		result.Issues = resourceIssues
 	}
 
 	return result
 }
 
-// analyzeResourceHealth analyzes the health of a single resource
-func analyzeResourceHealth(resource *unstructured.Unstructured) (bool, []string, []types.ResourceCondition) {
-	var issues []string
-	var conditions []types.ResourceCondition
-	isHealthy := true
-
+// analyzeResourceHealth analyzes the health of a single resource.
+//
+// A resource whose metadata.generation is ahead of status.observedGeneration
+// has a spec change the operator has not processed yet. It is Reconciling
+// while the change is younger than staleAfter and Stale afterwards, since its
+// status no longer describes its spec. Failed critical rules make an in-sync
+// resource Unhealthy; while reconciling they are expected and only become a
+// problem (Stale) once their condition has been stuck for staleAfter. Failed
+// warning rules mark the resource Degraded.
+func analyzeResourceHealth(resource *unstructured.Unstructured, rules *health.RuleSet, now time.Time, staleAfter time.Duration) (types.ResourceHealth, []types.ResourceCondition) {
 	resourceName := resource.GetName()
 	resourceNamespace := resource.GetNamespace()
 	resourceId := resourceName
@@ -171,67 +259,114 @@ func analyzeResourceHealth(resource *unstructured.Unstructured) (bool, []string,
 		resourceId = fmt.Sprintf("%s/%s", resourceNamespace, resourceName)
 	}
 
-	// Check status conditions
-	if conditionsRaw, found, _ := unstructured.NestedSlice(resource.Object, "status", "conditions"); found {
-		for _, condRaw := range conditionsRaw {
-			if condMap, ok := condRaw.(map[string]interface{}); ok {
-				condition := types.ResourceCondition{}
-				if t, ok := condMap["type"].(string); ok {
-					condition.Type = t
-				}
-				if s, ok := condMap["status"].(string); ok {
-					condition.Status = s
-				}
-				if r, ok := condMap["reason"].(string); ok {
-					condition.Reason = r
-				}
-				if m, ok := condMap["message"].(string); ok {
-					condition.Message = m
-				}
-				conditions = append(conditions, condition)
-
-				// Check critical conditions
-				if condition.Type == "Ready" && condition.Status != "True" {
-					isHealthy = false
-					issue := fmt.Sprintf("%s is not ready", resourceId)
-					if condition.Reason != "" {
-						issue += fmt.Sprintf(" (%s)", condition.Reason)
-					}
-					issues = append(issues, issue)
-				}
+	resourceHealth := types.ResourceHealth{
+		Name:       resourceName,
+		Namespace:  resourceNamespace,
+		Status:     "Healthy",
+		Generation: resource.GetGeneration(),
+	}
 
-				if condition.Type == "Reconciled" && condition.Status != "True" {
-					isHealthy = false
-					issue := fmt.Sprintf("%s reconciliation failed", resourceId)
-					if condition.Reason != "" {
-						issue += fmt.Sprintf(" (%s)", condition.Reason)
-					}
-					issues = append(issues, issue)
+	obj, err := sail.Decode[sail.Object](resource)
+	if err != nil {
+		resourceHealth.Status = "Unhealthy"
+		resourceHealth.Issues = append(resourceHealth.Issues, fmt.Sprintf("%s could not be decoded: %v", resourceId, err))
+		return resourceHealth, nil
+	}
+	conditions := sail.ResourceConditions(obj.Status.Conditions)
+
+	// Check whether the operator has observed the latest spec
+	reconciling := false
+	if observed := obj.Status.ObservedGeneration; observed > 0 {
+		resourceHealth.ObservedGeneration = observed
+		if observed < resourceHealth.Generation {
+			reconciling = true
+			issue := fmt.Sprintf("%s spec generation %d not yet reconciled (observed generation %d)", resourceId, resourceHealth.Generation, observed)
+			if changedAt := lastSpecChange(resource); !changedAt.IsZero() {
+				pending := now.Sub(changedAt)
+				issue += fmt.Sprintf(", spec changed %s ago", formatDuration(pending))
+				if pending > staleAfter {
+					resourceHealth.Status = worseHealth(resourceHealth.Status, "Stale")
 				}
+			}
+			resourceHealth.Status = worseHealth(resourceHealth.Status, "Reconciling")
+			resourceHealth.Issues = append(resourceHealth.Issues, issue)
+		}
+	}
 
-				if condition.Type == "DependenciesHealthy" && condition.Status != "True" {
-					isHealthy = false
-					issue := fmt.Sprintf("%s has unhealthy dependencies", resourceId)
-					if condition.Reason != "" {
-						issue += fmt.Sprintf(" (%s)", condition.Reason)
-					}
-					issues = append(issues, issue)
+	// Apply health rules
+	for _, finding := range rules.Evaluate(resource) {
+		issue := fmt.Sprintf("%s %s", resourceId, finding.Message)
+
+		stuck := false
+		if finding.ConditionType != "" {
+			if condition := sail.FindCondition(obj.Status.Conditions, finding.ConditionType); condition != nil && !condition.LastTransitionTime.IsZero() {
+				age := now.Sub(condition.LastTransitionTime.Time)
+				if age > staleAfter {
+					stuck = true
+					issue += fmt.Sprintf(" - %s has been %s for %s", condition.Type, condition.Status, formatDuration(age))
 				}
 			}
 		}
+
+		switch {
+		case finding.Severity == health.SeverityWarning:
+			resourceHealth.Status = worseHealth(resourceHealth.Status, "Degraded")
+		case !reconciling:
+			resourceHealth.Status = worseHealth(resourceHealth.Status, "Unhealthy")
+		case stuck:
+			resourceHealth.Status = worseHealth(resourceHealth.Status, "Stale")
+		}
+		resourceHealth.Issues = append(resourceHealth.Issues, issue)
 	}
 
-	// Check state field
-	if status, found, _ := unstructured.NestedMap(resource.Object, "status"); found {
-		if state, ok := status["state"].(string); ok {
-			if state != "Healthy" {
-				isHealthy = false
-				issues = append(issues, fmt.Sprintf("%s state is %s", resourceId, state))
-			}
+	return resourceHealth, conditions
+}
+
+// healthSeverity orders resource health statuses from best to worst
+var healthSeverity = map[string]int{
+	"Healthy":     0,
+	"Degraded":    1,
+	"Reconciling": 2,
+	"Stale":       3,
+	"Unhealthy":   4,
+}
+
+// worseHealth returns the more severe of two resource health statuses
+func worseHealth(current, candidate string) string {
+	if healthSeverity[candidate] > healthSeverity[current] {
+		return candidate
+	}
+	return current
+}
+
+// lastSpecChange returns the most recent time a manager wrote to the resource
+// outside of the status subresource, or the zero time if it is unknown
+func lastSpecChange(resource *unstructured.Unstructured) time.Time {
+	var latest time.Time
+	for _, entry := range resource.GetManagedFields() {
+		if entry.Subresource != "" || entry.Time == nil {
+			continue
+		}
+		if entry.Time.After(latest) {
+			latest = entry.Time.Time
 		}
 	}
+	if latest.IsZero() {
+		latest = resource.GetCreationTimestamp().Time
+	}
+	return latest
+}
 
-	return isHealthy, issues, conditions
+// formatDuration formats a duration into a short human-readable string
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	} else if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	} else if d < 24*time.Hour {
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%dh", int(d.Hours()/24), int(d.Hours())%24)
 }
 
 // generateHealthSummary generates a summary of the health check results
@@ -240,6 +375,21 @@ func generateHealthSummary(components []types.HealthCheckResult, overallHealth s
 
 	if overallHealth == "Healthy" {
 		summary += "✅ All Sail Operator components are healthy and functioning properly.\n"
+	} else if overallHealth == "Reconciling" || overallHealth == "Stale" {
+		if overallHealth == "Reconciling" {
+			summary += "🔄 Sail Operator is still reconciling recent spec changes.\n"
+		} else {
+			summary += "⏳ Some Sail Operator resources have not been reconciled for too long; their status may be out of date.\n"
+		}
+		summary += "\nComponents in progress:\n"
+		for _, comp := range components {
+			if comp.Status != "Healthy" {
+				summary += fmt.Sprintf("  • %s: %s\n", comp.Component, comp.Reason)
+				for _, issue := range comp.Issues {
+					summary += fmt.Sprintf("    - %s\n", issue)
+				}
+			}
+		}
 	} else if overallHealth == "Degraded" {
 		summary += "⚠️  Some Sail Operator components have issues that need attention.\n"
 		summary += "\nComponents with issues:\n"
@@ -256,7 +406,7 @@ func generateHealthSummary(components []types.HealthCheckResult, overallHealth s
 		summary += "❌ Sail Operator components are experiencing significant issues.\n"
 		summary += "\nCritical issues found:\n"
 		for _, comp := range components {
-			if comp.Status == "Unhealthy" || comp.Status == "Error" {
+			if comp.Status == "Unhealthy" || comp.Status == "Error" || comp.Status == "Timeout" {
 				summary += fmt.Sprintf("  • %s: %s\n", comp.Component, comp.Reason)
 				for _, issue := range comp.Issues {
 					summary += fmt.Sprintf("    - %s\n", issue)
@@ -276,8 +426,14 @@ func formatComponentHealth(component types.HealthCheckResult) string {
 		statusEmoji = "✅"
 	case "Degraded":
 		statusEmoji = "⚠️"
+	case "Reconciling":
+		statusEmoji = "🔄"
+	case "Stale":
+		statusEmoji = "⏳"
 	case "Unhealthy", "Error":
 		statusEmoji = "❌"
+	case "Timeout":
+		statusEmoji = "⏱️"
 	case "NotFound", "NotInstalled":
 		statusEmoji = "⭕"
 	default:
@@ -295,5 +451,16 @@ func formatComponentHealth(component types.HealthCheckResult) string {
 		output += fmt.Sprintf("   🔸 %s\n", issue)
 	}
 
+	// Break down mixed results per resource
+	if component.Status != "Healthy" && len(component.Resources) > 1 {
+		for _, res := range component.Resources {
+			name := res.Name
+			if res.Namespace != "" {
+				name = fmt.Sprintf("%s/%s", res.Namespace, res.Name)
+			}
+			output += fmt.Sprintf("   • %s: %s\n", name, res.Status)
+		}
+	}
+
 	return output
 }
\ No newline at end of file
@@ -8,25 +8,33 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
 	"github.com/frherrer/mcp-sail-operator/pkg/types"
 )
 
-var (
-	istioGVR = schema.GroupVersionResource{
-		Group:    "sailoperator.io",
-		Version:  "v1",
-		Resource: "istios",
-	}
-)
-
 // GetIstioStatus gets detailed status information about Istio installations
-func GetIstioStatus(dynamicClient dynamic.Interface) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetIstioStatusParams]) (*mcp.CallToolResultFor[types.GetIstioStatusResult], error) {
+func GetIstioStatus(dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetIstioStatusParams]) (*mcp.CallToolResultFor[types.GetIstioStatusResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetIstioStatusParams]) (*mcp.CallToolResultFor[types.GetIstioStatusResult], error) {
 		var istios []types.IstioStatus
 
+		istioGVR, err := registry.GVR(ctx, sail.KindIstio)
+		if err != nil {
+			if sail.IsNotInstalled(err) {
+				return &mcp.CallToolResultFor[types.GetIstioStatusResult]{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: "Istio CRD not found. Sail Operator may not be installed.",
+					}},
+				}, nil
+			}
+			return &mcp.CallToolResultFor[types.GetIstioStatusResult]{
+				Content: []mcp.Content{&mcp.TextContent{
+					Text: fmt.Sprintf("Error resolving Istio API version: %v", err),
+				}},
+			}, nil
+		}
+
 		if params.Arguments.Name != "" {
 			// Get specific Istio resource (cluster-scoped)
 			istio, err := dynamicClient.Resource(istioGVR).Get(ctx, params.Arguments.Name, metav1.GetOptions{})
@@ -52,13 +60,6 @@ func GetIstioStatus(dynamicClient dynamic.Interface) func(ctx context.Context, c
 			istioList, err := dynamicClient.Resource(istioGVR).List(ctx, metav1.ListOptions{})
 
 			if err != nil {
-				if errors.IsNotFound(err) {
-					return &mcp.CallToolResultFor[types.GetIstioStatusResult]{
-						Content: []mcp.Content{&mcp.TextContent{
-							Text: "Istio CRD not found. Sail Operator may not be installed.",
-						}},
-					}, nil
-				}
 				return &mcp.CallToolResultFor[types.GetIstioStatusResult]{
 					Content: []mcp.Content{&mcp.TextContent{
 						Text: fmt.Sprintf("Error listing Istio resources: %v", err),
@@ -98,74 +99,33 @@ func GetIstioStatus(dynamicClient dynamic.Interface) func(ctx context.Context, c
 }
 
 // parseIstioStatus extracts status information from an unstructured Istio resource
-func parseIstioStatus(istio *unstructured.Unstructured) types.IstioStatus {
+func parseIstioStatus(u *unstructured.Unstructured) types.IstioStatus {
 	status := types.IstioStatus{
-		Name:      istio.GetName(),
-		Namespace: istio.GetNamespace(),
-		CreatedAt: istio.GetCreationTimestamp().String(),
+		Name:      u.GetName(),
+		Namespace: u.GetNamespace(),
+		CreatedAt: u.GetCreationTimestamp().String(),
 	}
 
-	// Extract spec information
-	if spec, found, _ := unstructured.NestedMap(istio.Object, "spec"); found {
-		if version, ok := spec["version"].(string); ok {
-			status.Version = version
-		}
-		if profile, ok := spec["profile"].(string); ok {
-			status.Profile = profile
-		}
-		if updateStrategy, found, _ := unstructured.NestedMap(istio.Object, "spec", "updateStrategy"); found {
-			if strategyType, ok := updateStrategy["type"].(string); ok {
-				status.UpdateStrategy = strategyType
-			}
-		}
+	istio, err := sail.Decode[sail.Istio](u)
+	if err != nil {
+		status.State = fmt.Sprintf("Unknown (%v)", err)
+		return status
 	}
 
-	// Extract status information
-	if statusMap, found, _ := unstructured.NestedMap(istio.Object, "status"); found {
-		if state, ok := statusMap["state"].(string); ok {
-			status.State = state
-		}
-		if activeRev, ok := statusMap["activeRevisionName"].(string); ok {
-			status.ActiveRevisionName = activeRev
-		}
-
-		// Extract revision summary
-		if revisions, found, _ := unstructured.NestedMap(istio.Object, "status", "revisions"); found {
-			if total, ok := revisions["total"].(int64); ok {
-				status.Revisions.Total = int(total)
-			}
-			if ready, ok := revisions["ready"].(int64); ok {
-				status.Revisions.Ready = int(ready)
-			}
-			if inUse, ok := revisions["inUse"].(int64); ok {
-				status.Revisions.InUse = int(inUse)
-			}
-		}
+	status.Version = istio.Spec.Version
+	status.Profile = istio.Spec.Profile
+	if istio.Spec.UpdateStrategy != nil {
+		status.UpdateStrategy = istio.Spec.UpdateStrategy.Type
+	}
 
-		// Extract conditions
-		if conditionsRaw, found, _ := unstructured.NestedSlice(istio.Object, "status", "conditions"); found {
-			var conditions []types.ResourceCondition
-			for _, condRaw := range conditionsRaw {
-				if condMap, ok := condRaw.(map[string]interface{}); ok {
-					condition := types.ResourceCondition{}
-					if t, ok := condMap["type"].(string); ok {
-						condition.Type = t
-					}
-					if s, ok := condMap["status"].(string); ok {
-						condition.Status = s
-					}
-					if r, ok := condMap["reason"].(string); ok {
-						condition.Reason = r
-					}
-					if m, ok := condMap["message"].(string); ok {
-						condition.Message = m
-					}
-					conditions = append(conditions, condition)
-				}
-			}
-			status.Conditions = conditions
-		}
+	status.State = istio.Status.State
+	status.ActiveRevisionName = istio.Status.ActiveRevisionName
+	status.Revisions = types.RevisionSummary{
+		Total: int(istio.Status.Revisions.Total),
+		Ready: int(istio.Status.Revisions.Ready),
+		InUse: int(istio.Status.Revisions.InUse),
 	}
+	status.Conditions = sail.ResourceConditions(istio.Status.Conditions)
 
 	return status
 }
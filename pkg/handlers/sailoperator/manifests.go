@@ -0,0 +1,248 @@
+package sailoperator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// fallbackSailVersion is the API version written for kinds the cluster does not serve
+const fallbackSailVersion = "v1"
+
+// GenerateIstioManifest renders Sail resources from a high-level description
+// of the mesh and validates them with a server-side dry-run create
+func GenerateIstioManifest(dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GenerateIstioManifestParams]) (*mcp.CallToolResultFor[types.GenerateIstioManifestResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GenerateIstioManifestParams]) (*mcp.CallToolResultFor[types.GenerateIstioManifestResult], error) {
+		args := params.Arguments
+		if args.Version == "" {
+			return &mcp.CallToolResultFor[types.GenerateIstioManifestResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: "version is required, e.g. v1.24.3"}},
+			}, nil
+		}
+		if args.Strategy != "" && args.Strategy != "InPlace" && args.Strategy != "RevisionBased" {
+			return &mcp.CallToolResultFor[types.GenerateIstioManifestResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("strategy must be InPlace or RevisionBased, got '%s'", args.Strategy)}},
+			}, nil
+		}
+
+		result := types.GenerateIstioManifestResult{Status: "success"}
+		objects, notes := buildSailManifests(ctx, registry, args)
+		result.Notes = notes
+
+		var docs []string
+		for _, obj := range objects {
+			data, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return &mcp.CallToolResultFor[types.GenerateIstioManifestResult]{
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error rendering %s: %v", obj.GetKind(), err)}},
+				}, nil
+			}
+			docs = append(docs, string(data))
+		}
+		result.YAML = strings.Join(docs, "---\n")
+
+		if !args.SkipValidation {
+			for _, obj := range objects {
+				result.Validations = append(result.Validations, validateSailObject(ctx, dynamicClient, registry, obj))
+			}
+			result.Notes = append(result.Notes, checkManifestNamespaces(ctx, dynamicClient, objects)...)
+		}
+
+		output := formatGeneratedManifest(result)
+		return &mcp.CallToolResultFor[types.GenerateIstioManifestResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// buildSailManifests renders the objects for the requested mesh, in the order they should be applied
+func buildSailManifests(ctx context.Context, registry *sail.Registry, args types.GenerateIstioManifestParams) ([]*unstructured.Unstructured, []string) {
+	var notes []string
+
+	apiVersion := func(k sail.ResourceKind) string {
+		gvr, err := registry.GVR(ctx, k)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s is not served by the cluster; using %s/%s", k.Kind, sail.Group, fallbackSailVersion))
+			return sail.Group + "/" + fallbackSailVersion
+		}
+		return gvr.GroupVersion().String()
+	}
+	newObject := func(k sail.ResourceKind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion(k),
+			"kind":       k.Kind,
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       spec,
+		}}
+	}
+
+	name := args.Name
+	if name == "" {
+		name = "default"
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = "istio-system"
+	}
+	strategy := args.Strategy
+	if strategy == "" {
+		strategy = "InPlace"
+	}
+	profile := args.Profile
+	if profile == "" && args.Ambient {
+		profile = "ambient"
+	}
+	if profile != "" {
+		if _, ok := sail.ProfileDefaults(profile); !ok {
+			notes = append(notes, fmt.Sprintf("Profile '%s' is not one of the known built-in profiles (%s)", profile, strings.Join(sail.Profiles(), ", ")))
+		}
+	}
+	if args.Ambient && profile != "ambient" && !strings.HasSuffix(profile, "-ambient") {
+		notes = append(notes, fmt.Sprintf("Ambient mode usually needs the ambient profile, but profile '%s' was requested", profile))
+	}
+
+	var objects []*unstructured.Unstructured
+
+	if args.Ambient || args.CNI {
+		spec := map[string]interface{}{"version": args.Version, "namespace": istioCNIAgent.defaultNamespace}
+		if args.Ambient {
+			spec["profile"] = "ambient"
+		}
+		objects = append(objects, newObject(sail.KindIstioCNI, "default", spec))
+	}
+	if args.Ambient {
+		objects = append(objects, newObject(sail.KindZTunnel, "default", map[string]interface{}{
+			"version":   args.Version,
+			"namespace": ztunnelAgent.defaultNamespace,
+		}))
+	}
+
+	istioSpec := map[string]interface{}{
+		"version":        args.Version,
+		"namespace":      namespace,
+		"updateStrategy": map[string]interface{}{"type": strategy},
+	}
+	if profile != "" {
+		istioSpec["profile"] = profile
+	}
+	values := map[string]interface{}{}
+	if args.MeshID != "" {
+		values["global"] = map[string]interface{}{"meshID": args.MeshID}
+	}
+	if args.TrustDomain != "" {
+		values["meshConfig"] = map[string]interface{}{"trustDomain": args.TrustDomain}
+	}
+	if len(values) > 0 {
+		istioSpec["values"] = values
+	}
+	objects = append(objects, newObject(sail.KindIstio, name, istioSpec))
+
+	if strategy == "RevisionBased" {
+		tag := args.Tag
+		if tag == "" {
+			tag = "default"
+		}
+		objects = append(objects, newObject(sail.KindIstioRevisionTag, tag, map[string]interface{}{
+			"targetRef": map[string]interface{}{"kind": sail.KindIstio.Kind, "name": name},
+		}))
+		notes = append(notes, fmt.Sprintf("Label namespaces with istio.io/rev=%s so workloads follow the tag across revisions", tag))
+	} else if args.Tag != "" {
+		notes = append(notes, "A revision tag is only generated for the RevisionBased strategy")
+	}
+
+	return objects, notes
+}
+
+// validateSailObject dry-runs the creation of an object. Objects that already
+// exist are validated with a dry-run update instead.
+func validateSailObject(ctx context.Context, dynamicClient dynamic.Interface, registry *sail.Registry, obj *unstructured.Unstructured) types.ManifestValidation {
+	v := types.ManifestValidation{Kind: obj.GetKind(), Name: obj.GetName(), Mode: "create"}
+
+	kind, _ := sail.LookupKind(obj.GetKind())
+	gvr, err := registry.GVR(ctx, kind)
+	if err != nil {
+		v.Mode = "skipped"
+		v.Error = err.Error()
+		return v
+	}
+	res := dynamicClient.Resource(gvr)
+	dryRun := []string{metav1.DryRunAll}
+
+	_, err = res.Create(ctx, obj, metav1.CreateOptions{DryRun: dryRun})
+	if errors.IsAlreadyExists(err) {
+		v.Mode = "update"
+		existing, getErr := res.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if getErr != nil {
+			v.Error = fmt.Sprintf("object exists but could not be read: %v", getErr)
+			return v
+		}
+		updated := obj.DeepCopy()
+		updated.SetResourceVersion(existing.GetResourceVersion())
+		_, err = res.Update(ctx, updated, metav1.UpdateOptions{DryRun: dryRun})
+	}
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+	v.Valid = true
+	return v
+}
+
+// checkManifestNamespaces reports target namespaces that do not exist yet
+func checkManifestNamespaces(ctx context.Context, dynamicClient dynamic.Interface, objects []*unstructured.Unstructured) []string {
+	var notes []string
+	namespaces := dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"})
+	for _, obj := range objects {
+		ns, found, _ := unstructured.NestedString(obj.Object, "spec", "namespace")
+		if !found || ns == "" {
+			continue
+		}
+		if _, err := namespaces.Get(ctx, ns, metav1.GetOptions{}); errors.IsNotFound(err) {
+			notes = append(notes, fmt.Sprintf("Namespace %s does not exist; create it before applying %s %s", ns, obj.GetKind(), obj.GetName()))
+		}
+	}
+	return notes
+}
+
+// formatGeneratedManifest formats the generated YAML with its validation results
+func formatGeneratedManifest(result types.GenerateIstioManifestResult) string {
+	output := "=== Generated Sail Operator Manifests ===\n\n"
+	output += result.YAML
+
+	if len(result.Validations) > 0 {
+		output += "\nServer-side validation (dry-run):\n"
+		for _, v := range result.Validations {
+			switch {
+			case v.Valid:
+				output += fmt.Sprintf("  ✅ %s %s: valid (%s)\n", v.Kind, v.Name, v.Mode)
+			case v.Mode == "skipped":
+				output += fmt.Sprintf("  ⚠️ %s %s: not validated - %s\n", v.Kind, v.Name, v.Error)
+			default:
+				output += fmt.Sprintf("  ❌ %s %s: %s\n", v.Kind, v.Name, v.Error)
+			}
+		}
+	}
+
+	if len(result.Notes) > 0 {
+		output += "\nNotes:\n"
+		for _, note := range result.Notes {
+			output += fmt.Sprintf("  • %s\n", note)
+		}
+	}
+
+	return output
+}
@@ -0,0 +1,571 @@
+package sailoperator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// nodeAgent describes where the operator deploys a node agent DaemonSet
+type nodeAgent struct {
+	kind             sail.ResourceKind
+	defaultNamespace string
+	daemonSetName    string
+}
+
+var (
+	istioCNIAgent = nodeAgent{kind: sail.KindIstioCNI, defaultNamespace: "istio-cni", daemonSetName: "istio-cni-node"}
+	ztunnelAgent  = nodeAgent{kind: sail.KindZTunnel, defaultNamespace: "ztunnel", daemonSetName: "ztunnel"}
+)
+
+// cniConfigMapName is the ConfigMap the istio-cni chart renders for the node agent
+const cniConfigMapName = "istio-cni-config"
+
+// daemonSetTolerations are the tolerations the DaemonSet controller adds to
+// every daemon pod in addition to those in the pod template
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: "node.kubernetes.io/not-ready", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: "node.kubernetes.io/unreachable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: "node.kubernetes.io/disk-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.kubernetes.io/memory-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.kubernetes.io/pid-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.kubernetes.io/unschedulable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// GetIstioCNIStatus compares the IstioCNI DaemonSet with the cluster nodes and
+// reports the CNI plugin chaining configuration
+func GetIstioCNIStatus(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.NodeAgentStatusParams]) (*mcp.CallToolResultFor[types.NodeAgentStatusResult], error) {
+	return nodeAgentStatus(k8sClient, dynamicClient, registry, istioCNIAgent)
+}
+
+// GetZTunnelStatus compares the ZTunnel DaemonSet with the cluster nodes
+func GetZTunnelStatus(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.NodeAgentStatusParams]) (*mcp.CallToolResultFor[types.NodeAgentStatusResult], error) {
+	return nodeAgentStatus(k8sClient, dynamicClient, registry, ztunnelAgent)
+}
+
+func nodeAgentStatus(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry, agent nodeAgent) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.NodeAgentStatusParams]) (*mcp.CallToolResultFor[types.NodeAgentStatusResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.NodeAgentStatusParams]) (*mcp.CallToolResultFor[types.NodeAgentStatusResult], error) {
+		name := params.Arguments.Name
+		if name == "" {
+			name = "default"
+		}
+		kind := agent.kind.Kind
+
+		errorResult := func(format string, a ...interface{}) (*mcp.CallToolResultFor[types.NodeAgentStatusResult], error) {
+			return &mcp.CallToolResultFor[types.NodeAgentStatusResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf(format, a...)}},
+			}, nil
+		}
+
+		gvr, err := registry.GVR(ctx, agent.kind)
+		if err != nil {
+			if sail.IsNotInstalled(err) {
+				return errorResult("%s CRD not found. Sail Operator may not be installed.", kind)
+			}
+			return errorResult("Error resolving %s API version: %v", kind, err)
+		}
+
+		u, err := dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return errorResult("%s resource '%s' not found (cluster-scoped)", kind, name)
+			}
+			return errorResult("Error getting %s resource '%s': %v", kind, name, err)
+		}
+		obj, err := sail.Decode[sail.Object](u)
+		if err != nil {
+			return errorResult("Error decoding %s resource '%s': %v", kind, name, err)
+		}
+
+		namespace := obj.Spec.Namespace
+		if namespace == "" {
+			namespace = agent.defaultNamespace
+		}
+
+		result := types.NodeAgentStatusResult{
+			Status:     "success",
+			Kind:       kind,
+			Name:       name,
+			Namespace:  namespace,
+			Version:    obj.Spec.Version,
+			State:      obj.Status.State,
+			Conditions: sail.ResourceConditions(obj.Status.Conditions),
+		}
+
+		ds, err := findAgentDaemonSet(ctx, k8sClient, namespace, agent, name)
+		if err != nil {
+			return errorResult("Error finding %s DaemonSet in namespace '%s': %v", kind, namespace, err)
+		}
+
+		nodes, err := k8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errorResult("Error listing nodes: %v", err)
+		}
+		result.TotalNodes = len(nodes.Items)
+
+		if ds == nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("No %s DaemonSet found in namespace '%s'; no node runs the agent", kind, namespace))
+			for _, node := range nodes.Items {
+				result.Nodes = append(result.Nodes, types.NodeCoverage{Node: node.Name, Status: "Missing", Reason: "DaemonSet does not exist"})
+			}
+		} else {
+			coverage, nodeCoverage, issues, err := analyzeDaemonSetCoverage(ctx, k8sClient, ds, nodes.Items)
+			if err != nil {
+				return errorResult("Error analyzing %s DaemonSet: %v", kind, err)
+			}
+			result.DaemonSet = &coverage
+			result.Nodes = nodeCoverage
+			result.Issues = append(result.Issues, issues...)
+		}
+		for _, n := range result.Nodes {
+			if n.Status == "Ready" {
+				result.Covered++
+			}
+		}
+
+		if agent.kind == sail.KindIstioCNI {
+			cniConfig, issues := inspectCNIChaining(ctx, k8sClient, namespace, u)
+			result.CNIConfig = cniConfig
+			result.Issues = append(result.Issues, issues...)
+		}
+
+		output := formatNodeAgentStatus(result, params.Arguments.ShowAllNodes)
+		return &mcp.CallToolResultFor[types.NodeAgentStatusResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// findAgentDaemonSet returns the DaemonSet owned by the Sail resource, falling
+// back to the chart's default DaemonSet name. It returns nil if neither exists.
+func findAgentDaemonSet(ctx context.Context, k8sClient *kubernetes.Clientset, namespace string, agent nodeAgent, owner string) (*appsv1.DaemonSet, error) {
+	list, err := k8sClient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var byName *appsv1.DaemonSet
+	for i := range list.Items {
+		ds := &list.Items[i]
+		for _, ref := range ds.OwnerReferences {
+			if ref.Kind == agent.kind.Kind && ref.Name == owner {
+				return ds, nil
+			}
+		}
+		if ds.Name == agent.daemonSetName {
+			byName = ds
+		}
+	}
+	return byName, nil
+}
+
+// analyzeDaemonSetCoverage matches the DaemonSet pods to nodes and explains
+// every node that does not run a ready pod
+func analyzeDaemonSetCoverage(ctx context.Context, k8sClient *kubernetes.Clientset, ds *appsv1.DaemonSet, nodes []corev1.Node) (types.DaemonSetCoverage, []types.NodeCoverage, []string, error) {
+	var issues []string
+	podSpec := ds.Spec.Template.Spec
+
+	coverage := types.DaemonSetCoverage{
+		Name:         ds.Name,
+		Namespace:    ds.Namespace,
+		Desired:      ds.Status.DesiredNumberScheduled,
+		Scheduled:    ds.Status.CurrentNumberScheduled,
+		Ready:        ds.Status.NumberReady,
+		Available:    ds.Status.NumberAvailable,
+		Updated:      ds.Status.UpdatedNumberScheduled,
+		Misscheduled: ds.Status.NumberMisscheduled,
+	}
+	for _, t := range podSpec.Tolerations {
+		coverage.Tolerations = append(coverage.Tolerations, formatToleration(t))
+	}
+	if len(podSpec.NodeSelector) > 0 {
+		coverage.NodeSelector = labels.SelectorFromSet(podSpec.NodeSelector).String()
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return coverage, nil, nil, fmt.Errorf("invalid DaemonSet selector: %w", err)
+	}
+	pods, err := k8sClient.CoreV1().Pods(ds.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return coverage, nil, nil, fmt.Errorf("failed to list DaemonSet pods: %w", err)
+	}
+	podsByNode := make(map[string]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if existing, ok := podsByNode[pod.Spec.NodeName]; ok && existing.DeletionTimestamp == nil {
+			continue // prefer the live pod over one that is terminating
+		}
+		podsByNode[pod.Spec.NodeName] = pod
+	}
+
+	tolerations := append(append([]corev1.Toleration{}, podSpec.Tolerations...), daemonSetTolerations...)
+	if podSpec.HostNetwork {
+		tolerations = append(tolerations, corev1.Toleration{Key: "node.kubernetes.io/network-unavailable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule})
+	}
+
+	var nodeCoverage []types.NodeCoverage
+	for _, node := range nodes {
+		nc := types.NodeCoverage{Node: node.Name}
+		pod := podsByNode[node.Name]
+
+		if pod != nil {
+			nc.Pod = pod.Name
+			nc.PodPhase = string(pod.Status.Phase)
+			if isPodReady(pod) {
+				nc.Status = "Ready"
+			} else {
+				nc.Status = "NotReady"
+				nc.Reason = podNotReadyReason(pod)
+			}
+			nodeCoverage = append(nodeCoverage, nc)
+			continue
+		}
+
+		untolerated := untoleratedTaints(node.Spec.Taints, tolerations)
+		for _, taint := range untolerated {
+			nc.UntoleratedTaints = append(nc.UntoleratedTaints, formatTaint(taint))
+		}
+		matches, mismatch := nodeMatchesPodSpec(&node, &podSpec)
+		switch {
+		case len(untolerated) > 0:
+			nc.Status = "Excluded"
+			nc.Reason = "node has taints the DaemonSet does not tolerate"
+		case !matches:
+			nc.Status = "Excluded"
+			nc.Reason = mismatch
+		default:
+			nc.Status = "Missing"
+			nc.Reason = "no pod scheduled on an eligible node"
+			if !isNodeReady(&node) {
+				nc.Reason = "node is NotReady"
+			}
+		}
+		nodeCoverage = append(nodeCoverage, nc)
+	}
+
+	sort.Slice(nodeCoverage, func(i, j int) bool { return nodeCoverage[i].Node < nodeCoverage[j].Node })
+
+	counts := make(map[string]int)
+	for _, nc := range nodeCoverage {
+		counts[nc.Status]++
+	}
+	if counts["Excluded"] > 0 {
+		issues = append(issues, fmt.Sprintf("%d node(s) cannot run the DaemonSet because of taints or node selection; pods scheduled there will not be served by the agent", counts["Excluded"]))
+	}
+	if counts["Missing"] > 0 {
+		issues = append(issues, fmt.Sprintf("%d eligible node(s) have no DaemonSet pod", counts["Missing"]))
+	}
+	if counts["NotReady"] > 0 {
+		issues = append(issues, fmt.Sprintf("%d node(s) run a DaemonSet pod that is not ready", counts["NotReady"]))
+	}
+	if coverage.Updated < coverage.Desired {
+		issues = append(issues, fmt.Sprintf("Rollout in progress: %d of %d pods updated", coverage.Updated, coverage.Desired))
+	}
+	if coverage.Misscheduled > 0 {
+		issues = append(issues, fmt.Sprintf("%d pod(s) run on nodes they should not", coverage.Misscheduled))
+	}
+
+	return coverage, nodeCoverage, issues, nil
+}
+
+// untoleratedTaints returns the scheduling taints of a node none of the tolerations match
+func untoleratedTaints(taints []corev1.Taint, tolerations []corev1.Toleration) []corev1.Taint {
+	var untolerated []corev1.Taint
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			untolerated = append(untolerated, *taint)
+		}
+	}
+	return untolerated
+}
+
+// nodeMatchesPodSpec evaluates the pod's nodeSelector and required node
+// affinity against a node, returning a reason when it does not match
+func nodeMatchesPodSpec(node *corev1.Node, spec *corev1.PodSpec) (bool, string) {
+	if len(spec.NodeSelector) > 0 && !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false, fmt.Sprintf("node labels do not match nodeSelector %s", labels.SelectorFromSet(spec.NodeSelector))
+	}
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, ""
+	}
+	for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if nodeMatchesTerm(node, term) {
+			return true, ""
+		}
+	}
+	return false, "node does not match the required node affinity"
+}
+
+// nodeMatchesTerm evaluates a single node selector term; terms without
+// requirements match nothing, as in the scheduler
+func nodeMatchesTerm(node *corev1.Node, term corev1.NodeSelectorTerm) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, expr := range term.MatchExpressions {
+		op, ok := nodeSelectorOperators[expr.Operator]
+		if !ok {
+			return false
+		}
+		req, err := labels.NewRequirement(expr.Key, op, expr.Values)
+		if err != nil || !req.Matches(labels.Set(node.Labels)) {
+			return false
+		}
+	}
+	for _, field := range term.MatchFields {
+		if field.Key != "metadata.name" {
+			return false
+		}
+		found := false
+		for _, v := range field.Values {
+			if v == node.Name {
+				found = true
+			}
+		}
+		if (field.Operator == corev1.NodeSelectorOpIn) != found {
+			return false
+		}
+	}
+	return true
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// inspectCNIChaining reads the CNI chaining settings from the agent's
+// ConfigMap and the IstioCNI values
+func inspectCNIChaining(ctx context.Context, k8sClient *kubernetes.Clientset, namespace string, u *unstructured.Unstructured) (*types.CNIChainingConfig, []string) {
+	var issues []string
+	config := &types.CNIChainingConfig{}
+
+	// The values are what the user asked for; the ConfigMap below is what the agent runs with
+	if cni, err := sail.Decode[sail.IstioCNI](u); err == nil && cni.Spec.Values != nil && cni.Spec.Values.Cni != nil {
+		values := cni.Spec.Values.Cni
+		config.Chained = values.Chained
+		config.Provider = values.Provider
+		config.ConfDir = values.CniConfDir
+		config.BinDir = values.CniBinDir
+		config.ConfFileName = values.CniConfFileName
+	}
+
+	cm, err := k8sClient.CoreV1().ConfigMaps(namespace).Get(ctx, cniConfigMapName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		issues = append(issues, fmt.Sprintf("ConfigMap %s/%s not found; the CNI agent configuration cannot be inspected", namespace, cniConfigMapName))
+	case err != nil:
+		issues = append(issues, fmt.Sprintf("Failed to read ConfigMap %s/%s: %v", namespace, cniConfigMapName, err))
+	default:
+		config.ConfigMap = fmt.Sprintf("%s/%s", namespace, cniConfigMapName)
+		config.Data = cm.Data
+		if v, ok := cm.Data["CHAINED_CNI_PLUGIN"]; ok {
+			if chained, err := strconv.ParseBool(v); err == nil {
+				config.Chained = &chained
+			}
+		}
+		if v := cm.Data["CNI_NET_DIR"]; v != "" {
+			config.ConfDir = v
+		}
+		if v := cm.Data["CNI_CONF_NAME"]; v != "" {
+			config.ConfFileName = v
+		}
+	}
+
+	return config, issues
+}
+
+// isPodReady reports whether the pod's Ready condition is True
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isNodeReady reports whether the node's Ready condition is True
+func isNodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podNotReadyReason explains why a pod is not ready from its container states
+func podNotReadyReason(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "pod is terminating"
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return fmt.Sprintf("container %s: %s", cs.Name, cs.State.Waiting.Reason)
+		}
+		if cs.State.Terminated != nil {
+			return fmt.Sprintf("container %s terminated: %s", cs.Name, cs.State.Terminated.Reason)
+		}
+		if !cs.Ready {
+			return fmt.Sprintf("container %s not ready (%d restarts)", cs.Name, cs.RestartCount)
+		}
+	}
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return fmt.Sprintf("pod is %s", pod.Status.Phase)
+}
+
+func formatTaint(t corev1.Taint) string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+func formatToleration(t corev1.Toleration) string {
+	key := t.Key
+	if key == "" {
+		key = "*"
+	}
+	s := key
+	if t.Operator == corev1.TolerationOpEqual || (t.Operator == "" && t.Value != "") {
+		s += "=" + t.Value
+	}
+	if t.Effect != "" {
+		s += ":" + string(t.Effect)
+	} else {
+		s += ":*"
+	}
+	return s
+}
+
+// formatNodeAgentStatus formats the node agent coverage report
+func formatNodeAgentStatus(result types.NodeAgentStatusResult, showAll bool) string {
+	output := fmt.Sprintf("=== %s: %s ===\n", result.Kind, result.Name)
+	output += fmt.Sprintf("Namespace: %s\n", result.Namespace)
+	if result.Version != "" {
+		output += fmt.Sprintf("Version: %s\n", result.Version)
+	}
+	if result.State != "" {
+		output += fmt.Sprintf("State: %s\n", result.State)
+	}
+
+	if ds := result.DaemonSet; ds != nil {
+		output += fmt.Sprintf("\nDaemonSet %s/%s:\n", ds.Namespace, ds.Name)
+		output += fmt.Sprintf("  Desired: %d, Scheduled: %d, Ready: %d, Available: %d, Updated: %d\n",
+			ds.Desired, ds.Scheduled, ds.Ready, ds.Available, ds.Updated)
+		if ds.NodeSelector != "" {
+			output += fmt.Sprintf("  Node selector: %s\n", ds.NodeSelector)
+		}
+		if len(ds.Tolerations) > 0 {
+			output += fmt.Sprintf("  Tolerations: %s\n", strings.Join(ds.Tolerations, ", "))
+		}
+	}
+
+	output += fmt.Sprintf("\nNode coverage: %d/%d nodes run a ready pod\n", result.Covered, result.TotalNodes)
+	for _, n := range result.Nodes {
+		if n.Status == "Ready" && !showAll {
+			continue
+		}
+		emoji := "✅"
+		switch n.Status {
+		case "NotReady":
+			emoji = "⚠️"
+		case "Missing", "Excluded":
+			emoji = "❌"
+		}
+		output += fmt.Sprintf("  %s %s: %s", emoji, n.Node, n.Status)
+		if n.Pod != "" {
+			output += fmt.Sprintf(" (pod %s)", n.Pod)
+		}
+		if n.Reason != "" {
+			output += fmt.Sprintf(" - %s", n.Reason)
+		}
+		output += "\n"
+		if len(n.UntoleratedTaints) > 0 {
+			output += fmt.Sprintf("      untolerated taints: %s\n", strings.Join(n.UntoleratedTaints, ", "))
+		}
+	}
+
+	if cni := result.CNIConfig; cni != nil {
+		output += "\nCNI plugin configuration:\n"
+		if cni.Chained != nil {
+			mode := "chained (appended to the primary CNI configuration)"
+			if !*cni.Chained {
+				mode = "standalone (own configuration file, e.g. for Multus)"
+			}
+			output += fmt.Sprintf("  Mode: %s\n", mode)
+		}
+		if cni.Provider != "" {
+			output += fmt.Sprintf("  Provider: %s\n", cni.Provider)
+		}
+		if cni.ConfDir != "" {
+			output += fmt.Sprintf("  Config dir: %s\n", cni.ConfDir)
+		}
+		if cni.ConfFileName != "" {
+			output += fmt.Sprintf("  Config file: %s\n", cni.ConfFileName)
+		}
+		if cni.BinDir != "" {
+			output += fmt.Sprintf("  Binary dir: %s\n", cni.BinDir)
+		}
+		if cni.ConfigMap != "" {
+			output += fmt.Sprintf("  Source: ConfigMap %s\n", cni.ConfigMap)
+			keys := make([]string, 0, len(cni.Data))
+			for k := range cni.Data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				output += fmt.Sprintf("    %s=%s\n", k, cni.Data[k])
+			}
+		}
+	}
+
+	if len(result.Issues) > 0 {
+		output += "\nIssues:\n"
+		for _, issue := range result.Issues {
+			output += fmt.Sprintf("  • %s\n", issue)
+		}
+	}
+
+	return output
+}
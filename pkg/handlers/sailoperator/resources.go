@@ -2,70 +2,59 @@ package sailoperator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
 	"github.com/frherrer/mcp-sail-operator/pkg/types"
 )
 
 // ListSailOperatorResources lists Sail Operator CRD resources
-func ListSailOperatorResources(dynamicClient dynamic.Interface) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListSailOperatorResourcesParams]) (*mcp.CallToolResultFor[types.ListSailOperatorResourcesResult], error) {
+func ListSailOperatorResources(dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListSailOperatorResourcesParams]) (*mcp.CallToolResultFor[types.ListSailOperatorResourcesResult], error) {
 	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.ListSailOperatorResourcesParams]) (*mcp.CallToolResultFor[types.ListSailOperatorResourcesResult], error) {
 		var resources []types.SailOperatorResource
 		var totalCount int
 
-		// Define Sail Operator CRD resource types
-		crdTypes := map[string]schema.GroupVersionResource{
-			"istio": {
-				Group:    "sailoperator.io",
-				Version:  "v1",
-				Resource: "istios",
-			},
-			"istiorevision": {
-				Group:    "sailoperator.io",
-				Version:  "v1",
-				Resource: "istiorevisions",
-			},
-			"istiocni": {
-				Group:    "sailoperator.io",
-				Version:  "v1",
-				Resource: "istiocnis",
-			},
-			"ztunnel": {
-				Group:    "sailoperator.io",
-				Version:  "v1alpha1",
-				Resource: "ztunnels",
-			},
-		}
-
 		// Determine which resources to query
-		resourcesToQuery := make(map[string]schema.GroupVersionResource)
+		var kindsToQuery []sail.ResourceKind
 		if params.Arguments.Resource == "" || params.Arguments.Resource == "all" {
-			resourcesToQuery = crdTypes
+			kindsToQuery = sail.Kinds
 		} else {
-			if gvr, exists := crdTypes[strings.ToLower(params.Arguments.Resource)]; exists {
-				resourcesToQuery[params.Arguments.Resource] = gvr
+			if kind, exists := sail.LookupKind(params.Arguments.Resource); exists {
+				kindsToQuery = append(kindsToQuery, kind)
 			} else {
 				return &mcp.CallToolResultFor[types.ListSailOperatorResourcesResult]{
 					Content: []mcp.Content{&mcp.TextContent{
-						Text: fmt.Sprintf("Unknown resource type: %s. Available types: istio, istiorevision, istiocni, ztunnel", params.Arguments.Resource),
+						Text: fmt.Sprintf("Unknown resource type: %s. Available types: istio, istiorevision, istiorevisiontag, istiocni, ztunnel", params.Arguments.Resource),
 					}},
 				}, nil
 			}
 		}
 
 		// Query each resource type
-		for resourceType, gvr := range resourcesToQuery {
-			var resourceList *unstructured.UnstructuredList
-			var err error
+		var notInstalled []string
+		for _, kind := range kindsToQuery {
+			gvr, err := registry.GVR(ctx, kind)
+			if err != nil {
+				if sail.IsNotInstalled(err) {
+					// CRD is not installed, continue with other resources
+					notInstalled = append(notInstalled, kind.Kind)
+					continue
+				}
+				return &mcp.CallToolResultFor[types.ListSailOperatorResourcesResult]{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: fmt.Sprintf("Error resolving %s API version: %v", kind.Kind, err),
+					}},
+				}, nil
+			}
 
+			var resourceList *unstructured.UnstructuredList
 			if params.Arguments.Namespace != "" {
 				resourceList, err = dynamicClient.Resource(gvr).Namespace(params.Arguments.Namespace).List(ctx, metav1.ListOptions{})
 			} else {
@@ -73,13 +62,9 @@ func ListSailOperatorResources(dynamicClient dynamic.Interface) func(ctx context
 			}
 
 			if err != nil {
-				if errors.IsNotFound(err) {
-					// CRD might not be installed, continue with other resources
-					continue
-				}
 				return &mcp.CallToolResultFor[types.ListSailOperatorResourcesResult]{
 					Content: []mcp.Content{&mcp.TextContent{
-						Text: fmt.Sprintf("Error listing %s resources: %v", resourceType, err),
+						Text: fmt.Sprintf("Error listing %s resources (%s): %v", kind.Kind, gvr.GroupVersion(), err),
 					}},
 				}, nil
 			}
@@ -93,42 +78,11 @@ func ListSailOperatorResources(dynamicClient dynamic.Interface) func(ctx context
 					CreatedAt: item.GetCreationTimestamp().String(),
 				}
 
-				// Extract version from spec
-				if spec, found, _ := unstructured.NestedMap(item.Object, "spec"); found {
-					if version, ok := spec["version"].(string); ok {
-						resource.Version = version
-					}
-				}
-
-				// Extract state and conditions from status
-				if status, found, _ := unstructured.NestedMap(item.Object, "status"); found {
-					if state, ok := status["state"].(string); ok {
-						resource.State = state
-					}
-
-					// Extract conditions
-					if conditionsRaw, found, _ := unstructured.NestedSlice(item.Object, "status", "conditions"); found {
-						var conditions []types.ResourceCondition
-						for _, condRaw := range conditionsRaw {
-							if condMap, ok := condRaw.(map[string]interface{}); ok {
-								condition := types.ResourceCondition{}
-								if t, ok := condMap["type"].(string); ok {
-									condition.Type = t
-								}
-								if s, ok := condMap["status"].(string); ok {
-									condition.Status = s
-								}
-								if r, ok := condMap["reason"].(string); ok {
-									condition.Reason = r
-								}
-								if m, ok := condMap["message"].(string); ok {
-									condition.Message = m
-								}
-								conditions = append(conditions, condition)
-							}
-						}
-						resource.Conditions = conditions
-					}
+				// Extract version, state and conditions
+				if obj, err := sail.Decode[sail.Object](&item); err == nil {
+					resource.Version = obj.Spec.Version
+					resource.State = obj.Status.State
+					resource.Conditions = sail.ResourceConditions(obj.Status.Conditions)
 				}
 
 				resources = append(resources, resource)
@@ -155,8 +109,12 @@ func ListSailOperatorResources(dynamicClient dynamic.Interface) func(ctx context
 				resourcesByType[res.Kind] = append(resourcesByType[res.Kind], res)
 			}
 
-			for kind, resList := range resourcesByType {
-				output += fmt.Sprintf("=== %s ===\n", kind)
+			for _, kind := range kindsToQuery {
+				resList, ok := resourcesByType[kind.Kind]
+				if !ok {
+					continue
+				}
+				output += fmt.Sprintf("=== %s ===\n", kind.Kind)
 				for _, res := range resList {
 					output += fmt.Sprintf("• %s", res.Name)
 					if res.Namespace != "" {
@@ -193,10 +151,22 @@ func ListSailOperatorResources(dynamicClient dynamic.Interface) func(ctx context
 			}
 		}
 
+		if len(notInstalled) > 0 {
+			output += fmt.Sprintf("\nCRDs not installed: %s", strings.Join(notInstalled, ", "))
+		}
+
 		return &mcp.CallToolResultFor[types.ListSailOperatorResourcesResult]{
 			Content: []mcp.Content{&mcp.TextContent{
 				Text: output,
 			}},
 		}, nil
 	}
-}
\ No newline at end of file
+}
+// toJSONString marshals a value into compact JSON string. On failure, returns an empty JSON object
+func toJSONString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
@@ -0,0 +1,279 @@
+package sailoperator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// GetIstioValues shows the values set on an Istio resource, the values the
+// operator rendered into its IstioRevisions and the differences between them
+func GetIstioValues(dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetIstioValuesParams]) (*mcp.CallToolResultFor[types.GetIstioValuesResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.GetIstioValuesParams]) (*mcp.CallToolResultFor[types.GetIstioValuesResult], error) {
+		args := params.Arguments
+		name := args.Name
+		if name == "" {
+			name = "default"
+		}
+
+		errorResult := func(format string, a ...interface{}) (*mcp.CallToolResultFor[types.GetIstioValuesResult], error) {
+			return &mcp.CallToolResultFor[types.GetIstioValuesResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf(format, a...)}},
+			}, nil
+		}
+
+		istioGVR, err := registry.GVR(ctx, sail.KindIstio)
+		if err != nil {
+			return errorResult("Error resolving Istio API version: %v", err)
+		}
+		revisionGVR, err := registry.GVR(ctx, sail.KindIstioRevision)
+		if err != nil {
+			return errorResult("Error resolving IstioRevision API version: %v", err)
+		}
+
+		u, err := dynamicClient.Resource(istioGVR).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return errorResult("Istio resource '%s' not found (cluster-scoped)", name)
+			}
+			return errorResult("Error getting Istio resource '%s': %v", name, err)
+		}
+		istio, err := sail.Decode[sail.Istio](u)
+		if err != nil {
+			return errorResult("Error decoding Istio resource '%s': %v", name, err)
+		}
+
+		profile := istio.Spec.Profile
+		if profile == "" {
+			profile = "default"
+		}
+		userValues := map[string]interface{}{}
+		if istio.Spec.Values != nil && istio.Spec.Values.Raw != nil {
+			userValues = istio.Spec.Values.Raw
+		}
+
+		result := types.GetIstioValuesResult{
+			Status:     "success",
+			Name:       name,
+			Version:    istio.Spec.Version,
+			Profile:    profile,
+			UserValues: userValues,
+		}
+
+		var notes []string
+		addNote := func(format string, a ...interface{}) {
+			note := fmt.Sprintf(format, a...)
+			for _, n := range notes {
+				if n == note {
+					return
+				}
+			}
+			notes = append(notes, note)
+		}
+		withDefaults := func(values map[string]interface{}, fallbackProfile string) map[string]interface{} {
+			if !args.IncludeProfileDefaults {
+				return values
+			}
+			p := fallbackProfile
+			if vp, ok := values["profile"].(string); ok && vp != "" {
+				p = vp
+			}
+			defaults, ok := sail.ProfileDefaults(p)
+			if !ok {
+				addNote("Profile '%s' is not known to the server (known: %s); its defaults are not included", p, strings.Join(sail.Profiles(), ", "))
+				return values
+			}
+			return sail.MergeValues(defaults, values)
+		}
+		if args.IncludeProfileDefaults {
+			result.EffectiveValues = withDefaults(userValues, profile)
+			result.Approximate = true
+			result.ProfileDefaultsSource = sail.ProfileDefaultsSource
+			addNote("Profile defaults are an approximation of the operator's built-in profiles, taken from the %s; the IstioRevision values are what the operator rendered", sail.ProfileDefaultsSource)
+		}
+
+		// Collect the revisions owned by this Istio plus any requested explicitly
+		revisionList, err := dynamicClient.Resource(revisionGVR).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errorResult("Error listing IstioRevisions: %v", err)
+		}
+		revisions := make(map[string]types.RevisionValues)
+		for i := range revisionList.Items {
+			item := &revisionList.Items[i]
+			owned := isOwnedBy(item, "Istio", name)
+			if !owned && item.GetName() != args.FromRevision && item.GetName() != args.ToRevision {
+				continue
+			}
+			rv, err := revisionValues(item)
+			if err != nil {
+				addNote("%v", err)
+				continue
+			}
+			rv.Active = rv.Name == istio.Status.ActiveRevisionName
+			revisions[rv.Name] = rv
+			if owned {
+				result.Revisions = append(result.Revisions, rv)
+			}
+		}
+
+		compare := func(fromLabel, toLabel string, from, to map[string]interface{}) {
+			result.Comparisons = append(result.Comparisons, types.ValuesComparison{
+				From:        fromLabel,
+				To:          toLabel,
+				Approximate: args.IncludeProfileDefaults,
+				Diffs:       sail.DiffValues(from, to),
+			})
+		}
+
+		active := istio.Status.ActiveRevisionName
+		if rv, ok := revisions[active]; ok {
+			compare(fmt.Sprintf("Istio %s", name), fmt.Sprintf("IstioRevision %s", active),
+				withDefaults(userValues, profile), withDefaults(rv.Values, profile))
+		} else if active != "" {
+			addNote("Active revision '%s' was not found", active)
+		} else {
+			addNote("Istio has no active revision yet")
+		}
+
+		if args.FromRevision != "" || args.ToRevision != "" {
+			from, to := args.FromRevision, args.ToRevision
+			if from == "" {
+				from = active
+			}
+			if to == "" {
+				to = active
+			}
+			fromValues, fromOK := revisions[from]
+			toValues, toOK := revisions[to]
+			switch {
+			case from == "" || to == "":
+				addNote("Both revisions must be set when the Istio has no active revision")
+			case !fromOK:
+				addNote("IstioRevision '%s' not found", from)
+			case !toOK:
+				addNote("IstioRevision '%s' not found", to)
+			default:
+				compare(fmt.Sprintf("IstioRevision %s (%s)", from, fromValues.Version), fmt.Sprintf("IstioRevision %s (%s)", to, toValues.Version),
+					withDefaults(fromValues.Values, profile), withDefaults(toValues.Values, profile))
+			}
+		}
+
+		output := formatIstioValues(result, notes)
+		return &mcp.CallToolResultFor[types.GetIstioValuesResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+// revisionValues extracts the version and values of an IstioRevision
+func revisionValues(u *unstructured.Unstructured) (types.RevisionValues, error) {
+	rev, err := sail.Decode[sail.IstioRevision](u)
+	if err != nil {
+		return types.RevisionValues{}, fmt.Errorf("error decoding IstioRevision '%s': %v", u.GetName(), err)
+	}
+	rv := types.RevisionValues{
+		Name:    rev.Name,
+		Version: rev.Spec.Version,
+		Values:  map[string]interface{}{},
+	}
+	if rev.Spec.Values != nil && rev.Spec.Values.Raw != nil {
+		rv.Values = rev.Spec.Values.Raw
+	}
+	return rv, nil
+}
+
+// isOwnedBy reports whether a resource has an owner reference to the given kind and name
+func isOwnedBy(u *unstructured.Unstructured, kind, name string) bool {
+	for _, ref := range u.GetOwnerReferences() {
+		if ref.Kind == kind && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatIstioValues formats the values report
+func formatIstioValues(result types.GetIstioValuesResult, notes []string) string {
+	output := fmt.Sprintf("=== Istio Values: %s ===\n", result.Name)
+	output += fmt.Sprintf("Version: %s\n", result.Version)
+	output += fmt.Sprintf("Profile: %s\n", result.Profile)
+
+	output += "\nUser values (spec.values):\n"
+	output += formatValuesTree(result.UserValues)
+
+	if result.EffectiveValues != nil {
+		output += fmt.Sprintf("\nEffective values (approximate '%s' profile defaults + user values):\n", result.Profile)
+		output += formatValuesTree(result.EffectiveValues)
+	}
+
+	if len(result.Revisions) > 0 {
+		output += "\nRevisions:\n"
+		for _, rev := range result.Revisions {
+			marker := ""
+			if rev.Active {
+				marker = " (active)"
+			}
+			output += fmt.Sprintf("  • %s%s - Version: %s\n", rev.Name, marker, rev.Version)
+		}
+	}
+
+	for _, cmp := range result.Comparisons {
+		output += fmt.Sprintf("\nDiff: %s -> %s", cmp.From, cmp.To)
+		if cmp.Approximate {
+			output += " (approximate, with profile defaults)"
+		}
+		output += "\n"
+		if len(cmp.Diffs) == 0 {
+			output += "  (no differences)\n"
+			continue
+		}
+		for _, d := range cmp.Diffs {
+			switch d.Change {
+			case "added":
+				output += fmt.Sprintf("  + %s: %s\n", d.Path, sail.FormatValue(d.To))
+			case "removed":
+				output += fmt.Sprintf("  - %s: %s\n", d.Path, sail.FormatValue(d.From))
+			default:
+				output += fmt.Sprintf("  ~ %s: %s -> %s\n", d.Path, sail.FormatValue(d.From), sail.FormatValue(d.To))
+			}
+		}
+	}
+
+	if len(notes) > 0 {
+		output += "\nNotes:\n"
+		for _, note := range notes {
+			output += fmt.Sprintf("  • %s\n", note)
+		}
+	}
+
+	return output
+}
+
+// formatValuesTree renders a values tree as indented YAML
+func formatValuesTree(values map[string]interface{}) string {
+	if len(values) == 0 {
+		return "  (none)\n"
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Sprintf("  (failed to render values: %v)\n", err)
+	}
+	var output string
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		output += "  " + line + "\n"
+	}
+	return output
+}
@@ -0,0 +1,550 @@
+package sailoperator
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// Name prefixes of the webhook configurations rendered by the istiod chart
+var (
+	injectorConfigPrefixes  = []string{"istio-sidecar-injector", "istio-revision-tag-"}
+	validatorConfigPrefixes = []string{"istio-validator", "istiod-default-validator"}
+)
+
+// caExpiryWarning is how close to expiry a webhook CA bundle is reported
+const caExpiryWarning = 30 * 24 * time.Hour
+
+// webhookEntry is a webhook of either configuration type with the fields the checks need
+type webhookEntry struct {
+	info              types.WebhookInfo
+	clientConfig      admissionregistrationv1.WebhookClientConfig
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+	matchesPods       bool
+}
+
+// CheckInjectionWebhooks inspects the sidecar injector and validation webhook
+// configurations of every revision
+func CheckInjectionWebhooks(k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckInjectionWebhooksParams]) (*mcp.CallToolResultFor[types.CheckInjectionWebhooksResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CheckInjectionWebhooksParams]) (*mcp.CallToolResultFor[types.CheckInjectionWebhooksResult], error) {
+		errorResult := func(format string, a ...interface{}) (*mcp.CallToolResultFor[types.CheckInjectionWebhooksResult], error) {
+			return &mcp.CallToolResultFor[types.CheckInjectionWebhooksResult]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf(format, a...)}},
+			}, nil
+		}
+
+		result := types.CheckInjectionWebhooksResult{Status: "success"}
+
+		revisions, tags, err := listRevisionsAndTags(ctx, dynamicClient, registry)
+		if err != nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("Could not read IstioRevisions and tags: %v", err))
+		}
+
+		var entries []*webhookEntry
+
+		mutating, err := k8sClient.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errorResult("Error listing mutating webhook configurations: %v", err)
+		}
+		for _, config := range mutating.Items {
+			if !hasAnyPrefix(config.Name, injectorConfigPrefixes) {
+				continue
+			}
+			for _, wh := range config.Webhooks {
+				entries = append(entries, newWebhookEntry(config.ObjectMeta, "mutating", wh.Name, wh.ClientConfig,
+					wh.FailurePolicy, wh.NamespaceSelector, wh.ObjectSelector, wh.Rules))
+			}
+		}
+
+		validating, err := k8sClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errorResult("Error listing validating webhook configurations: %v", err)
+		}
+		for _, config := range validating.Items {
+			if !hasAnyPrefix(config.Name, validatorConfigPrefixes) {
+				continue
+			}
+			for _, wh := range config.Webhooks {
+				entries = append(entries, newWebhookEntry(config.ObjectMeta, "validating", wh.Name, wh.ClientConfig,
+					wh.FailurePolicy, wh.NamespaceSelector, wh.ObjectSelector, wh.Rules))
+			}
+		}
+
+		if filter := params.Arguments.Revision; filter != "" {
+			var filtered []*webhookEntry
+			for _, e := range entries {
+				if e.info.Revision == filter || e.info.Tag == filter {
+					filtered = append(filtered, e)
+				}
+			}
+			entries = filtered
+		}
+
+		namespaces, err := k8sClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errorResult("Error listing namespaces: %v", err)
+		}
+
+		readyEndpoints := make(map[string]int)
+		now := time.Now()
+		for _, e := range entries {
+			mapWebhookToRevision(e, revisions, tags)
+			checkWebhookService(ctx, k8sClient, e, readyEndpoints)
+			checkWebhookCABundle(e, now)
+		}
+
+		// Evaluate namespace selectors against the real namespaces
+		matchedBy := make(map[string][]*webhookEntry)
+		for _, e := range entries {
+			selector, err := selectorOrEverything(e.namespaceSelector)
+			if err != nil {
+				e.info.Issues = append(e.info.Issues, fmt.Sprintf("invalid namespace selector: %v", err))
+				continue
+			}
+			for _, ns := range namespaces.Items {
+				if selector.Matches(labels.Set(ns.Labels)) {
+					e.info.MatchingNamespaces++
+					if e.info.Type == "mutating" && e.matchesPods {
+						matchedBy[ns.Name] = append(matchedBy[ns.Name], e)
+					}
+				}
+			}
+		}
+		result.Overlaps = findWebhookOverlaps(matchedBy)
+		for _, o := range result.Overlaps {
+			result.Issues = append(result.Issues, fmt.Sprintf("Pods in %d namespace(s) may be injected twice by %s", len(o.Namespaces), strings.Join(o.Webhooks, " and ")))
+		}
+
+		// Revisions without an injector are a common cause of pods starting without a sidecar
+		if params.Arguments.Revision == "" {
+			for _, rev := range revisions {
+				found := false
+				for _, e := range entries {
+					if e.info.Type == "mutating" && e.info.Revision == rev {
+						found = true
+						break
+					}
+				}
+				if !found {
+					result.Issues = append(result.Issues, fmt.Sprintf("IstioRevision %s has no sidecar injector webhook configuration", rev))
+				}
+			}
+		}
+
+		for _, e := range entries {
+			result.Webhooks = append(result.Webhooks, e.info)
+			for _, issue := range e.info.Issues {
+				result.Issues = append(result.Issues, fmt.Sprintf("%s/%s: %s", e.info.Configuration, e.info.Name, issue))
+			}
+		}
+
+		output := formatInjectionWebhooks(result)
+		return &mcp.CallToolResultFor[types.CheckInjectionWebhooksResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output},
+				&mcp.TextContent{Text: toJSONString(result)},
+			},
+		}, nil
+	}
+}
+
+func newWebhookEntry(config metav1.ObjectMeta, webhookType, name string, clientConfig admissionregistrationv1.WebhookClientConfig,
+	failurePolicy *admissionregistrationv1.FailurePolicyType, namespaceSelector, objectSelector *metav1.LabelSelector,
+	rules []admissionregistrationv1.RuleWithOperations) *webhookEntry {
+	e := &webhookEntry{
+		info: types.WebhookInfo{
+			Configuration: config.Name,
+			Type:          webhookType,
+			Name:          name,
+			Revision:      config.Labels["istio.io/rev"],
+			Tag:           config.Labels["istio.io/tag"],
+		},
+		clientConfig:      clientConfig,
+		namespaceSelector: namespaceSelector,
+		objectSelector:    objectSelector,
+	}
+	if failurePolicy != nil {
+		e.info.FailurePolicy = string(*failurePolicy)
+	}
+	if namespaceSelector != nil {
+		e.info.NamespaceSelector = metav1.FormatLabelSelector(namespaceSelector)
+	}
+	if objectSelector != nil {
+		e.info.ObjectSelector = metav1.FormatLabelSelector(objectSelector)
+	}
+	for _, rule := range rules {
+		for _, resource := range rule.Resources {
+			if resource == "pods" || resource == "*" {
+				e.matchesPods = true
+			}
+		}
+	}
+	return e
+}
+
+// listRevisionsAndTags returns the IstioRevision names and a map from
+// IstioRevisionTag name to the revision it points at
+func listRevisionsAndTags(ctx context.Context, dynamicClient dynamic.Interface, registry *sail.Registry) ([]string, map[string]string, error) {
+	tags := make(map[string]string)
+
+	revisionGVR, err := registry.GVR(ctx, sail.KindIstioRevision)
+	if err != nil {
+		return nil, tags, err
+	}
+	list, err := dynamicClient.Resource(revisionGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, tags, err
+	}
+	revisions := []string{}
+	for _, item := range list.Items {
+		revisions = append(revisions, item.GetName())
+	}
+	sort.Strings(revisions)
+
+	tagGVR, err := registry.GVR(ctx, sail.KindIstioRevisionTag)
+	if err != nil {
+		if sail.IsNotInstalled(err) {
+			return revisions, tags, nil
+		}
+		return revisions, tags, err
+	}
+	tagList, err := dynamicClient.Resource(tagGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return revisions, tags, err
+	}
+	for i := range tagList.Items {
+		tag, err := sail.Decode[sail.IstioRevisionTag](&tagList.Items[i])
+		if err != nil {
+			continue
+		}
+		tags[tag.Name] = tag.Status.IstioRevision
+	}
+	return revisions, tags, nil
+}
+
+// mapWebhookToRevision links a webhook to the IstioRevision or tag it was rendered for
+func mapWebhookToRevision(e *webhookEntry, revisions []string, tags map[string]string) {
+	if e.info.Tag != "" {
+		target, ok := tags[e.info.Tag]
+		switch {
+		case !ok:
+			e.info.Issues = append(e.info.Issues, fmt.Sprintf("IstioRevisionTag %s does not exist; the webhook configuration may be left over", e.info.Tag))
+		case target == "":
+			e.info.MappedTo = fmt.Sprintf("IstioRevisionTag %s", e.info.Tag)
+		default:
+			e.info.MappedTo = fmt.Sprintf("IstioRevisionTag %s -> IstioRevision %s", e.info.Tag, target)
+		}
+		return
+	}
+	if e.info.Revision == "" {
+		return
+	}
+	for _, rev := range revisions {
+		if rev == e.info.Revision {
+			e.info.MappedTo = fmt.Sprintf("IstioRevision %s", rev)
+			return
+		}
+	}
+	if revisions != nil { // nil when the revisions could not be listed
+		e.info.Issues = append(e.info.Issues, fmt.Sprintf("IstioRevision %s does not exist; the webhook configuration may be left over", e.info.Revision))
+	}
+}
+
+// checkWebhookService counts the ready endpoints behind the webhook service.
+// Counts are cached per service since every webhook of a revision uses istiod.
+func checkWebhookService(ctx context.Context, k8sClient *kubernetes.Clientset, e *webhookEntry, cache map[string]int) {
+	if e.clientConfig.URL != nil {
+		e.info.URL = *e.clientConfig.URL
+		return
+	}
+	svc := e.clientConfig.Service
+	if svc == nil {
+		e.info.Issues = append(e.info.Issues, "webhook has neither a service nor a URL")
+		return
+	}
+	port := int32(443)
+	if svc.Port != nil {
+		port = *svc.Port
+	}
+	e.info.Service = fmt.Sprintf("%s/%s:%d", svc.Namespace, svc.Name, port)
+
+	key := svc.Namespace + "/" + svc.Name
+	ready, ok := cache[key]
+	if !ok {
+		slices, err := k8sClient.DiscoveryV1().EndpointSlices(svc.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, svc.Name),
+		})
+		if err != nil {
+			e.info.Issues = append(e.info.Issues, fmt.Sprintf("failed to read endpoints of service %s: %v", key, err))
+			return
+		}
+		for _, slice := range slices.Items {
+			for _, ep := range slice.Endpoints {
+				if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+					ready++
+				}
+			}
+		}
+		cache[key] = ready
+	}
+	e.info.ReadyEndpoints = ready
+
+	if ready == 0 {
+		issue := fmt.Sprintf("service %s has no ready endpoints", key)
+		if e.info.FailurePolicy == string(admissionregistrationv1.Fail) {
+			issue += "; requests matched by this webhook will be rejected"
+		} else {
+			issue += "; requests matched by this webhook are let through unmodified"
+		}
+		e.info.Issues = append(e.info.Issues, issue)
+	}
+}
+
+// checkWebhookCABundle decodes the CA bundle and records the earliest certificate expiry
+func checkWebhookCABundle(e *webhookEntry, now time.Time) {
+	bundle := e.clientConfig.CABundle
+	if len(bundle) == 0 {
+		e.info.Issues = append(e.info.Issues, "caBundle is empty; istiod has not patched the webhook yet")
+		return
+	}
+
+	var earliest *x509.Certificate
+	for rest := bundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			e.info.Issues = append(e.info.Issues, fmt.Sprintf("caBundle contains an invalid certificate: %v", err))
+			continue
+		}
+		if earliest == nil || cert.NotAfter.Before(earliest.NotAfter) {
+			earliest = cert
+		}
+	}
+	if earliest == nil {
+		e.info.Issues = append(e.info.Issues, "caBundle contains no PEM certificates")
+		return
+	}
+
+	e.info.CAExpiry = earliest.NotAfter.UTC().Format(time.RFC3339)
+	daysLeft := int(earliest.NotAfter.Sub(now).Hours() / 24)
+	e.info.CADaysLeft = &daysLeft
+	switch {
+	case now.After(earliest.NotAfter):
+		e.info.Issues = append(e.info.Issues, fmt.Sprintf("caBundle certificate %q expired on %s", earliest.Subject.CommonName, e.info.CAExpiry))
+	case earliest.NotAfter.Sub(now) < caExpiryWarning:
+		e.info.Issues = append(e.info.Issues, fmt.Sprintf("caBundle certificate %q expires in %d days", earliest.Subject.CommonName, daysLeft))
+	}
+}
+
+// findWebhookOverlaps reports pairs of pod-mutating webhooks whose namespace
+// selectors both match a namespace and whose object selectors can be
+// satisfied by the same pod
+func findWebhookOverlaps(matchedBy map[string][]*webhookEntry) []types.WebhookOverlap {
+	overlaps := make(map[string]*types.WebhookOverlap)
+	for ns, matched := range matchedBy {
+		for i := 0; i < len(matched); i++ {
+			for j := i + 1; j < len(matched); j++ {
+				a, b := matched[i], matched[j]
+				if selectorsContradict(a.objectSelector, b.objectSelector) {
+					continue
+				}
+				names := []string{webhookID(a), webhookID(b)}
+				sort.Strings(names)
+				key := strings.Join(names, "|")
+				if overlaps[key] == nil {
+					overlaps[key] = &types.WebhookOverlap{Webhooks: names}
+				}
+				overlaps[key].Namespaces = append(overlaps[key].Namespaces, ns)
+			}
+		}
+	}
+
+	var result []types.WebhookOverlap
+	for _, o := range overlaps {
+		sort.Strings(o.Namespaces)
+		result = append(result, *o)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Join(result[i].Webhooks, "|") < strings.Join(result[j].Webhooks, "|")
+	})
+	return result
+}
+
+func webhookID(e *webhookEntry) string {
+	return e.info.Configuration + "/" + e.info.Name
+}
+
+// selectorsContradict reports whether no label set can match both selectors
+func selectorsContradict(a, b *metav1.LabelSelector) bool {
+	ra, rb := selectorRequirements(a), selectorRequirements(b)
+	for _, x := range ra {
+		for _, y := range rb {
+			if x.Key == y.Key && requirementsContradict(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectorRequirements flattens matchLabels into In requirements
+func selectorRequirements(s *metav1.LabelSelector) []metav1.LabelSelectorRequirement {
+	if s == nil {
+		return nil
+	}
+	reqs := append([]metav1.LabelSelectorRequirement{}, s.MatchExpressions...)
+	for k, v := range s.MatchLabels {
+		reqs = append(reqs, metav1.LabelSelectorRequirement{Key: k, Operator: metav1.LabelSelectorOpIn, Values: []string{v}})
+	}
+	return reqs
+}
+
+func requirementsContradict(x, y metav1.LabelSelectorRequirement) bool {
+	requiresValue := func(r metav1.LabelSelectorRequirement) bool {
+		return r.Operator == metav1.LabelSelectorOpIn || r.Operator == metav1.LabelSelectorOpExists
+	}
+	switch {
+	case x.Operator == metav1.LabelSelectorOpDoesNotExist:
+		return requiresValue(y)
+	case y.Operator == metav1.LabelSelectorOpDoesNotExist:
+		return requiresValue(x)
+	case x.Operator == metav1.LabelSelectorOpIn && y.Operator == metav1.LabelSelectorOpIn:
+		return !intersects(x.Values, y.Values)
+	case x.Operator == metav1.LabelSelectorOpIn && y.Operator == metav1.LabelSelectorOpNotIn:
+		return isSubset(x.Values, y.Values)
+	case x.Operator == metav1.LabelSelectorOpNotIn && y.Operator == metav1.LabelSelectorOpIn:
+		return isSubset(y.Values, x.Values)
+	}
+	return false
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isSubset(a, b []string) bool {
+	for _, x := range a {
+		if !intersects([]string{x}, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectorOrEverything converts a label selector, treating nil as matching everything
+func selectorOrEverything(s *metav1.LabelSelector) (labels.Selector, error) {
+	if s == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(s)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatInjectionWebhooks formats the webhook report
+func formatInjectionWebhooks(result types.CheckInjectionWebhooksResult) string {
+	if len(result.Webhooks) == 0 {
+		output := "No Istio webhook configurations found"
+		if len(result.Issues) > 0 {
+			output += "\n\nIssues:\n"
+			for _, issue := range result.Issues {
+				output += fmt.Sprintf("  • %s\n", issue)
+			}
+		}
+		return output
+	}
+
+	output := "=== Istio Webhooks ===\n"
+	currentConfig := ""
+	for _, wh := range result.Webhooks {
+		if wh.Configuration != currentConfig {
+			currentConfig = wh.Configuration
+			output += fmt.Sprintf("\n%s (%s)", wh.Configuration, wh.Type)
+			if wh.MappedTo != "" {
+				output += fmt.Sprintf(" -> %s", wh.MappedTo)
+			} else if wh.Revision == "" && wh.Tag == "" {
+				output += " -> no revision label"
+			}
+			output += "\n"
+		}
+
+		emoji := "✅"
+		if len(wh.Issues) > 0 {
+			emoji = "❌"
+		}
+		output += fmt.Sprintf("  %s %s\n", emoji, wh.Name)
+		if wh.Service != "" {
+			output += fmt.Sprintf("      Service: %s (%d ready endpoints)\n", wh.Service, wh.ReadyEndpoints)
+		} else if wh.URL != "" {
+			output += fmt.Sprintf("      URL: %s\n", wh.URL)
+		}
+		if wh.CAExpiry != "" {
+			output += fmt.Sprintf("      CA expires: %s (%d days)\n", wh.CAExpiry, *wh.CADaysLeft)
+		}
+		if wh.FailurePolicy != "" {
+			output += fmt.Sprintf("      Failure policy: %s\n", wh.FailurePolicy)
+		}
+		if wh.NamespaceSelector != "" {
+			output += fmt.Sprintf("      Namespace selector: %s (%d namespaces)\n", wh.NamespaceSelector, wh.MatchingNamespaces)
+		}
+		if wh.ObjectSelector != "" {
+			output += fmt.Sprintf("      Object selector: %s\n", wh.ObjectSelector)
+		}
+	}
+
+	if len(result.Overlaps) > 0 {
+		output += "\nOverlapping injection webhooks:\n"
+		for _, o := range result.Overlaps {
+			output += fmt.Sprintf("  ⚠️ %s\n", strings.Join(o.Webhooks, " + "))
+			output += fmt.Sprintf("      namespaces: %s\n", strings.Join(o.Namespaces, ", "))
+		}
+	}
+
+	if len(result.Issues) > 0 {
+		output += "\nIssues:\n"
+		for _, issue := range result.Issues {
+			output += fmt.Sprintf("  • %s\n", issue)
+		}
+	} else {
+		output += "\nNo webhook issues found\n"
+	}
+
+	return output
+}
@@ -0,0 +1,527 @@
+package sailoperator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/audit"
+	"github.com/frherrer/mcp-sail-operator/pkg/mcpext"
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// confirmationTimeout bounds how long a write waits for the user to answer
+const confirmationTimeout = 5 * time.Minute
+
+// maxListedChanges is how many objects the confirmation prompt lists by name
+const maxListedChanges = 20
+
+// workloadGVRs are the workload kinds restart_workloads_for_revision restarts
+var workloadGVRs = []struct {
+	kind string
+	gvr  schema.GroupVersionResource
+}{
+	{"Deployment", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}},
+	{"StatefulSet", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}},
+	{"DaemonSet", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}},
+}
+
+// WriteGuard makes every write go through a server-side dry-run, an explicit
+// user confirmation via MCP elicitation and the audit log
+type WriteGuard struct {
+	Elicitor mcpext.Elicitor
+	Audit    *audit.Logger
+}
+
+// plannedWrite is a merge patch of a single object
+type plannedWrite struct {
+	gvr       schema.GroupVersionResource
+	kind      string
+	namespace string
+	name      string
+	patch     []byte
+	// resourceVersion is the version the dry-run diff was computed from
+	resourceVersion string
+}
+
+func (w plannedWrite) target() string {
+	if w.namespace == "" {
+		return fmt.Sprintf("%s/%s", w.kind, w.name)
+	}
+	return fmt.Sprintf("%s/%s/%s", w.kind, w.namespace, w.name)
+}
+
+// preconditioned returns the patch with metadata.resourceVersion set to the
+// version the user confirmed, so the API server rejects it with a conflict
+// when the object changed in the meantime
+func (w plannedWrite) preconditioned() ([]byte, error) {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(w.patch, &patch); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(patch, w.resourceVersion, "metadata", "resourceVersion"); err != nil {
+		return nil, err
+	}
+	return json.Marshal(patch)
+}
+
+func (w plannedWrite) resource(dynamicClient dynamic.Interface) dynamic.ResourceInterface {
+	if w.namespace == "" {
+		return dynamicClient.Resource(w.gvr)
+	}
+	return dynamicClient.Resource(w.gvr).Namespace(w.namespace)
+}
+
+// PatchIstioVersion changes spec.version of an Istio resource
+func PatchIstioVersion(dynamicClient dynamic.Interface, registry *sail.Registry, guard *WriteGuard) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.PatchIstioVersionParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.PatchIstioVersionParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+		args := params.Arguments
+		if args.Version == "" {
+			return guard.reject("patch_istio_version", args, "version is required")
+		}
+		name := args.Name
+		if name == "" {
+			name = "default"
+		}
+
+		gvr, err := registry.GVR(ctx, sail.KindIstio)
+		if err != nil {
+			return guard.reject("patch_istio_version", args, "Error resolving Istio API version: %v", err)
+		}
+		patch, _ := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{"version": args.Version},
+		})
+
+		return guard.run(ctx, dynamicClient, "patch_istio_version", args, []plannedWrite{
+			{gvr: gvr, kind: sail.KindIstio.Kind, name: name, patch: patch},
+		})
+	}
+}
+
+// SetUpdateStrategy changes spec.updateStrategy of an Istio resource
+func SetUpdateStrategy(dynamicClient dynamic.Interface, registry *sail.Registry, guard *WriteGuard) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.SetUpdateStrategyParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.SetUpdateStrategyParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+		args := params.Arguments
+		if args.Type != "InPlace" && args.Type != "RevisionBased" {
+			return guard.reject("set_update_strategy", args, "type must be InPlace or RevisionBased, got '%s'", args.Type)
+		}
+		name := args.Name
+		if name == "" {
+			name = "default"
+		}
+
+		gvr, err := registry.GVR(ctx, sail.KindIstio)
+		if err != nil {
+			return guard.reject("set_update_strategy", args, "Error resolving Istio API version: %v", err)
+		}
+		strategy := map[string]interface{}{"type": args.Type}
+		if args.InactiveRevisionDeletionGracePeriodSeconds != nil {
+			strategy["inactiveRevisionDeletionGracePeriodSeconds"] = *args.InactiveRevisionDeletionGracePeriodSeconds
+		}
+		if args.UpdateWorkloads != nil {
+			strategy["updateWorkloads"] = *args.UpdateWorkloads
+		}
+		patch, _ := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{"updateStrategy": strategy},
+		})
+
+		return guard.run(ctx, dynamicClient, "set_update_strategy", args, []plannedWrite{
+			{gvr: gvr, kind: sail.KindIstio.Kind, name: name, patch: patch},
+		})
+	}
+}
+
+// SwitchRevisionTag points an IstioRevisionTag at another Istio or IstioRevision
+func SwitchRevisionTag(dynamicClient dynamic.Interface, registry *sail.Registry, guard *WriteGuard) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.SwitchRevisionTagParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.SwitchRevisionTagParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+		args := params.Arguments
+		if args.Tag == "" || args.TargetName == "" {
+			return guard.reject("switch_revision_tag", args, "tag and target_name are required")
+		}
+		targetKind := args.TargetKind
+		if targetKind == "" {
+			targetKind = sail.KindIstio.Kind
+		}
+		target, ok := sail.LookupKind(targetKind)
+		if !ok || (target != sail.KindIstio && target != sail.KindIstioRevision) {
+			return guard.reject("switch_revision_tag", args, "target_kind must be Istio or IstioRevision, got '%s'", targetKind)
+		}
+
+		// The dry-run does not check that the target exists, so do it here
+		targetGVR, err := registry.GVR(ctx, target)
+		if err != nil {
+			return guard.reject("switch_revision_tag", args, "Error resolving %s API version: %v", target.Kind, err)
+		}
+		if _, err := dynamicClient.Resource(targetGVR).Get(ctx, args.TargetName, metav1.GetOptions{}); err != nil {
+			return guard.reject("switch_revision_tag", args, "Error getting %s '%s': %v", target.Kind, args.TargetName, err)
+		}
+
+		tagGVR, err := registry.GVR(ctx, sail.KindIstioRevisionTag)
+		if err != nil {
+			return guard.reject("switch_revision_tag", args, "Error resolving IstioRevisionTag API version: %v", err)
+		}
+		patch, _ := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"targetRef": map[string]interface{}{"kind": target.Kind, "name": args.TargetName},
+			},
+		})
+
+		return guard.run(ctx, dynamicClient, "switch_revision_tag", args, []plannedWrite{
+			{gvr: tagGVR, kind: sail.KindIstioRevisionTag.Kind, name: args.Tag, patch: patch},
+		})
+	}
+}
+
+// RestartWorkloadsForRevision restarts the Deployments, StatefulSets and
+// DaemonSets whose pods are injected by a revision or one of its tags, so they
+// pick up its proxy
+func RestartWorkloadsForRevision(dynamicClient dynamic.Interface, registry *sail.Registry, guard *WriteGuard) func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.RestartWorkloadsForRevisionParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+	return func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[types.RestartWorkloadsForRevisionParams]) (*mcp.CallToolResultFor[types.WriteResult], error) {
+		args := params.Arguments
+		if args.Revision == "" {
+			return guard.reject("restart_workloads_for_revision", args, "revision is required")
+		}
+
+		_, tags, err := listRevisionsAndTags(ctx, dynamicClient, registry)
+		if err != nil {
+			return guard.reject("restart_workloads_for_revision", args, "Error reading IstioRevisions and tags: %v", err)
+		}
+		revisionLabels := map[string]bool{args.Revision: true}
+		for tag, rev := range tags {
+			if rev == args.Revision {
+				revisionLabels[tag] = true
+			}
+		}
+
+		namespaces, err := namespaceLabels(ctx, dynamicClient)
+		if err != nil {
+			return guard.reject("restart_workloads_for_revision", args, "Error listing namespaces: %v", err)
+		}
+
+		restartedAt := time.Now().UTC().Format(time.RFC3339)
+		patch, _ := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"annotations": map[string]interface{}{"kubectl.kubernetes.io/restartedAt": restartedAt},
+					},
+				},
+			},
+		})
+
+		var writes []plannedWrite
+		for _, w := range workloadGVRs {
+			list, err := dynamicClient.Resource(w.gvr).Namespace(args.Namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return guard.reject("restart_workloads_for_revision", args, "Error listing %ss: %v", w.kind, err)
+			}
+			for i := range list.Items {
+				item := &list.Items[i]
+				if workloadUsesRevision(item, namespaces[item.GetNamespace()], revisionLabels) {
+					writes = append(writes, plannedWrite{gvr: w.gvr, kind: w.kind, namespace: item.GetNamespace(), name: item.GetName(), patch: patch})
+				}
+			}
+		}
+		if len(writes) == 0 {
+			return guard.reject("restart_workloads_for_revision", args, "No workloads found that are injected by revision '%s'", args.Revision)
+		}
+
+		return guard.run(ctx, dynamicClient, "restart_workloads_for_revision", args, writes)
+	}
+}
+
+// namespaceLabels returns the labels of every namespace by name
+func namespaceLabels(ctx context.Context, dynamicClient dynamic.Interface) (map[string]map[string]string, error) {
+	list, err := dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]map[string]string, len(list.Items))
+	for _, ns := range list.Items {
+		labels[ns.GetName()] = ns.GetLabels()
+	}
+	return labels, nil
+}
+
+// workloadUsesRevision reports whether a workload's pods are injected by one
+// of the revision labels
+func workloadUsesRevision(u *unstructured.Unstructured, nsLabels map[string]string, revisionLabels map[string]bool) bool {
+	podLabels, _, _ := unstructured.NestedStringMap(u.Object, "spec", "template", "metadata", "labels")
+	podAnnotations, _, _ := unstructured.NestedStringMap(u.Object, "spec", "template", "metadata", "annotations")
+	if podAnnotations["sidecar.istio.io/inject"] == "false" {
+		return false
+	}
+	rev := injectingRevision(nsLabels, podLabels)
+	return rev != "" && revisionLabels[rev]
+}
+
+// injectingRevision returns the revision or tag whose webhook injects a pod
+// with podLabels in a namespace with nsLabels, or "" when none does. It
+// follows the selectors of Istio's webhooks: istio-injection, whatever its
+// value, excludes the namespace from every revision webhook; otherwise the
+// namespace's istio.io/rev wins, and pod labels only count in namespaces
+// with neither label.
+func injectingRevision(nsLabels, podLabels map[string]string) string {
+	if podLabels["sidecar.istio.io/inject"] == "false" {
+		return ""
+	}
+	if injection, ok := nsLabels["istio-injection"]; ok {
+		if injection == "enabled" {
+			return "default"
+		}
+		return ""
+	}
+	if rev, ok := nsLabels["istio.io/rev"]; ok {
+		return rev
+	}
+	if rev, ok := podLabels["istio.io/rev"]; ok {
+		return rev
+	}
+	if podLabels["sidecar.istio.io/inject"] == "true" {
+		return "default"
+	}
+	return ""
+}
+
+// run dry-runs the planned writes, asks the user to confirm the resulting
+// changes and applies them. Every outcome is recorded in the audit log.
+func (g *WriteGuard) run(ctx context.Context, dynamicClient dynamic.Interface, tool string, params interface{}, writes []plannedWrite) (*mcp.CallToolResultFor[types.WriteResult], error) {
+	result := types.WriteResult{Status: "success"}
+
+	// Server-side dry-run of every change first
+	var pending []plannedWrite
+	dryRunFailed := false
+	for _, w := range writes {
+		change := types.PlannedChange{Kind: w.kind, Namespace: w.namespace, Name: w.name}
+		res := w.resource(dynamicClient)
+		current, err := res.Get(ctx, w.name, metav1.GetOptions{})
+		if err != nil {
+			change.Error = fmt.Sprintf("failed to get %s: %v", w.target(), err)
+			dryRunFailed = true
+		} else if dryRun, err := res.Patch(ctx, w.name, k8stypes.MergePatchType, w.patch, metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+			change.Error = fmt.Sprintf("dry-run rejected: %v", err)
+			dryRunFailed = true
+		} else {
+			w.resourceVersion = current.GetResourceVersion()
+			before, _, _ := unstructured.NestedMap(current.Object, "spec")
+			after, _, _ := unstructured.NestedMap(dryRun.Object, "spec")
+			change.Diffs = sail.DiffValues(before, after)
+			if len(change.Diffs) > 0 {
+				pending = append(pending, w)
+			}
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	switch {
+	case dryRunFailed:
+		result.Outcome = "dry-run-failed"
+	case len(pending) == 0:
+		result.Outcome = "no-op"
+	case g.Elicitor == nil || !g.Elicitor.SupportsElicitation():
+		result.Outcome = "refused"
+		result.Error = "the MCP client does not support elicitation, so the change cannot be confirmed; no changes were made"
+	default:
+		result.Outcome = g.confirmAndApply(ctx, dynamicClient, tool, pending, &result)
+	}
+
+	g.record(tool, params, writes, result)
+
+	return &mcp.CallToolResultFor[types.WriteResult]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatWriteResult(tool, result)},
+			&mcp.TextContent{Text: toJSONString(result)},
+		},
+	}, nil
+}
+
+// confirmAndApply asks the user to confirm and applies the pending writes,
+// returning the outcome
+func (g *WriteGuard) confirmAndApply(ctx context.Context, dynamicClient dynamic.Interface, tool string, pending []plannedWrite, result *types.WriteResult) string {
+	confirmCtx, cancel := context.WithTimeout(ctx, confirmationTimeout)
+	defer cancel()
+
+	answer, err := g.Elicitor.Elicit(confirmCtx, &mcpext.ElicitParams{
+		Message: confirmationMessage(tool, result.Changes),
+		RequestedSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"confirm": map[string]any{
+					"type":        "boolean",
+					"title":       "Apply these changes",
+					"description": "Apply the changes shown above to the cluster",
+				},
+			},
+			"required": []string{"confirm"},
+		},
+	})
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		result.Error = "no confirmation received in time; no changes were made"
+		return "cancelled"
+	case err != nil:
+		result.Error = fmt.Sprintf("confirmation failed: %v; no changes were made", err)
+		return "cancelled"
+	case answer.Action == "decline":
+		return "declined"
+	case answer.Action != "accept":
+		return "cancelled"
+	}
+	if confirmed, _ := answer.Content["confirm"].(bool); !confirmed {
+		return "declined"
+	}
+
+	failed := 0
+	for _, w := range pending {
+		patch, err := w.preconditioned()
+		if err == nil {
+			_, err = w.resource(dynamicClient).Patch(ctx, w.name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+		}
+		if apierrors.IsConflict(err) {
+			err = fmt.Errorf("%s changed after the dry-run was confirmed; review the new state and try again", w.target())
+		}
+		if err != nil {
+			failed++
+			for i := range result.Changes {
+				c := &result.Changes[i]
+				if c.Kind == w.kind && c.Namespace == w.namespace && c.Name == w.name {
+					c.Error = fmt.Sprintf("apply failed: %v", err)
+				}
+			}
+		}
+	}
+	if failed > 0 {
+		result.Error = fmt.Sprintf("%d of %d changes failed to apply", failed, len(pending))
+		return "failed"
+	}
+	return "applied"
+}
+
+func (g *WriteGuard) record(tool string, params interface{}, writes []plannedWrite, result types.WriteResult) {
+	if g.Audit == nil {
+		return
+	}
+	var targets []string
+	for _, w := range writes {
+		targets = append(targets, w.target())
+	}
+	if err := g.Audit.Record(audit.Entry{
+		Tool:    tool,
+		Targets: targets,
+		Params:  params,
+		Changes: result.Changes,
+		Outcome: result.Outcome,
+		Error:   result.Error,
+	}); err != nil {
+		log.Printf("Warning: failed to write audit log entry for %s: %v", tool, err)
+	}
+}
+
+// confirmationMessage summarizes the changes for the user to confirm
+func confirmationMessage(tool string, changes []types.PlannedChange) string {
+	msg := fmt.Sprintf("%s will change %d object(s) (validated with a server-side dry-run):\n", tool, countChanged(changes))
+	listed := 0
+	for _, c := range changes {
+		if len(c.Diffs) == 0 {
+			continue
+		}
+		if listed == maxListedChanges {
+			msg += fmt.Sprintf("... and %d more\n", countChanged(changes)-listed)
+			break
+		}
+		listed++
+		msg += "\n" + formatPlannedChange(c)
+	}
+	return msg
+}
+
+func countChanged(changes []types.PlannedChange) int {
+	n := 0
+	for _, c := range changes {
+		if len(c.Diffs) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func formatPlannedChange(c types.PlannedChange) string {
+	target := c.Kind + " " + c.Name
+	if c.Namespace != "" {
+		target = fmt.Sprintf("%s %s/%s", c.Kind, c.Namespace, c.Name)
+	}
+	output := target + ":\n"
+	if c.Error != "" {
+		output += fmt.Sprintf("  ❌ %s\n", c.Error)
+	}
+	for _, d := range c.Diffs {
+		switch d.Change {
+		case "added":
+			output += fmt.Sprintf("  + spec.%s: %s\n", d.Path, sail.FormatValue(d.To))
+		case "removed":
+			output += fmt.Sprintf("  - spec.%s: %s\n", d.Path, sail.FormatValue(d.From))
+		default:
+			output += fmt.Sprintf("  ~ spec.%s: %s -> %s\n", d.Path, sail.FormatValue(d.From), sail.FormatValue(d.To))
+		}
+	}
+	return output
+}
+
+// formatWriteResult formats the outcome of a write operation
+func formatWriteResult(tool string, result types.WriteResult) string {
+	var summary string
+	switch result.Outcome {
+	case "applied":
+		summary = "✅ Changes applied"
+	case "declined":
+		summary = "🚫 Changes declined by the user; nothing was applied"
+	case "cancelled":
+		summary = "🚫 Confirmation cancelled; nothing was applied"
+	case "refused":
+		summary = "🚫 Write refused; nothing was applied"
+	case "no-op":
+		summary = "ℹ️ Nothing to change; the objects already match"
+	case "dry-run-failed":
+		summary = "❌ Dry-run failed; nothing was applied"
+	default:
+		summary = "❌ Some changes failed to apply"
+	}
+
+	output := fmt.Sprintf("=== %s ===\n%s\n", tool, summary)
+	if result.Error != "" {
+		output += fmt.Sprintf("%s\n", result.Error)
+	}
+	var parts []string
+	for _, c := range result.Changes {
+		if len(c.Diffs) > 0 || c.Error != "" {
+			parts = append(parts, formatPlannedChange(c))
+		}
+	}
+	if len(parts) > 0 {
+		output += "\n" + strings.Join(parts, "\n")
+	}
+	return output
+}
+
+// reject records a write refused before its dry-run, for invalid arguments
+// or a failed lookup, and returns the error to the caller
+func (g *WriteGuard) reject(tool string, params interface{}, format string, a ...interface{}) (*mcp.CallToolResultFor[types.WriteResult], error) {
+	msg := fmt.Sprintf(format, a...)
+	g.record(tool, params, nil, types.WriteResult{Outcome: "rejected", Error: msg})
+	return &mcp.CallToolResultFor[types.WriteResult]{
+		Content: []mcp.Content{&mcp.TextContent{Text: msg}},
+	}, nil
+}
@@ -0,0 +1,73 @@
+package sailoperator
+
+import "testing"
+
+func TestInjectingRevision(t *testing.T) {
+	tests := []struct {
+		name      string
+		nsLabels  map[string]string
+		podLabels map[string]string
+		want      string
+	}{
+		{
+			name:     "istio-injection enabled",
+			nsLabels: map[string]string{"istio-injection": "enabled"},
+			want:     "default",
+		},
+		{
+			name:     "istio-injection enabled wins over istio.io/rev",
+			nsLabels: map[string]string{"istio-injection": "enabled", "istio.io/rev": "canary"},
+			want:     "default",
+		},
+		{
+			name:     "istio-injection disabled with istio.io/rev",
+			nsLabels: map[string]string{"istio-injection": "disabled", "istio.io/rev": "canary"},
+			want:     "",
+		},
+		{
+			name:      "istio-injection disabled ignores the pod label",
+			nsLabels:  map[string]string{"istio-injection": "disabled"},
+			podLabels: map[string]string{"istio.io/rev": "canary"},
+			want:      "",
+		},
+		{
+			name:     "istio.io/rev only",
+			nsLabels: map[string]string{"istio.io/rev": "canary"},
+			want:     "canary",
+		},
+		{
+			name:      "namespace revision wins over the pod label",
+			nsLabels:  map[string]string{"istio.io/rev": "canary"},
+			podLabels: map[string]string{"istio.io/rev": "stable"},
+			want:      "canary",
+		},
+		{
+			name: "neither label",
+			want: "",
+		},
+		{
+			name:      "neither label with a pod revision",
+			podLabels: map[string]string{"istio.io/rev": "stable"},
+			want:      "stable",
+		},
+		{
+			name:      "neither label with pod opt-in",
+			podLabels: map[string]string{"sidecar.istio.io/inject": "true"},
+			want:      "default",
+		},
+		{
+			name:      "pod opt-out",
+			nsLabels:  map[string]string{"istio.io/rev": "canary"},
+			podLabels: map[string]string{"sidecar.istio.io/inject": "false"},
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := injectingRevision(tt.nsLabels, tt.podLabels); got != tt.want {
+				t.Errorf("injectingRevision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
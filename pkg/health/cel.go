@@ -0,0 +1,49 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// celProgram is a compiled CEL expression evaluated against an unstructured object
+type celProgram struct {
+	program cel.Program
+}
+
+// compileExpression compiles a boolean CEL expression. The resource is
+// available as the `object` variable, e.g.
+// `has(object.spec.values.pilot.resources)`.
+func compileExpression(expression string) (*celProgram, error) {
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile expression %q: %w", expression, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression %q must evaluate to a bool, not %s", expression, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build program for %q: %w", expression, err)
+	}
+	return &celProgram{program: program}, nil
+}
+
+// eval runs the program against the object and returns its boolean result
+func (p *celProgram) eval(object map[string]interface{}) (bool, error) {
+	out, _, err := p.program.Eval(map[string]interface{}{"object": object})
+	if err != nil {
+		return false, err
+	}
+	passed, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %T, not bool", out.Value())
+	}
+	return passed, nil
+}
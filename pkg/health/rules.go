@@ -0,0 +1,248 @@
+package health
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Severity describes how a failed rule affects the health of a resource
+type Severity string
+
+const (
+	// SeverityCritical marks the resource Unhealthy when the rule fails
+	SeverityCritical Severity = "critical"
+	// SeverityWarning marks the resource Degraded when the rule fails
+	SeverityWarning Severity = "warning"
+)
+
+// RuleSet is an ordered list of health rules applied to Sail Operator resources
+type RuleSet struct {
+	// ReplaceDefaults drops the built-in rules instead of appending to them
+	ReplaceDefaults bool   `json:"replaceDefaults,omitempty"`
+	Rules           []Rule `json:"rules"`
+}
+
+// Rule is a single declarative health check. Exactly one of Condition, State
+// or Expression must be set.
+type Rule struct {
+	Name string `json:"name"`
+	// Kinds restricts the rule to these resource kinds (e.g. Istio, IstioRevision).
+	// An empty list applies the rule to every kind.
+	Kinds    []string `json:"kinds,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+	// Message describes the failure; it is prefixed with the resource name
+	Message string `json:"message,omitempty"`
+
+	// Condition expects status.conditions[type] to have the given status
+	Condition *ConditionExpectation `json:"condition,omitempty"`
+	// State expects status.state to equal this value when it is set
+	State string `json:"state,omitempty"`
+	// Expression is a CEL expression over `object` that must evaluate to true
+	Expression string `json:"expression,omitempty"`
+
+	program *celProgram
+}
+
+// ConditionExpectation describes the expected status of a status condition
+type ConditionExpectation struct {
+	Type   string `json:"type"`
+	Status string `json:"status,omitempty"` // defaults to "True"
+}
+
+// Finding is a rule that failed for a resource
+type Finding struct {
+	Rule     string
+	Severity Severity
+	// Message is the failure description without the resource name
+	Message string
+	// ConditionType is set when the finding comes from a condition rule
+	ConditionType string
+}
+
+// DefaultRuleSet returns the built-in rules: Ready, Reconciled and
+// DependenciesHealthy must be True and status.state must be Healthy
+func DefaultRuleSet() *RuleSet {
+	return &RuleSet{Rules: defaultRules()}
+}
+
+func defaultRules() []Rule {
+	return []Rule{
+		{
+			Name:      "ready",
+			Severity:  SeverityCritical,
+			Message:   "is not ready",
+			Condition: &ConditionExpectation{Type: "Ready"},
+		},
+		{
+			Name:      "reconciled",
+			Severity:  SeverityCritical,
+			Message:   "reconciliation failed",
+			Condition: &ConditionExpectation{Type: "Reconciled"},
+		},
+		{
+			Name:      "dependencies-healthy",
+			Severity:  SeverityCritical,
+			Message:   "has unhealthy dependencies",
+			Condition: &ConditionExpectation{Type: "DependenciesHealthy"},
+		},
+		{
+			Name:     "healthy-state",
+			Severity: SeverityCritical,
+			State:    "Healthy",
+		},
+	}
+}
+
+// LoadRuleSet reads health rules from a YAML or JSON file. Unless the file sets
+// replaceDefaults, its rules are appended to the built-in ones.
+func LoadRuleSet(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read health rules from %s: %w", path, err)
+	}
+
+	var fileRules RuleSet
+	if err := yaml.UnmarshalStrict(data, &fileRules); err != nil {
+		return nil, fmt.Errorf("failed to parse health rules from %s: %w", path, err)
+	}
+
+	ruleSet := &RuleSet{Rules: fileRules.Rules}
+	if !fileRules.ReplaceDefaults {
+		ruleSet.Rules = append(defaultRules(), fileRules.Rules...)
+	}
+
+	if err := ruleSet.compile(); err != nil {
+		return nil, fmt.Errorf("invalid health rules in %s: %w", path, err)
+	}
+	return ruleSet, nil
+}
+
+// compile validates every rule and prepares its CEL program
+func (rs *RuleSet) compile() error {
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i)
+		}
+
+		set := 0
+		if rule.Condition != nil {
+			set++
+			if rule.Condition.Type == "" {
+				return fmt.Errorf("rule %q: condition.type is required", rule.Name)
+			}
+		}
+		if rule.State != "" {
+			set++
+		}
+		if rule.Expression != "" {
+			set++
+		}
+		if set != 1 {
+			return fmt.Errorf("rule %q: exactly one of condition, state or expression must be set", rule.Name)
+		}
+
+		switch rule.Severity {
+		case "":
+			rule.Severity = SeverityCritical
+		case SeverityCritical, SeverityWarning:
+		default:
+			return fmt.Errorf("rule %q: unknown severity %q (expected critical or warning)", rule.Name, rule.Severity)
+		}
+
+		if rule.Expression != "" {
+			program, err := compileExpression(rule.Expression)
+			if err != nil {
+				return fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+			rule.program = program
+		}
+	}
+	return nil
+}
+
+// Evaluate applies every rule matching the resource kind and returns the failures
+func (rs *RuleSet) Evaluate(resource *unstructured.Unstructured) []Finding {
+	var findings []Finding
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		if !rule.appliesTo(resource.GetKind()) {
+			continue
+		}
+		if finding, failed := rule.evaluate(resource); failed {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// appliesTo reports whether the rule applies to the given kind
+func (r *Rule) appliesTo(kind string) bool {
+	if len(r.Kinds) == 0 {
+		return true
+	}
+	for _, k := range r.Kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate runs a single rule and reports whether it failed
+func (r *Rule) evaluate(resource *unstructured.Unstructured) (Finding, bool) {
+	finding := Finding{Rule: r.Name, Severity: r.Severity, Message: r.Message}
+
+	switch {
+	case r.Condition != nil:
+		expected := r.Condition.Status
+		if expected == "" {
+			expected = "True"
+		}
+		conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+		for _, condRaw := range conditions {
+			condMap, ok := condRaw.(map[string]interface{})
+			if !ok || condMap["type"] != r.Condition.Type {
+				continue
+			}
+			if status, _ := condMap["status"].(string); status != expected {
+				finding.ConditionType = r.Condition.Type
+				if finding.Message == "" {
+					finding.Message = fmt.Sprintf("%s is %s (expected %s)", r.Condition.Type, status, expected)
+				}
+				if reason, ok := condMap["reason"].(string); ok && reason != "" {
+					finding.Message += fmt.Sprintf(" (%s)", reason)
+				}
+				return finding, true
+			}
+		}
+		return finding, false
+
+	case r.State != "":
+		state, found, _ := unstructured.NestedString(resource.Object, "status", "state")
+		if !found || state == r.State {
+			return finding, false
+		}
+		if finding.Message == "" {
+			finding.Message = fmt.Sprintf("state is %s", state)
+		}
+		return finding, true
+
+	default:
+		passed, err := r.program.eval(resource.Object)
+		if err == nil && passed {
+			return finding, false
+		}
+		if finding.Message == "" {
+			finding.Message = fmt.Sprintf("failed rule %q", r.Name)
+		}
+		if err != nil {
+			finding.Message += fmt.Sprintf(" (evaluation error: %v)", err)
+		}
+		return finding, true
+	}
+}
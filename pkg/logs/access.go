@@ -0,0 +1,246 @@
+package logs
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessEntry is one parsed Envoy access log entry
+type AccessEntry struct {
+	Time                  time.Time
+	Method                string
+	Path                  string
+	Protocol              string
+	ResponseCode          int      // 0 for TCP connections and requests without a response
+	ResponseFlags         []string // empty when Envoy logged "-"
+	ResponseCodeDetails   string
+	UpstreamFailureReason string
+	Duration              time.Duration
+	Authority             string
+	UpstreamHost          string
+	UpstreamCluster       string
+}
+
+// responseFlagMeanings explains Envoy's short response flags, as documented
+// for %RESPONSE_FLAGS% in the Envoy access log reference
+var responseFlagMeanings = map[string]string{
+	"UH":    "no healthy upstream hosts in the upstream cluster",
+	"UF":    "upstream connection failure",
+	"UO":    "upstream overflow (circuit breaker open)",
+	"NR":    "no route configured for the request",
+	"URX":   "upstream retry limit or maximum connect attempts exceeded",
+	"NC":    "upstream cluster not found",
+	"DT":    "request or connection exceeded its maximum duration",
+	"DC":    "downstream connection terminated",
+	"LH":    "local service failed its health check",
+	"UT":    "upstream request timeout",
+	"LR":    "connection reset locally",
+	"UR":    "upstream remote reset",
+	"UC":    "upstream connection terminated",
+	"DI":    "request delayed by fault injection",
+	"FI":    "request aborted by fault injection",
+	"RL":    "rate limited locally",
+	"UAEX":  "denied by the external authorization service",
+	"RLSE":  "rate limit service error",
+	"IH":    "strictly checked request header had an invalid value",
+	"SI":    "stream idle timeout",
+	"DPE":   "downstream request had an HTTP protocol error",
+	"UPE":   "upstream response had an HTTP protocol error",
+	"UMSDR": "upstream request reached its maximum stream duration",
+	"OM":    "overload manager terminated the request",
+	"DF":    "DNS resolution failed",
+	"DO":    "request dropped by overload (drop_overload)",
+}
+
+// ResponseFlagMeaning explains a response flag in plain language
+func ResponseFlagMeaning(flag string) string {
+	if meaning, ok := responseFlagMeanings[flag]; ok {
+		return meaning
+	}
+	return "unknown response flag"
+}
+
+// ParseAccessLog parses an access log line in Istio's default TEXT format or
+// its JSON encoding. Lines that are not access log entries, such as Envoy's
+// own logs in the istio-proxy container, return false.
+func ParseAccessLog(raw string) (AccessEntry, bool) {
+	line := strings.TrimSpace(raw)
+	// A timestamp added by the Kubernetes API precedes either format
+	if ts, rest, ok := strings.Cut(line, " "); ok && !strings.HasPrefix(ts, "[") && !strings.HasPrefix(ts, "{") {
+		if _, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			line = strings.TrimSpace(rest)
+		}
+	}
+	if strings.HasPrefix(line, "{") {
+		return parseJSONAccessLog(line)
+	}
+	if strings.HasPrefix(line, "[") {
+		return parseTextAccessLog(line)
+	}
+	return AccessEntry{}, false
+}
+
+// accessToken is one field of a TEXT access log line
+type accessToken struct {
+	value  string
+	quoted bool
+}
+
+// tokenizeAccessLog splits a TEXT access log line into fields separated by
+// spaces, keeping [bracketed] and "quoted" fields whole
+func tokenizeAccessLog(line string) []accessToken {
+	var tokens []accessToken
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ':
+			i++
+		case '[', '"':
+			end := byte(']')
+			if line[i] == '"' {
+				end = '"'
+			}
+			j := strings.IndexByte(line[i+1:], end)
+			if j < 0 {
+				return append(tokens, accessToken{value: line[i+1:], quoted: true})
+			}
+			tokens = append(tokens, accessToken{value: line[i+1 : i+1+j], quoted: true})
+			i += j + 2
+		default:
+			j := strings.IndexByte(line[i:], ' ')
+			if j < 0 {
+				j = len(line) - i
+			}
+			tokens = append(tokens, accessToken{value: line[i : i+j]})
+			i += j
+		}
+	}
+	return tokens
+}
+
+// parseTextAccessLog parses Istio's default TEXT format:
+//
+//	[%START_TIME%] "%REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %PROTOCOL%"
+//	%RESPONSE_CODE% %RESPONSE_FLAGS% %RESPONSE_CODE_DETAILS% %CONNECTION_TERMINATION_DETAILS%
+//	"%UPSTREAM_TRANSPORT_FAILURE_REASON%" %BYTES_RECEIVED% %BYTES_SENT% %DURATION%
+//	%RESP(X-ENVOY-UPSTREAM-SERVICE-TIME)% "%REQ(X-FORWARDED-FOR)%" "%REQ(USER-AGENT)%"
+//	"%REQ(X-REQUEST-ID)%" "%REQ(:AUTHORITY)%" "%UPSTREAM_HOST%" %UPSTREAM_CLUSTER% ...
+//
+// Releases without %CONNECTION_TERMINATION_DETAILS% are recognised by the
+// quoted failure reason following the response code details.
+func parseTextAccessLog(line string) (AccessEntry, bool) {
+	tokens := tokenizeAccessLog(line)
+	if len(tokens) < 16 || !tokens[0].quoted || !tokens[1].quoted || tokens[2].quoted {
+		return AccessEntry{}, false
+	}
+	code, err := strconv.Atoi(tokens[2].value)
+	if err != nil {
+		return AccessEntry{}, false
+	}
+
+	entry := AccessEntry{ResponseCode: code}
+	entry.Time, _ = time.Parse(time.RFC3339Nano, tokens[0].value)
+	if request := strings.Fields(tokens[1].value); len(request) == 3 {
+		entry.Method, entry.Path, entry.Protocol = dash(request[0]), dash(request[1]), dash(request[2])
+	}
+	entry.ResponseFlags = splitFlags(tokens[3].value)
+	entry.ResponseCodeDetails = dash(tokens[4].value)
+
+	// Index of the failure reason, after which the positions are fixed
+	reason := 6
+	if tokens[5].quoted {
+		reason = 5
+	}
+	if len(tokens) < reason+11 {
+		return AccessEntry{}, false
+	}
+	entry.UpstreamFailureReason = dash(tokens[reason].value)
+	if ms, err := strconv.Atoi(tokens[reason+3].value); err == nil {
+		entry.Duration = time.Duration(ms) * time.Millisecond
+	}
+	entry.Authority = dash(tokens[reason+8].value)
+	entry.UpstreamHost = dash(tokens[reason+9].value)
+	entry.UpstreamCluster = dash(tokens[reason+10].value)
+	return entry, true
+}
+
+// parseJSONAccessLog parses Istio's JSON access log encoding
+func parseJSONAccessLog(line string) (AccessEntry, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return AccessEntry{}, false
+	}
+	if _, ok := fields["response_code"]; !ok {
+		return AccessEntry{}, false
+	}
+
+	str := func(key string) string {
+		switch v := fields[key].(type) {
+		case string:
+			return dash(v)
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return ""
+	}
+	entry := AccessEntry{
+		Method:                str("method"),
+		Path:                  str("path"),
+		Protocol:              str("protocol"),
+		ResponseFlags:         splitFlags(str("response_flags")),
+		ResponseCodeDetails:   str("response_code_details"),
+		UpstreamFailureReason: str("upstream_transport_failure_reason"),
+		Authority:             str("authority"),
+		UpstreamHost:          str("upstream_host"),
+		UpstreamCluster:       str("upstream_cluster"),
+	}
+	entry.Time, _ = time.Parse(time.RFC3339Nano, str("start_time"))
+	entry.ResponseCode, _ = strconv.Atoi(str("response_code"))
+	if ms, err := strconv.ParseFloat(str("duration"), 64); err == nil {
+		entry.Duration = time.Duration(ms * float64(time.Millisecond))
+	}
+	return entry, true
+}
+
+// Destination names where a request went: the service of an outbound
+// cluster without the cluster domain (reviews.default), "inbound:<port>"
+// for requests to the local workload, or the authority when there was no
+// cluster, as with NR
+func (e AccessEntry) Destination() string {
+	if e.UpstreamCluster == "" {
+		if e.Authority != "" {
+			return e.Authority
+		}
+		return "unknown"
+	}
+	// outbound|9080|v1|reviews.default.svc.cluster.local
+	parts := strings.Split(e.UpstreamCluster, "|")
+	if len(parts) != 4 {
+		// PassthroughCluster, BlackHoleCluster, InboundPassthroughCluster...
+		return e.UpstreamCluster
+	}
+	if parts[0] == "inbound" {
+		return "inbound:" + parts[1]
+	}
+	host := parts[3]
+	if i := strings.Index(host, ".svc."); i >= 0 {
+		host = host[:i]
+	}
+	return host
+}
+
+func splitFlags(flags string) []string {
+	if flags == "" || flags == "-" {
+		return nil
+	}
+	return strings.Split(flags, ",")
+}
+
+// dash turns Envoy's "-" placeholder for a missing value into ""
+func dash(value string) string {
+	if value == "-" {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,165 @@
+package logs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAccessLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		ok          bool
+		want        AccessEntry
+		destination string
+	}{
+		{
+			name: "text 503 UF",
+			raw:  `[2024-05-01T10:00:00.123Z] "GET /reviews/0 HTTP/1.1" 503 UF upstream_reset_before_response_started{connection_failure} - "delayed_connect_error:_111" 0 91 2 - "-" "Mozilla/5.0" "5f1e7c2a-7d1b-9a7e-a3b1-2f4c8d9e0a11" "reviews:9080" "10.244.0.12:9080" outbound|9080||reviews.default.svc.cluster.local - 10.96.120.5:9080 10.244.0.10:45678 - default`,
+			ok:   true,
+			want: AccessEntry{
+				Time:                  time.Date(2024, 5, 1, 10, 0, 0, 123000000, time.UTC),
+				Method:                "GET",
+				Path:                  "/reviews/0",
+				Protocol:              "HTTP/1.1",
+				ResponseCode:          503,
+				ResponseFlags:         []string{"UF"},
+				ResponseCodeDetails:   "upstream_reset_before_response_started{connection_failure}",
+				UpstreamFailureReason: "delayed_connect_error:_111",
+				Duration:              2 * time.Millisecond,
+				Authority:             "reviews:9080",
+				UpstreamHost:          "10.244.0.12:9080",
+				UpstreamCluster:       "outbound|9080||reviews.default.svc.cluster.local",
+			},
+			destination: "reviews.default",
+		},
+		{
+			name: "text NR without cluster",
+			raw:  `[2024-05-01T10:00:01.000Z] "GET /missing HTTP/1.1" 404 NR route_not_found - "-" 0 0 0 - "-" "curl/8.5.0" "0c7d2a8e-55c4-4b7a-9a65-3d2f1b7e9c40" "productpage:9080" "-" - - 10.96.0.20:9080 10.244.0.10:51234 - -`,
+			ok:   true,
+			want: AccessEntry{
+				Time:                time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC),
+				Method:              "GET",
+				Path:                "/missing",
+				Protocol:            "HTTP/1.1",
+				ResponseCode:        404,
+				ResponseFlags:       []string{"NR"},
+				ResponseCodeDetails: "route_not_found",
+				Authority:           "productpage:9080",
+			},
+			destination: "productpage:9080",
+		},
+		{
+			name: "text TCP connection",
+			raw:  `[2024-05-01T10:00:02.000Z] "- - -" 0 - - - "-" 1024 2048 5003 - "-" "-" "-" "-" "10.244.0.30:3306" outbound|3306||mysql.db.svc.cluster.local 10.244.0.10:40000 10.96.50.1:3306 10.244.0.10:39998 - -`,
+			ok:   true,
+			want: AccessEntry{
+				Time:            time.Date(2024, 5, 1, 10, 0, 2, 0, time.UTC),
+				Duration:        5003 * time.Millisecond,
+				UpstreamHost:    "10.244.0.30:3306",
+				UpstreamCluster: "outbound|3306||mysql.db.svc.cluster.local",
+			},
+			destination: "mysql.db",
+		},
+		{
+			name: "text without connection termination details, behind a kubernetes timestamp",
+			raw:  `2024-05-01T10:00:03.500000000Z [2024-05-01T10:00:03.000Z] "POST /api HTTP/2" 200 - via_upstream "-" 120 15 8 7 "-" "grpc-go/1.60" "a3c1e2d4-1111-4222-8333-944455556666" "api.default:8080" "10.244.1.4:8080" inbound|8080|| 127.0.0.6:41234 10.244.1.4:8080 10.244.0.10:38888 outbound_.8080_._.api.default.svc.cluster.local default`,
+			ok:   true,
+			want: AccessEntry{
+				Time:                time.Date(2024, 5, 1, 10, 0, 3, 0, time.UTC),
+				Method:              "POST",
+				Path:                "/api",
+				Protocol:            "HTTP/2",
+				ResponseCode:        200,
+				ResponseCodeDetails: "via_upstream",
+				Duration:            8 * time.Millisecond,
+				Authority:           "api.default:8080",
+				UpstreamHost:        "10.244.1.4:8080",
+				UpstreamCluster:     "inbound|8080||",
+			},
+			destination: "inbound:8080",
+		},
+		{
+			name: "json 503 UF",
+			raw:  `{"authority":"reviews:9080","bytes_received":0,"bytes_sent":91,"connection_termination_details":null,"downstream_local_address":"10.96.120.5:9080","downstream_remote_address":"10.244.0.10:45678","duration":2,"method":"GET","path":"/reviews/0","protocol":"HTTP/1.1","request_id":"5f1e7c2a-7d1b-9a7e-a3b1-2f4c8d9e0a11","requested_server_name":null,"response_code":503,"response_code_details":"upstream_reset_before_response_started{connection_failure}","response_flags":"UF","route_name":"default","start_time":"2024-05-01T10:00:00.123Z","upstream_cluster":"outbound|9080||reviews.default.svc.cluster.local","upstream_host":"10.244.0.12:9080","upstream_local_address":null,"upstream_service_time":null,"upstream_transport_failure_reason":"delayed_connect_error:_111","user_agent":"Mozilla/5.0","x_forwarded_for":null}`,
+			ok:   true,
+			want: AccessEntry{
+				Time:                  time.Date(2024, 5, 1, 10, 0, 0, 123000000, time.UTC),
+				Method:                "GET",
+				Path:                  "/reviews/0",
+				Protocol:              "HTTP/1.1",
+				ResponseCode:          503,
+				ResponseFlags:         []string{"UF"},
+				ResponseCodeDetails:   "upstream_reset_before_response_started{connection_failure}",
+				UpstreamFailureReason: "delayed_connect_error:_111",
+				Duration:              2 * time.Millisecond,
+				Authority:             "reviews:9080",
+				UpstreamHost:          "10.244.0.12:9080",
+				UpstreamCluster:       "outbound|9080||reviews.default.svc.cluster.local",
+			},
+			destination: "reviews.default",
+		},
+		{
+			name: "json NR without cluster",
+			raw:  `{"authority":"productpage:9080","bytes_received":0,"bytes_sent":0,"duration":0,"method":"GET","path":"/missing","protocol":"HTTP/1.1","response_code":404,"response_code_details":"route_not_found","response_flags":"NR","start_time":"2024-05-01T10:00:01.000Z","upstream_cluster":null,"upstream_host":null,"upstream_transport_failure_reason":null}`,
+			ok:   true,
+			want: AccessEntry{
+				Time:                time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC),
+				Method:              "GET",
+				Path:                "/missing",
+				Protocol:            "HTTP/1.1",
+				ResponseCode:        404,
+				ResponseFlags:       []string{"NR"},
+				ResponseCodeDetails: "route_not_found",
+				Authority:           "productpage:9080",
+			},
+			destination: "productpage:9080",
+		},
+		{
+			name: "json TCP connection",
+			raw:  `{"authority":null,"bytes_received":1024,"bytes_sent":2048,"duration":5003,"method":null,"path":null,"protocol":null,"response_code":0,"response_code_details":null,"response_flags":"-","start_time":"2024-05-01T10:00:02.000Z","upstream_cluster":"outbound|3306||mysql.db.svc.cluster.local","upstream_host":"10.244.0.30:3306","upstream_transport_failure_reason":null}`,
+			ok:   true,
+			want: AccessEntry{
+				Time:            time.Date(2024, 5, 1, 10, 0, 2, 0, time.UTC),
+				Duration:        5003 * time.Millisecond,
+				UpstreamHost:    "10.244.0.30:3306",
+				UpstreamCluster: "outbound|3306||mysql.db.svc.cluster.local",
+			},
+			destination: "mysql.db",
+		},
+		{
+			name: "envoy log line",
+			raw:  `[2024-05-01 10:00:00.123][15][warning][config] [source/common/config/grpc_stream.h:191] StreamAggregatedResources gRPC config stream to xds-grpc closed: 13, `,
+			ok:   false,
+		},
+		{
+			name: "istio-agent log line",
+			raw:  "2024-05-01T10:00:00.123456Z\tinfo\txdsproxy\tconnected to upstream XDS server: istiod.istio-system.svc:15012",
+			ok:   false,
+		},
+		{
+			name: "json log line without response code",
+			raw:  `{"level":"info","msg":"starting"}`,
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := ParseAccessLog(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("ParseAccessLog() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(entry, tt.want) {
+				t.Errorf("ParseAccessLog() = %+v\nwant %+v", entry, tt.want)
+			}
+			if got := entry.Destination(); got != tt.destination {
+				t.Errorf("Destination() = %q, want %q", got, tt.destination)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Package logs filters, deduplicates and summarises container logs on the
+// server, so large istiod and Envoy logs fit in the model's context
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Level is a log severity; lines with an unknown level are LevelUnknown
+type Level int
+
+const (
+	LevelUnknown Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelUnknown: "unknown",
+	LevelDebug:   "debug",
+	LevelInfo:    "info",
+	LevelWarn:    "warn",
+	LevelError:   "error",
+}
+
+func (l Level) String() string { return levelNames[l] }
+
+// ParseLevel parses a level name as written by Istio, Envoy and common JSON loggers
+func ParseLevel(name string) (Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "trace", "debug", "dbug":
+		return LevelDebug, true
+	case "info", "information":
+		return LevelInfo, true
+	case "warn", "warning":
+		return LevelWarn, true
+	case "error", "err", "critical", "fatal", "panic":
+		return LevelError, true
+	}
+	return LevelUnknown, false
+}
+
+// Line is a parsed log line
+type Line struct {
+	Raw     string
+	Time    time.Time // zero when the line has no timestamp
+	Level   Level
+	Message string // the line without timestamp and level
+}
+
+var (
+	// Istio: 2024-05-01T10:00:00.123456Z	info	ads	message
+	istioLine = regexp.MustCompile(`^(\S+)\t(\w+)\t(.*)$`)
+	// Envoy: [2024-05-01 10:00:00.123][15][warning][config] message
+	envoyLine = regexp.MustCompile(`^\[([^\]]+)\]\[\d+\]\[(\w+)\](?:\[[^\]]*\])?\s*(.*)$`)
+	// klog: E0501 10:00:00.123456       1 file.go:42] message
+	klogLine = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}\.\d+\s+\d+ [^\]]+\] (.*)$`)
+)
+
+// Parse extracts the timestamp, level and message of a line. A leading
+// RFC 3339 timestamp, as added by the Kubernetes API with timestamps=true,
+// is taken as the line's time.
+func Parse(raw string) Line {
+	line := Line{Raw: raw, Message: raw}
+	rest := raw
+	if ts, after, ok := strings.Cut(rest, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			line.Time = t
+			rest = after
+			line.Message = rest
+		}
+	}
+
+	trimmed := strings.TrimSpace(rest)
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		var fields map[string]any
+		if json.Unmarshal([]byte(trimmed), &fields) == nil {
+			for _, key := range []string{"level", "severity", "lvl"} {
+				if v, ok := fields[key].(string); ok {
+					line.Level, _ = ParseLevel(v)
+					break
+				}
+			}
+			for _, key := range []string{"msg", "message"} {
+				if v, ok := fields[key].(string); ok {
+					line.Message = v
+					break
+				}
+			}
+			if line.Time.IsZero() {
+				for _, key := range []string{"time", "ts", "timestamp"} {
+					if v, ok := fields[key].(string); ok {
+						line.Time, _ = time.Parse(time.RFC3339Nano, v)
+						break
+					}
+				}
+			}
+		}
+	case istioLine.MatchString(rest):
+		m := istioLine.FindStringSubmatch(rest)
+		if level, ok := ParseLevel(m[2]); ok {
+			line.Level = level
+			// The scope stays in the message: "ads push failed"
+			line.Message = strings.Replace(m[3], "\t", " ", 1)
+			if line.Time.IsZero() {
+				line.Time, _ = time.Parse(time.RFC3339Nano, m[1])
+			}
+		}
+	case envoyLine.MatchString(rest):
+		m := envoyLine.FindStringSubmatch(rest)
+		if level, ok := ParseLevel(m[2]); ok {
+			line.Level = level
+			line.Message = m[3]
+		}
+	case klogLine.MatchString(rest):
+		m := klogLine.FindStringSubmatch(rest)
+		line.Level = map[string]Level{"I": LevelInfo, "W": LevelWarn, "E": LevelError, "F": LevelError}[m[1]]
+		line.Message = m[2]
+	}
+	return line
+}
+
+// Filter selects lines by regex and minimum level
+type Filter struct {
+	Include  *regexp.Regexp
+	Exclude  *regexp.Regexp
+	MinLevel Level // lines with an unknown level pass unless MinLevel is above info
+}
+
+// NewFilter compiles a filter; empty arguments disable that part of it
+func NewFilter(include, exclude, minLevel string) (*Filter, error) {
+	f := &Filter{}
+	var err error
+	if include != "" {
+		if f.Include, err = regexp.Compile(include); err != nil {
+			return nil, fmt.Errorf("invalid include pattern '%s': %w", include, err)
+		}
+	}
+	if exclude != "" {
+		if f.Exclude, err = regexp.Compile(exclude); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern '%s': %w", exclude, err)
+		}
+	}
+	if minLevel != "" {
+		level, ok := ParseLevel(minLevel)
+		if !ok {
+			return nil, fmt.Errorf("invalid min_level '%s', use debug, info, warn or error", minLevel)
+		}
+		f.MinLevel = level
+	}
+	return f, nil
+}
+
+// Match reports whether a line passes the filter
+func (f *Filter) Match(line Line) bool {
+	if f.Include != nil && !f.Include.MatchString(line.Raw) {
+		return false
+	}
+	if f.Exclude != nil && f.Exclude.MatchString(line.Raw) {
+		return false
+	}
+	if f.MinLevel > LevelUnknown {
+		if line.Level == LevelUnknown {
+			// Continuation lines such as stack traces carry no level
+			return f.MinLevel <= LevelInfo
+		}
+		return line.Level >= f.MinLevel
+	}
+	return true
+}
+
+var (
+	signatureIP      = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`)
+	signatureUUID    = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	signatureHex     = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-f]{12,}\b`)
+	signaturePodHash = regexp.MustCompile(`-[a-z0-9]{8,10}-[a-z0-9]{5}\b`)
+	signatureNumber  = regexp.MustCompile(`\b\d+(\.\d+)?(ms|s|m|h)?\b`)
+)
+
+// Signature normalises a message so repeated occurrences of the same event
+// compare equal: addresses, IDs, pod hashes and numbers are replaced
+func Signature(message string) string {
+	s := signatureUUID.ReplaceAllString(message, "<uuid>")
+	s = signatureIP.ReplaceAllString(s, "<ip>")
+	s = signatureHex.ReplaceAllString(s, "<hex>")
+	s = signaturePodHash.ReplaceAllString(s, "-<hash>")
+	s = signatureNumber.ReplaceAllString(s, "<n>")
+	return strings.TrimSpace(s)
+}
+
+// DedupEntry is a message and how often it repeated
+type DedupEntry struct {
+	Line  Line // first occurrence
+	Count int
+}
+
+// Dedup collapses lines with the same signature into one entry per
+// signature, in order of first occurrence
+func Dedup(lines []Line) []DedupEntry {
+	index := map[string]int{}
+	var entries []DedupEntry
+	for _, l := range lines {
+		sig := Signature(l.Message)
+		if i, ok := index[sig]; ok {
+			entries[i].Count++
+			continue
+		}
+		index[sig] = len(entries)
+		entries = append(entries, DedupEntry{Line: l, Count: 1})
+	}
+	return entries
+}
+
+// SignatureSummary aggregates the occurrences of one warning or error
+type SignatureSummary struct {
+	Signature string
+	Level     string
+	Count     int
+	FirstSeen time.Time // zero when no line had a timestamp
+	LastSeen  time.Time
+	Example   string // the message of the first occurrence
+}
+
+// Summarize returns the top warning and error signatures, most frequent first
+func Summarize(lines []Line, top int) []SignatureSummary {
+	index := map[string]*SignatureSummary{}
+	var order []string
+	for _, l := range lines {
+		if l.Level < LevelWarn {
+			continue
+		}
+		sig := Signature(l.Message)
+		s, ok := index[sig]
+		if !ok {
+			s = &SignatureSummary{Signature: sig, Level: l.Level.String(), Example: l.Message}
+			index[sig] = s
+			order = append(order, sig)
+		}
+		s.Count++
+		if l.Level > LevelWarn {
+			s.Level = l.Level.String()
+		}
+		if !l.Time.IsZero() {
+			if s.FirstSeen.IsZero() || l.Time.Before(s.FirstSeen) {
+				s.FirstSeen = l.Time
+			}
+			if l.Time.After(s.LastSeen) {
+				s.LastSeen = l.Time
+			}
+		}
+	}
+
+	summaries := make([]SignatureSummary, 0, len(order))
+	for _, sig := range order {
+		summaries = append(summaries, *index[sig])
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].Level != summaries[j].Level {
+			return summaries[i].Level == LevelError.String()
+		}
+		return summaries[i].Count > summaries[j].Count
+	})
+	if top > 0 && len(summaries) > top {
+		summaries = summaries[:top]
+	}
+	return summaries
+}
+
+// TailBytes keeps the newest strings whose total size, counting a newline
+// after each, fits in maxBytes. It returns them and how many were dropped.
+func TailBytes(lines []string, maxBytes int) ([]string, int) {
+	if maxBytes <= 0 {
+		return lines, 0
+	}
+	first, size := len(lines), 0
+	for first > 0 && size+len(lines[first-1])+1 <= maxBytes {
+		first--
+		size += len(lines[first]) + 1
+	}
+	return lines[first:], first
+}
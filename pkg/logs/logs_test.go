@@ -0,0 +1,187 @@
+package logs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		time    time.Time
+		level   Level
+		message string
+	}{
+		{
+			name:    "istio",
+			raw:     "2024-05-01T10:00:00.123456Z\twarn\tads\tADS: \"10.0.0.5:43210\" productpage-v1-7d9f8c6b5-x2x4k.bookinfo-14 terminated with stream closed",
+			time:    time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC),
+			level:   LevelWarn,
+			message: "ads ADS: \"10.0.0.5:43210\" productpage-v1-7d9f8c6b5-x2x4k.bookinfo-14 terminated with stream closed",
+		},
+		{
+			name:    "istio with kubernetes timestamp",
+			raw:     "2024-05-01T10:00:01.000000001Z 2024-05-01T10:00:00.500000Z\terror\tmodel\tfailed to push to 3 proxies",
+			time:    time.Date(2024, 5, 1, 10, 0, 1, 1, time.UTC),
+			level:   LevelError,
+			message: "model failed to push to 3 proxies",
+		},
+		{
+			name:    "envoy",
+			raw:     "[2024-05-01 10:00:00.123][15][warning][config] [source/common/config/grpc_stream.h:191] StreamAggregatedResources gRPC config stream to xds-grpc closed: 13, ",
+			level:   LevelWarn,
+			message: "[source/common/config/grpc_stream.h:191] StreamAggregatedResources gRPC config stream to xds-grpc closed: 13, ",
+		},
+		{
+			name:    "klog",
+			raw:     "E0501 10:00:00.123456       1 leaderelection.go:332] error retrieving resource lock istio-system/istio-leader: context deadline exceeded",
+			level:   LevelError,
+			message: "error retrieving resource lock istio-system/istio-leader: context deadline exceeded",
+		},
+		{
+			name:    "json",
+			raw:     `{"level":"info","time":"2024-05-01T10:00:00Z","msg":"Reconciling Istio","controller":"istio"}`,
+			time:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			level:   LevelInfo,
+			message: "Reconciling Istio",
+		},
+		{
+			name:    "json with severity",
+			raw:     `{"severity":"ERROR","message":"reconcile failed"}`,
+			level:   LevelError,
+			message: "reconcile failed",
+		},
+		{
+			name:    "plain text",
+			raw:     "starting server",
+			level:   LevelUnknown,
+			message: "starting server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := Parse(tt.raw)
+			if !line.Time.Equal(tt.time) {
+				t.Errorf("Time = %s, want %s", line.Time, tt.time)
+			}
+			if line.Level != tt.level {
+				t.Errorf("Level = %s, want %s", line.Level, tt.level)
+			}
+			if line.Message != tt.message {
+				t.Errorf("Message = %q, want %q", line.Message, tt.message)
+			}
+			if line.Raw != tt.raw {
+				t.Errorf("Raw = %q, want the input", line.Raw)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "address and pod hash",
+			message: "ADS: \"10.0.0.5:43210\" productpage-v1-7d9f8c6b5-x2x4k.bookinfo-14 terminated",
+			want:    "ADS: \"<ip>\" productpage-v1-<hash>.bookinfo-<n> terminated",
+		},
+		{
+			name:    "uuid",
+			message: "request 3f2504e0-4f89-11d3-9a0c-0305e82c3301 failed",
+			want:    "request <uuid> failed",
+		},
+		{
+			name:    "durations",
+			message: "push took 250ms for 12 proxies",
+			want:    "push took <n> for <n> proxies",
+		},
+		{
+			name:    "hex",
+			message: "cert serial 0x1f2e3d expired",
+			want:    "cert serial <hex> expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Signature(tt.message); got != tt.want {
+				t.Errorf("Signature() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	raw := []string{
+		"2024-05-01T10:00:00Z\tinfo\tads\tpush complete",
+		"2024-05-01T10:00:01Z\twarn\tads\tconnection from 10.0.0.1:1000 reset",
+		"2024-05-01T10:00:02Z\twarn\tads\tconnection from 10.0.0.2:2000 reset",
+		"2024-05-01T10:00:03Z\twarn\tads\tconnection from 10.0.0.3:3000 reset",
+		"2024-05-01T10:00:04Z\terror\tmodel\tfailed to push to 3 proxies",
+		"[2024-05-01 10:00:05.000][1][warning][config] stream closed",
+	}
+	var lines []Line
+	for _, r := range raw {
+		lines = append(lines, Parse(r))
+	}
+
+	got := Summarize(lines, 0)
+	if len(got) != 3 {
+		t.Fatalf("Summarize() returned %d signatures, want 3: %+v", len(got), got)
+	}
+
+	// Errors come first, then warnings by count
+	if got[0].Level != "error" || got[0].Count != 1 {
+		t.Errorf("first = %+v, want the error", got[0])
+	}
+	reset := got[1]
+	if reset.Signature != "ads connection from <ip> reset" || reset.Count != 3 {
+		t.Errorf("second = %+v, want 3 connection resets", reset)
+	}
+	if !reset.FirstSeen.Equal(time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC)) || !reset.LastSeen.Equal(time.Date(2024, 5, 1, 10, 0, 3, 0, time.UTC)) {
+		t.Errorf("reset seen %s to %s", reset.FirstSeen, reset.LastSeen)
+	}
+	if reset.Example != "ads connection from 10.0.0.1:1000 reset" {
+		t.Errorf("Example = %q, want the first occurrence", reset.Example)
+	}
+	if got[2].Count != 1 || !got[2].FirstSeen.IsZero() {
+		t.Errorf("third = %+v, want the untimed envoy warning", got[2])
+	}
+
+	if top := Summarize(lines, 1); len(top) != 1 || top[0].Level != "error" {
+		t.Errorf("Summarize(top=1) = %+v, want only the error", top)
+	}
+}
+
+func TestTailBytes(t *testing.T) {
+	lines := []string{"aaaa", "bbbb", "cccc"}
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		want     int
+		dropped  int
+	}{
+		{name: "no limit", maxBytes: 0, want: 3, dropped: 0},
+		{name: "everything fits", maxBytes: 15, want: 3, dropped: 0},
+		{name: "newest lines kept", maxBytes: 14, want: 2, dropped: 1},
+		{name: "nothing fits", maxBytes: 4, want: 0, dropped: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, dropped := TailBytes(lines, tt.maxBytes)
+			if len(kept) != tt.want || dropped != tt.dropped {
+				t.Errorf("TailBytes() kept %v dropped %d, want %d kept %d dropped", kept, dropped, tt.want, tt.dropped)
+			}
+			if len(kept) > 0 && kept[len(kept)-1] != "cccc" {
+				t.Errorf("TailBytes() kept %v, want the newest lines", kept)
+			}
+		})
+	}
+}
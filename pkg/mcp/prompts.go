@@ -0,0 +1,21 @@
+package mcp
+
+import (
+	"log"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/handlers/prompts"
+)
+
+// RegisterAllPrompts registers the troubleshooting playbooks as MCP prompts
+func RegisterAllPrompts(server *mcp.Server) {
+	var names []string
+	for _, p := range prompts.Playbooks {
+		server.AddPrompt(p.Prompt, prompts.GetPlaybook(p))
+		names = append(names, p.Prompt.Name)
+	}
+
+	log.Printf("Registered MCP prompts: %s", strings.Join(names, ", "))
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+	"log"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	resourcehandlers "github.com/frherrer/mcp-sail-operator/pkg/handlers/resources"
+	"github.com/frherrer/mcp-sail-operator/pkg/mcpext"
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
+)
+
+// RegisterAllResources registers the MCP resource templates and lists the
+// concrete mesh resources in resources/list. With a transport, clients can
+// also subscribe to resources and are notified when they change.
+func RegisterAllResources(ctx context.Context, server *mcp.Server, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry, transport *mcpext.Transport) {
+	readSail := resourcehandlers.ReadSailResource(dynamicClient, registry)
+	for _, t := range resourcehandlers.SailTemplates() {
+		server.AddResourceTemplate(t, readSail)
+	}
+
+	readPod := resourcehandlers.ReadPod(k8sClient)
+	server.AddResourceTemplate(resourcehandlers.PodTemplate, readPod)
+	server.AddResourceTemplate(resourcehandlers.PodLogsTemplate, readPod)
+
+	// The SDK only lists resources registered up front, so the objects that
+	// exist in the cluster are added to the first page when it is requested
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+		return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+			result, err := next(ctx, ss, method, params)
+			if err != nil || method != "resources/list" {
+				return result, err
+			}
+			list, ok := result.(*mcp.ListResourcesResult)
+			if !ok {
+				return result, nil
+			}
+			if p, ok := params.(*mcp.ListResourcesParams); ok && p.Cursor != "" {
+				return result, nil
+			}
+			list.Resources = append(list.Resources, resourcehandlers.ListMeshResources(ctx, k8sClient, dynamicClient, registry)...)
+			return list, nil
+		}
+	})
+
+	if transport != nil {
+		watcher := resourcehandlers.NewWatcher(ctx, k8sClient, dynamicClient, registry, transport)
+		transport.OnSubscribe(watcher.Watch)
+		transport.OnUnsubscribe(watcher.Unwatch)
+	}
+
+	log.Println("Registered MCP resource templates: sail://<kind>/{name}, k8s://{namespace}/pods/{name}, k8s://{namespace}/pods/{name}/logs")
+}
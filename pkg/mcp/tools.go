@@ -7,20 +7,45 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/frherrer/mcp-sail-operator/pkg/audit"
 	k8shandlers "github.com/frherrer/mcp-sail-operator/pkg/handlers/k8s"
 	sailoperatorhandlers "github.com/frherrer/mcp-sail-operator/pkg/handlers/sailoperator"
+	"github.com/frherrer/mcp-sail-operator/pkg/health"
+	"github.com/frherrer/mcp-sail-operator/pkg/mcpext"
+	"github.com/frherrer/mcp-sail-operator/pkg/sail"
 )
 
+// Config holds server-wide settings shared by the registered tools
+type Config struct {
+	// HealthRules are applied by check_sailoperator_health; nil uses the built-in defaults
+	HealthRules *health.RuleSet
+	// EnableWrites registers the tools that modify Sail resources and workloads
+	EnableWrites bool
+	// Elicitor asks the user to confirm writes; writes are refused without it
+	Elicitor mcpext.Elicitor
+	// AuditLog records every attempted write
+	AuditLog *audit.Logger
+	// ResponseBudget caps the bytes of items list tools return per call;
+	// 0 uses k8s.DefaultResponseBudget
+	ResponseBudget int
+}
+
 // RegisterAllTools registers all available MCP tools with the server
-func RegisterAllTools(server *mcp.Server, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface) {
-	registerK8sTools(server, k8sClient)
-	registerSailOperatorTools(server, dynamicClient)
+func RegisterAllTools(server *mcp.Server, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry, cfg Config) {
+	registerK8sTools(server, k8sClient, dynamicClient, registry, cfg.ResponseBudget)
+	registerSailOperatorTools(server, k8sClient, dynamicClient, registry, cfg)
+	if cfg.EnableWrites {
+		registerWriteTools(server, dynamicClient, registry, &sailoperatorhandlers.WriteGuard{
+			Elicitor: cfg.Elicitor,
+			Audit:    cfg.AuditLog,
+		})
+	}
 
 	log.Println("Registered all MCP tools")
 }
 
 // registerK8sTools registers Kubernetes-related MCP tools
-func registerK8sTools(server *mcp.Server, k8sClient *kubernetes.Clientset) {
+func registerK8sTools(server *mcp.Server, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry, budget int) {
 	// Basic Kubernetes connectivity test
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "test_k8s_connection",
@@ -42,67 +67,176 @@ func registerK8sTools(server *mcp.Server, k8sClient *kubernetes.Clientset) {
 	// List pods tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_pods",
-		Description: "List pods in the cluster with optional namespace and label filtering",
-	}, k8shandlers.ListPods(k8sClient))
+		Description: "List pods in the cluster with optional namespace, label and field selectors. node_name and phase filter on the server; not_ready, restarts_above, older_than_seconds, younger_than_seconds and has_sidecar (istio-proxy container present or absent) filter the listed pods, so crash-looping meshed pods are one call (not_ready, restarts_above=3, has_sidecar=true); sort_by orders them by age (newest first), restarts or name. With these filters or sort_by the whole selection is scanned before paging, so pages and counts cover the matching pods. Returns at most limit pods (default 500) within the server's response budget; when more exist the result has a next_cursor to pass as cursor to get the next page",
+	}, k8shandlers.ListPods(k8sClient, budget))
 
 	// List services tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_services",
-		Description: "List services in the cluster with optional namespace and label filtering",
-	}, k8shandlers.ListServices(k8sClient))
+		Description: "List services in the cluster with optional namespace and label filtering. Returns at most limit services (default 500) within the server's response budget; when more exist the result has a next_cursor to pass as cursor to get the next page",
+	}, k8shandlers.ListServices(k8sClient, budget))
 
 	// List deployments tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_deployments",
-		Description: "List deployments in the cluster with optional namespace and label filtering",
-	}, k8shandlers.ListDeployments(k8sClient))
+		Description: "List deployments in the cluster with optional namespace and label filtering. Returns at most limit deployments (default 500) within the server's response budget; when more exist the result has a next_cursor to pass as cursor to get the next page",
+	}, k8shandlers.ListDeployments(k8sClient, budget))
 
 	// List configmaps tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_configmaps",
-		Description: "List configmaps in the cluster with optional namespace and label filtering",
-	}, k8shandlers.ListConfigMaps(k8sClient))
+		Description: "List configmaps in the cluster with optional namespace and label filtering. Returns at most limit configmaps (default 500) within the server's response budget; when more exist the result has a next_cursor to pass as cursor to get the next page",
+	}, k8shandlers.ListConfigMaps(k8sClient, budget))
 
 	// List events tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_events",
-		Description: "List recent Kubernetes events with optional selectors",
+		Description: "List recent Kubernetes events with optional selectors, oldest first; since_seconds keeps events last seen within the window",
 	}, k8shandlers.ListEvents(k8sClient))
 
+	// Generic resource tools, for kinds without a dedicated tool
+	mapper := k8shandlers.NewResourceMapper(k8sClient.Discovery())
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_resource",
+		Description: "Get or list resources of any kind the cluster serves, including CRDs: kind accepts a kind, plural or short name, optionally qualified with group (StatefulSet, jobs, hpa, cronjobs.batch, istios.sailoperator.io). Returns name, status, controlling owner and age per object, or the manifests with output=yaml or json; filter by namespace, name, label_selector or field_selector. Secret values are redacted to their key names and sizes",
+	}, k8shandlers.GetResource(dynamicClient, mapper))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "describe_resource",
+		Description: "Describe resources of any kind the cluster serves, like kubectl describe: metadata, labels and annotations, owner references, spec and status fields, conditions and the events about each resource. Takes kind (kind, plural or short name), namespace and name or label_selector (at most 10 resources). Secret values are redacted to their key names and sizes",
+	}, k8shandlers.DescribeResource(k8sClient, dynamicClient, mapper))
+
 	// Get pod logs tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_pod_logs",
-		Description: "Get logs from a specific pod and optionally a specific container",
+		Description: "Get logs from a specific pod and optionally a specific container. With follow, new lines are streamed until follow_seconds pass, max_lines lines are read or a line matches the until regex, then a summary is returned. Lines stream as progress notifications when the request has a progress token, otherwise as log notifications, which are only sent after the client called logging/setLevel; without a progress token the summary also repeats all streamed lines within max_bytes (default 256KiB). include/exclude regexes and min_level (from Istio, Envoy, klog or JSON log levels) filter lines on the server; dedup collapses repeated messages into counts, max_bytes keeps the newest lines within a size, and output=summary returns the top warning and error signatures with counts and first/last seen times instead of lines",
 	}, k8shandlers.GetPodLogs(k8sClient))
 
+	// Get workload logs tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_workload_logs",
+		Description: "Get the logs of every pod and container matching a label selector, Deployment, DaemonSet or IstioRevision (its istiod pods), read concurrently and interleaved by timestamp with a pod prefix on each line, within a total byte cap",
+	}, k8shandlers.GetWorkloadLogs(k8sClient, dynamicClient, registry))
+
+	// Watch events tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "watch_events",
+		Description: "Watch Kubernetes events (events.k8s.io watch API) for duration_seconds (default 60, at most 600), streaming new events, repeat counts and alerts as progress (or log) notifications. Repeats are deduplicated by series and message; an alert is raised when Warning events for a mesh component (istiod, gateways, node agents, Sail resources, the injection webhook) cross storm_threshold per storm_window_seconds",
+	}, k8shandlers.WatchEvents(k8sClient))
+
+	// Build incident timeline tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "build_incident_timeline",
+		Description: "Build one chronological timeline for a namespace or an Istio control plane (the Istio, its IstioRevisions and tags and its namespace) over a time window (since_seconds, default 1 hour), merging Kubernetes events, Sail condition lastTransitionTimes, pod starts, container restarts and terminations and ReplicaSet rollouts, deduplicated and grouped by involved object",
+	}, k8shandlers.BuildIncidentTimeline(k8sClient, dynamicClient, registry))
+
+	// Diagnose pod tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "diagnose_pod",
+		Description: "Explain why a pod is failing or restarting: reads each container's state and last termination (exit code, OOMKilled), the previous logs of the crashed container, the pod's events and its probe and resource configuration, and classifies the failure (oom_killed, liveness_probe_failure, image_pull_error, sidecar_not_ready, crash_loop, ...) with recommendations",
+	}, k8shandlers.DiagnosePod(k8sClient))
+
+	// Analyze access logs tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "analyze_access_logs",
+		Description: "Parse Envoy access logs of a sidecar or gateway (istio-proxy container by default, or logs passed in) in Istio's TEXT or JSON format and aggregate them per destination: requests, status codes, error rate, latency percentiles and response flags (UH, UF, NR, URX, DC...) explained in plain language, with findings such as '60% of 503s to reviews.default are UF'",
+	}, k8shandlers.AnalyzeAccessLogs(k8sClient))
+
 	// Check mesh workloads tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "check_mesh_workloads",
-		Description: "Check the status of workloads in the Istio mesh including sidecar injection status",
-	}, k8shandlers.CheckMeshWorkloads(k8sClient))
+		Description: "Check the status of workloads in the Istio mesh including sidecar injection status. Checks at most limit pods (default 500) within the server's response budget; when more exist the result has a next_cursor to pass as cursor to check the next page",
+	}, k8shandlers.CheckMeshWorkloads(k8sClient, budget))
 
-	log.Println("Registered Kubernetes tools: test_k8s_connection, list_namespaces, get_namespace_details, list_pods, list_services, list_deployments, list_configmaps, list_events, get_pod_logs, check_mesh_workloads")
+	log.Println("Registered Kubernetes tools: test_k8s_connection, list_namespaces, get_namespace_details, list_pods, list_services, list_deployments, list_configmaps, get_resource, describe_resource, list_events, watch_events, build_incident_timeline, get_pod_logs, get_workload_logs, diagnose_pod, analyze_access_logs, check_mesh_workloads")
 }
 
 // registerSailOperatorTools registers Sail Operator CRD-related MCP tools
-func registerSailOperatorTools(server *mcp.Server, dynamicClient dynamic.Interface) {
+func registerSailOperatorTools(server *mcp.Server, k8sClient *kubernetes.Clientset, dynamicClient dynamic.Interface, registry *sail.Registry, cfg Config) {
+	// List Sail Operator CRDs
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_sail_crds",
+		Description: "Report which Sail Operator CRDs are installed, their served and preferred API versions and whether they are established",
+	}, sailoperatorhandlers.ListSailCRDs(registry))
+
 	// List Sail Operator resources
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_sailoperator_resources",
-		Description: "List Sail Operator CRD resources (Istio, IstioRevision, IstioCNI, ZTunnel)",
-	}, sailoperatorhandlers.ListSailOperatorResources(dynamicClient))
+		Description: "List Sail Operator CRD resources (Istio, IstioRevision, IstioRevisionTag, IstioCNI, ZTunnel)",
+	}, sailoperatorhandlers.ListSailOperatorResources(dynamicClient, registry))
 
 	// Get Istio status
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_istio_status",
 		Description: "Get detailed status information about Istio installations",
-	}, sailoperatorhandlers.GetIstioStatus(dynamicClient))
+	}, sailoperatorhandlers.GetIstioStatus(dynamicClient, registry))
+
+	// Get Istio values
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_istio_values",
+		Description: "Show the values set on an Istio resource, the values rendered into its IstioRevisions and a structured diff between them or between any two revisions, optionally with the server's approximate profile defaults merged in (the result is then marked approximate)",
+	}, sailoperatorhandlers.GetIstioValues(dynamicClient, registry))
+
+	// IstioCNI node coverage
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_istiocni_status",
+		Description: "Compare the IstioCNI DaemonSet with the cluster nodes, explain nodes without a ready CNI pod using taints, tolerations and node selection, and report the CNI plugin chaining configuration",
+	}, sailoperatorhandlers.GetIstioCNIStatus(k8sClient, dynamicClient, registry))
+
+	// ZTunnel node coverage
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_ztunnel_status",
+		Description: "Compare the ZTunnel DaemonSet with the cluster nodes and explain nodes without a ready ztunnel pod using taints, tolerations and node selection",
+	}, sailoperatorhandlers.GetZTunnelStatus(k8sClient, dynamicClient, registry))
+
+	// Check injection webhooks
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "check_injection_webhooks",
+		Description: "Check the istio-sidecar-injector and istio-validator webhook configurations: map them to IstioRevisions and tags, verify the webhook services have ready endpoints, check caBundle expiry and flag namespace selectors that would inject pods twice",
+	}, sailoperatorhandlers.CheckInjectionWebhooks(k8sClient, dynamicClient, registry))
+
+	// Generate Istio manifests
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "generate_istio_manifest",
+		Description: "Generate Istio, IstioCNI, ZTunnel and IstioRevisionTag YAML from high-level intent (version, profile, update strategy, ambient, mesh ID, trust domain) and validate it against the installed CRDs with a server-side dry-run create",
+	}, sailoperatorhandlers.GenerateIstioManifest(dynamicClient, registry))
 
 	// Check Sail Operator health
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "check_sailoperator_health",
-		Description: "Perform comprehensive health checks on Sail Operator managed resources",
-	}, sailoperatorhandlers.CheckSailOperatorHealth(dynamicClient))
+		Description: "Perform comprehensive health checks on Sail Operator managed resources, including unreconciled spec changes and conditions stuck in a non-True state",
+	}, sailoperatorhandlers.CheckSailOperatorHealth(dynamicClient, registry, cfg.HealthRules))
+
+	log.Println("Registered Sail Operator tools: list_sail_crds, list_sailoperator_resources, get_istio_status, get_istio_values, get_istiocni_status, get_ztunnel_status, check_injection_webhooks, generate_istio_manifest, check_sailoperator_health")
+}
+
+// registerWriteTools registers the tools that modify the cluster. Each of them
+// dry-runs the change and asks the user to confirm it before applying.
+func registerWriteTools(server *mcp.Server, dynamicClient dynamic.Interface, registry *sail.Registry, guard *sailoperatorhandlers.WriteGuard) {
+	// Patch Istio version
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "patch_istio_version",
+		Description: "Change spec.version of an Istio resource. The change is dry-run on the server and applied only after the user confirms it",
+	}, sailoperatorhandlers.PatchIstioVersion(dynamicClient, registry, guard))
+
+	// Set update strategy
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "set_update_strategy",
+		Description: "Change the update strategy (InPlace or RevisionBased) of an Istio resource. The change is dry-run on the server and applied only after the user confirms it",
+	}, sailoperatorhandlers.SetUpdateStrategy(dynamicClient, registry, guard))
+
+	// Switch revision tag
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "switch_revision_tag",
+		Description: "Point an IstioRevisionTag at another Istio or IstioRevision. The change is dry-run on the server and applied only after the user confirms it",
+	}, sailoperatorhandlers.SwitchRevisionTag(dynamicClient, registry, guard))
+
+	// Restart workloads for revision
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "restart_workloads_for_revision",
+		Description: "Rolling-restart the Deployments, StatefulSets and DaemonSets injected by a revision or its tags so they pick up its proxy. The restarts are dry-run on the server and applied only after the user confirms them",
+	}, sailoperatorhandlers.RestartWorkloadsForRevision(dynamicClient, registry, guard))
 
-	log.Println("Registered Sail Operator tools: list_sailoperator_resources, get_istio_status, check_sailoperator_health")
+	log.Println("Registered write tools: patch_istio_version, set_update_strategy, switch_revision_tag, restart_workloads_for_revision")
 }
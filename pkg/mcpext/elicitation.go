@@ -0,0 +1,46 @@
+package mcpext
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrElicitationUnsupported is returned when the client did not declare the
+// elicitation capability
+var ErrElicitationUnsupported = errors.New("client does not support elicitation")
+
+// ElicitParams is the request to ask the user for input
+type ElicitParams struct {
+	Message string `json:"message"`
+	// RequestedSchema is a flat JSON schema object describing the requested fields
+	RequestedSchema map[string]any `json:"requestedSchema"`
+}
+
+// ElicitResult is the user's answer
+type ElicitResult struct {
+	Action  string         `json:"action"` // accept|decline|cancel
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// Elicitor asks the user for input through the MCP client
+type Elicitor interface {
+	SupportsElicitation() bool
+	Elicit(ctx context.Context, params *ElicitParams) (*ElicitResult, error)
+}
+
+// SupportsElicitation reports whether the client declared the elicitation capability
+func (t *Transport) SupportsElicitation() bool {
+	return t.hasClientCapability("elicitation")
+}
+
+// Elicit sends an elicitation/create request to the client and waits for the answer
+func (t *Transport) Elicit(ctx context.Context, params *ElicitParams) (*ElicitResult, error) {
+	if !t.SupportsElicitation() {
+		return nil, ErrElicitationUnsupported
+	}
+	var result ElicitResult
+	if err := t.call(ctx, "elicitation/create", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,154 @@
+package mcpext
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// OnSubscribe registers a function called whenever the client subscribes to
+// a new resource URI. Setting it makes the server advertise the resources
+// subscribe capability.
+func (t *Transport) OnSubscribe(fn func(uri string)) {
+	t.mu.Lock()
+	t.onSubscribe = fn
+	t.mu.Unlock()
+}
+
+// OnUnsubscribe registers a function called whenever a subscription ends,
+// because the client unsubscribed or the session closed
+func (t *Transport) OnUnsubscribe(fn func(uri string)) {
+	t.mu.Lock()
+	t.onUnsubscribe = fn
+	t.mu.Unlock()
+}
+
+// NotifyResourceUpdated sends notifications/resources/updated for every
+// subscription to uri. Subscriptions that differ from uri only in their query
+// (e.g. ?format=json) are notified too. It does nothing without a subscription.
+func (t *Transport) NotifyResourceUpdated(uri string) error {
+	t.mu.Lock()
+	var targets []string
+	for subscribed := range t.subscriptions {
+		if subscribed == uri || strings.HasPrefix(subscribed, uri+"?") {
+			targets = append(targets, subscribed)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, target := range targets {
+		if err := t.notify("notifications/resources/updated", map[string]any{"uri": target}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleSubscription answers resources/subscribe and resources/unsubscribe,
+// which the SDK does not implement. It reports false for any other request.
+func (t *Transport) handleSubscription(req *jsonrpc.Request) bool {
+	if req.Method != "resources/subscribe" && req.Method != "resources/unsubscribe" {
+		return false
+	}
+
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		_ = t.writeRaw(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID.Raw(),
+			"error":   map[string]any{"code": -32602, "message": "invalid params: uri is required"},
+		})
+		return true
+	}
+
+	t.mu.Lock()
+	subscribed := t.subscriptions[params.URI]
+	if req.Method == "resources/subscribe" {
+		t.subscriptions[params.URI] = true
+		if !subscribed && t.onSubscribe != nil {
+			t.dispatch(t.onSubscribe, params.URI)
+		}
+	} else {
+		delete(t.subscriptions, params.URI)
+		if subscribed && t.onUnsubscribe != nil {
+			t.dispatch(t.onUnsubscribe, params.URI)
+		}
+	}
+	t.mu.Unlock()
+
+	_ = t.writeRaw(map[string]any{"jsonrpc": "2.0", "id": req.ID.Raw(), "result": map[string]any{}})
+	return true
+}
+
+// endSubscriptions ends every subscription when the session closes
+func (t *Transport) endSubscriptions() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for uri := range t.subscriptions {
+		delete(t.subscriptions, uri)
+		if t.onUnsubscribe != nil {
+			t.dispatch(t.onUnsubscribe, uri)
+		}
+	}
+}
+
+// dispatch queues a subscription callback. Callbacks run one at a time in
+// the order they were queued, off the read loop, so a slow subscribe does
+// not hold up the session and an unsubscribe never overtakes it. The caller
+// holds mu.
+func (t *Transport) dispatch(fn func(uri string), uri string) {
+	t.callbacks = append(t.callbacks, func() { fn(uri) })
+	if t.dispatching {
+		return
+	}
+	t.dispatching = true
+	go func() {
+		for {
+			t.mu.Lock()
+			if len(t.callbacks) == 0 {
+				t.dispatching = false
+				t.mu.Unlock()
+				return
+			}
+			callback := t.callbacks[0]
+			t.callbacks = t.callbacks[1:]
+			t.mu.Unlock()
+			callback()
+		}
+	}()
+}
+
+// advertiseSubscribe adds capabilities.resources.subscribe to the initialize
+// response, so clients know they may subscribe
+func (t *Transport) advertiseSubscribe(resp *jsonrpc.Response) {
+	t.mu.Lock()
+	isInitialize := t.initializeID.IsValid() && resp.ID == t.initializeID
+	enabled := t.onSubscribe != nil
+	t.mu.Unlock()
+	if !isInitialize || !enabled || resp.Error != nil {
+		return
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return
+	}
+	capabilities, _ := result["capabilities"].(map[string]any)
+	if capabilities == nil {
+		capabilities = map[string]any{}
+		result["capabilities"] = capabilities
+	}
+	resources, _ := capabilities["resources"].(map[string]any)
+	if resources == nil {
+		resources = map[string]any{}
+		capabilities["resources"] = resources
+	}
+	resources["subscribe"] = true
+
+	if data, err := json.Marshal(result); err == nil {
+		resp.Result = data
+	}
+}
@@ -0,0 +1,231 @@
+// Package mcpext adds MCP protocol features the go-sdk release in use does not
+// implement yet. It wraps the SDK stdio transport and handles the extra
+// messages itself, passing everything else through to the SDK unchanged.
+package mcpext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrConnectionClosed is returned for requests to the client that were still
+// waiting for an answer when the connection closed
+var ErrConnectionClosed = errors.New("connection closed")
+
+// requestIDPrefix marks the JSON-RPC IDs of requests this package sends, so
+// their responses can be told apart from responses to the SDK's own requests
+const requestIDPrefix = "mcp-sail-operator-"
+
+// Transport is an mcp.Transport over stdin/stdout that additionally lets the
+// server send requests the SDK has no API for
+type Transport struct {
+	delegate mcp.Transport
+	out      io.Writer
+
+	// writeMu serializes everything written to out, whether it comes from the
+	// SDK or from this package
+	writeMu sync.Mutex
+
+	mu                 sync.Mutex
+	clientCapabilities map[string]json.RawMessage
+	initializeID       jsonrpc.ID
+	nextID             int64
+	pending            map[string]chan *jsonrpc.Response
+	closed             bool
+
+	// subscriptions holds the resource URIs the client subscribed to
+	subscriptions map[string]bool
+	onSubscribe   func(uri string)
+	onUnsubscribe func(uri string)
+	callbacks     []func()
+	dispatching   bool
+}
+
+// NewStdioTransport creates a transport over stdin/stdout, like mcp.NewStdioTransport
+func NewStdioTransport() *Transport {
+	return &Transport{
+		delegate: mcp.NewStdioTransport(),
+		out:      os.Stdout,
+		pending:  make(map[string]chan *jsonrpc.Response),
+
+		subscriptions: make(map[string]bool),
+	}
+}
+
+// Connect implements mcp.Transport
+func (t *Transport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.delegate.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &connection{transport: t, delegate: conn}, nil
+}
+
+// hasClientCapability reports whether the client declared the named capability during initialization
+func (t *Transport) hasClientCapability(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	raw, ok := t.clientCapabilities[name]
+	return ok && string(raw) != "null"
+}
+
+// call sends a request to the client and waits for its response
+func (t *Transport) call(ctx context.Context, method string, params, result any) error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrConnectionClosed
+	}
+	t.nextID++
+	id := fmt.Sprintf("%s%d", requestIDPrefix, t.nextID)
+	ch := make(chan *jsonrpc.Response, 1)
+	t.pending[id] = ch
+	t.mu.Unlock()
+
+	if err := t.writeRaw(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		t.forget(id)
+		return fmt.Errorf("calling %q: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.forget(id)
+		_ = t.notify("notifications/cancelled", map[string]any{"requestId": id, "reason": ctx.Err().Error()})
+		return ctx.Err()
+	case resp := <-ch:
+		if resp == nil {
+			return fmt.Errorf("calling %q: %w", method, ErrConnectionClosed)
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("calling %q: %w", method, resp.Error)
+		}
+		if result != nil {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("decoding %q result: %w", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// notify sends a notification to the client
+func (t *Transport) notify(method string, params any) error {
+	return t.writeRaw(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (t *Transport) writeRaw(msg map[string]any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.out.Write(append(data, '\n'))
+	return err
+}
+
+func (t *Transport) forget(id string) {
+	t.mu.Lock()
+	delete(t.pending, id)
+	t.mu.Unlock()
+}
+
+// deliver hands a response to the request waiting for it; it reports false
+// for responses that belong to the SDK
+func (t *Transport) deliver(resp *jsonrpc.Response) bool {
+	id, ok := resp.ID.Raw().(string)
+	if !ok {
+		return false
+	}
+	t.mu.Lock()
+	ch, ok := t.pending[id]
+	delete(t.pending, id)
+	t.mu.Unlock()
+	if ok {
+		ch <- resp
+	}
+	return ok
+}
+
+// closePending fails every request still waiting for a response
+func (t *Transport) closePending() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for id, ch := range t.pending {
+		close(ch)
+		delete(t.pending, id)
+	}
+}
+
+// connection intercepts the messages this package handles and passes all
+// others through to the SDK
+type connection struct {
+	transport *Transport
+	delegate  mcp.Connection
+}
+
+func (c *connection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		msg, err := c.delegate.Read(ctx)
+		if err != nil {
+			c.transport.closePending()
+			c.transport.endSubscriptions()
+			return nil, err
+		}
+		switch m := msg.(type) {
+		case *jsonrpc.Request:
+			if m.Method == "initialize" {
+				c.transport.recordInitialize(m.ID, m.Params)
+			}
+			if c.transport.handleSubscription(m) {
+				continue
+			}
+		case *jsonrpc.Response:
+			if c.transport.deliver(m) {
+				continue
+			}
+		}
+		return msg, nil
+	}
+}
+
+func (c *connection) Write(ctx context.Context, msg jsonrpc.Message) error {
+	if resp, ok := msg.(*jsonrpc.Response); ok {
+		c.transport.advertiseSubscribe(resp)
+	}
+	c.transport.writeMu.Lock()
+	defer c.transport.writeMu.Unlock()
+	return c.delegate.Write(ctx, msg)
+}
+
+func (c *connection) Close() error {
+	c.transport.closePending()
+	c.transport.endSubscriptions()
+	return c.delegate.Close()
+}
+
+func (c *connection) SessionID() string { return c.delegate.SessionID() }
+
+// recordInitialize keeps the capabilities the client declared and the request
+// ID, so the matching response can be recognized
+func (t *Transport) recordInitialize(id jsonrpc.ID, params json.RawMessage) {
+	var init struct {
+		Capabilities map[string]json.RawMessage `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &init); err != nil {
+		return
+	}
+	t.mu.Lock()
+	t.initializeID = id
+	t.clientCapabilities = init.Capabilities
+	t.mu.Unlock()
+}
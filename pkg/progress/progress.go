@@ -0,0 +1,64 @@
+// Package progress reports the progress of long-running tool calls to the MCP client
+package progress
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Reporter sends notifications/progress for one request. It does nothing when
+// the client did not send a progress token or there is no session, as when a
+// handler is called from the CLI. It is safe for concurrent use.
+type Reporter struct {
+	session *mcp.ServerSession
+	token   any
+
+	mu       sync.Mutex
+	progress float64
+	total    float64
+}
+
+// New creates a reporter for a request; total is the expected number of
+// steps, or 0 when unknown
+func New(session *mcp.ServerSession, params interface{ GetProgressToken() any }, total int) *Reporter {
+	r := &Reporter{session: session, total: float64(total)}
+	if params != nil {
+		r.token = params.GetProgressToken()
+	}
+	return r
+}
+
+// Enabled reports whether notifications are actually sent
+func (r *Reporter) Enabled() bool {
+	return r != nil && r.session != nil && r.token != nil
+}
+
+// Step records that one more step finished and reports it with message
+func (r *Reporter) Step(ctx context.Context, message string) {
+	r.Advance(ctx, 1, 0, message)
+}
+
+// Advance records n finished steps and reports them with message. A total
+// above 0 replaces the expected number of steps.
+func (r *Reporter) Advance(ctx context.Context, n int, total int, message string) {
+	if !r.Enabled() {
+		return
+	}
+	r.mu.Lock()
+	r.progress += float64(n)
+	if total > 0 {
+		r.total = float64(total)
+	}
+	params := &mcp.ProgressNotificationParams{
+		ProgressToken: r.token,
+		Progress:      r.progress,
+		Total:         r.total,
+		Message:       message,
+	}
+	r.mu.Unlock()
+
+	// Progress is best effort; a failed notification must not fail the tool
+	_ = r.session.NotifyProgress(ctx, params)
+}
@@ -0,0 +1,234 @@
+package sail
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Typed models of the Sail Operator CRDs. They cover the fields the server
+// reads; anything else is ignored when decoding (see Decode).
+
+// Condition is a status condition of a Sail resource
+type Condition struct {
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// BaseStatus holds the status fields shared by every Sail resource
+type BaseStatus struct {
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+	State              string      `json:"state,omitempty"`
+}
+
+// CommonSpec holds the spec fields shared by most Sail resources
+type CommonSpec struct {
+	Version   string `json:"version,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Object is the kind-agnostic view of any Sail resource
+type Object struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CommonSpec `json:"spec,omitempty"`
+	Status            BaseStatus `json:"status,omitempty"`
+}
+
+// Istio is the user-facing control plane resource
+type Istio struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              IstioSpec   `json:"spec,omitempty"`
+	Status            IstioStatus `json:"status,omitempty"`
+}
+
+// IstioSpec is the desired state of an Istio resource
+type IstioSpec struct {
+	CommonSpec     `json:",inline"`
+	UpdateStrategy *IstioUpdateStrategy `json:"updateStrategy,omitempty"`
+	Values         *Values              `json:"values,omitempty"`
+}
+
+// IstioUpdateStrategy defines how the control plane is updated
+type IstioUpdateStrategy struct {
+	Type                                       string `json:"type,omitempty"` // InPlace|RevisionBased
+	InactiveRevisionDeletionGracePeriodSeconds *int64 `json:"inactiveRevisionDeletionGracePeriodSeconds,omitempty"`
+	UpdateWorkloads                            *bool  `json:"updateWorkloads,omitempty"`
+}
+
+// IstioStatus is the observed state of an Istio resource
+type IstioStatus struct {
+	BaseStatus         `json:",inline"`
+	ActiveRevisionName string          `json:"activeRevisionName,omitempty"`
+	Revisions          RevisionSummary `json:"revisions,omitempty"`
+}
+
+// RevisionSummary counts the revisions owned by an Istio resource
+type RevisionSummary struct {
+	Total int32 `json:"total,omitempty"`
+	Ready int32 `json:"ready,omitempty"`
+	InUse int32 `json:"inUse,omitempty"`
+}
+
+// IstioRevision is a single control plane revision rendered from an Istio resource
+type IstioRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              IstioRevisionSpec `json:"spec,omitempty"`
+	Status            BaseStatus        `json:"status,omitempty"`
+}
+
+// IstioRevisionSpec is the desired state of an IstioRevision
+type IstioRevisionSpec struct {
+	Version   string  `json:"version,omitempty"`
+	Namespace string  `json:"namespace,omitempty"`
+	Values    *Values `json:"values,omitempty"`
+}
+
+// IstioRevisionTag points a stable tag at an Istio or IstioRevision
+type IstioRevisionTag struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              IstioRevisionTagSpec   `json:"spec,omitempty"`
+	Status            IstioRevisionTagStatus `json:"status,omitempty"`
+}
+
+// IstioRevisionTagSpec is the desired state of an IstioRevisionTag
+type IstioRevisionTagSpec struct {
+	TargetRef TargetRef `json:"targetRef"`
+}
+
+// TargetRef references the Istio or IstioRevision a tag points at
+type TargetRef struct {
+	Kind string `json:"kind"` // Istio|IstioRevision
+	Name string `json:"name"`
+}
+
+// IstioRevisionTagStatus is the observed state of an IstioRevisionTag
+type IstioRevisionTagStatus struct {
+	BaseStatus      `json:",inline"`
+	IstioRevision   string `json:"istioRevision,omitempty"`
+	IstiodNamespace string `json:"istiodNamespace,omitempty"`
+}
+
+// IstioCNI installs the Istio CNI node agent
+type IstioCNI struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              IstioCNISpec `json:"spec,omitempty"`
+	Status            BaseStatus   `json:"status,omitempty"`
+}
+
+// IstioCNISpec is the desired state of an IstioCNI
+type IstioCNISpec struct {
+	CommonSpec `json:",inline"`
+	Values     *CNIValues `json:"values,omitempty"`
+}
+
+// ZTunnel installs the ambient mode node proxy
+type ZTunnel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ZTunnelSpec `json:"spec,omitempty"`
+	Status            BaseStatus  `json:"status,omitempty"`
+}
+
+// ZTunnelSpec is the desired state of a ZTunnel
+type ZTunnelSpec struct {
+	CommonSpec `json:",inline"`
+	Values     *ZTunnelValues `json:"values,omitempty"`
+}
+
+// Values are the Helm values of the istiod chart. Raw holds the complete
+// tree, including fields without a typed representation.
+type Values struct {
+	Global          *GlobalConfig `json:"global,omitempty"`
+	Pilot           *PilotConfig  `json:"pilot,omitempty"`
+	MeshConfig      *MeshConfig   `json:"meshConfig,omitempty"`
+	Revision        string        `json:"revision,omitempty"`
+	DefaultRevision string        `json:"defaultRevision,omitempty"`
+	Profile         string        `json:"profile,omitempty"`
+
+	Raw map[string]interface{} `json:"-"`
+}
+
+// GlobalConfig holds values shared by all Istio charts
+type GlobalConfig struct {
+	Hub            string              `json:"hub,omitempty"`
+	Tag            string              `json:"tag,omitempty"`
+	MeshID         string              `json:"meshID,omitempty"`
+	Network        string              `json:"network,omitempty"`
+	IstioNamespace string              `json:"istioNamespace,omitempty"`
+	Platform       string              `json:"platform,omitempty"`
+	MultiCluster   *MultiClusterConfig `json:"multiCluster,omitempty"`
+}
+
+// MultiClusterConfig identifies the cluster in a multi-cluster mesh
+type MultiClusterConfig struct {
+	ClusterName string `json:"clusterName,omitempty"`
+}
+
+// PilotConfig holds istiod values
+type PilotConfig struct {
+	Enabled                 *bool                        `json:"enabled,omitempty"`
+	Hub                     string                       `json:"hub,omitempty"`
+	Image                   string                       `json:"image,omitempty"`
+	Tag                     string                       `json:"tag,omitempty"`
+	ReplicaCount            *int32                       `json:"replicaCount,omitempty"`
+	Env                     map[string]string            `json:"env,omitempty"`
+	Resources               *corev1.ResourceRequirements `json:"resources,omitempty"`
+	TrustedZtunnelNamespace string                       `json:"trustedZtunnelNamespace,omitempty"`
+}
+
+// MeshConfig holds the subset of mesh-wide settings the server reads
+type MeshConfig struct {
+	TrustDomain       string `json:"trustDomain,omitempty"`
+	AccessLogFile     string `json:"accessLogFile,omitempty"`
+	AccessLogEncoding string `json:"accessLogEncoding,omitempty"`
+}
+
+// CNIValues are the Helm values of the istio-cni chart
+type CNIValues struct {
+	Cni    *CNIConfig    `json:"cni,omitempty"`
+	Global *GlobalConfig `json:"global,omitempty"`
+
+	Raw map[string]interface{} `json:"-"`
+}
+
+// CNIConfig holds the CNI node agent values
+type CNIConfig struct {
+	Chained         *bool                        `json:"chained,omitempty"`
+	CniBinDir       string                       `json:"cniBinDir,omitempty"`
+	CniConfDir      string                       `json:"cniConfDir,omitempty"`
+	CniConfFileName string                       `json:"cniConfFileName,omitempty"`
+	Provider        string                       `json:"provider,omitempty"`
+	Ambient         *CNIAmbientConfig            `json:"ambient,omitempty"`
+	Resources       *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// CNIAmbientConfig holds the ambient settings of the CNI node agent
+type CNIAmbientConfig struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// ZTunnelValues are the Helm values of the ztunnel chart
+type ZTunnelValues struct {
+	ZTunnel *ZTunnelConfig `json:"ztunnel,omitempty"`
+	Global  *GlobalConfig  `json:"global,omitempty"`
+
+	Raw map[string]interface{} `json:"-"`
+}
+
+// ZTunnelConfig holds the ztunnel values
+type ZTunnelConfig struct {
+	Hub       string                       `json:"hub,omitempty"`
+	Image     string                       `json:"image,omitempty"`
+	Tag       string                       `json:"tag,omitempty"`
+	Env       map[string]string            `json:"env,omitempty"`
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
@@ -0,0 +1,181 @@
+package sail
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// Decode converts an unstructured Sail resource into one of the typed models
+// in this package. Decoding is tolerant: unknown fields are ignored and fields
+// that do not decode into the model, whether of the wrong type or malformed
+// like a bad timestamp or quantity, are left unset instead of failing the
+// whole object, so newer operator releases keep working.
+func Decode[T any](u *unstructured.Unstructured) (*T, error) {
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+
+	obj := new(T)
+	if !decodeTolerant(data, reflect.ValueOf(obj).Elem()) {
+		return nil, fmt.Errorf("failed to decode %s %s: not an object", u.GetKind(), u.GetName())
+	}
+
+	setRawValues(any(obj), u)
+	return obj, nil
+}
+
+// decodeTolerant decodes data into v. When that fails, objects, arrays and
+// maps are decoded member by member, so a member that does not decode only
+// leaves itself unset. It reports whether anything was decoded.
+func decodeTolerant(data []byte, v reflect.Value) bool {
+	if json.Unmarshal(data, v.Addr().Interface()) == nil {
+		return true
+	}
+	v.Set(reflect.Zero(v.Type()))
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if !decodeTolerant(data, elem.Elem()) {
+			return false
+		}
+		v.Set(elem)
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			return false
+		}
+		decodeFields(fields, v)
+	case reflect.Slice:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return false
+		}
+		slice := reflect.MakeSlice(v.Type(), 0, len(items))
+		for _, item := range items {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if decodeTolerant(item, elem) {
+				slice = reflect.Append(slice, elem)
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		var members map[string]json.RawMessage
+		if v.Type().Key().Kind() != reflect.String || json.Unmarshal(data, &members) != nil {
+			return false
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(members))
+		for key, member := range members {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if decodeTolerant(member, elem) {
+				m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+			}
+		}
+		v.Set(m)
+	default:
+		return false
+	}
+	return true
+}
+
+// decodeFields decodes the members of a JSON object into the fields of the
+// struct v, following the json tags and inlining embedded structs
+func decodeFields(fields map[string]json.RawMessage, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			decodeFields(fields, v.Field(i))
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if data, ok := fieldData(fields, name); ok {
+			decodeTolerant(data, v.Field(i))
+		}
+	}
+}
+
+// fieldData finds the member of a field, preferring an exact key match and
+// falling back to a case-insensitive one as encoding/json does
+func fieldData(fields map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if data, ok := fields[name]; ok {
+		return data, true
+	}
+	for key, data := range fields {
+		if strings.EqualFold(key, name) {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// setRawValues keeps the complete spec.values tree next to its typed form
+func setRawValues(obj any, u *unstructured.Unstructured) {
+	raw, found, _ := unstructured.NestedMap(u.Object, "spec", "values")
+	if !found {
+		return
+	}
+	switch o := obj.(type) {
+	case *Istio:
+		if o.Spec.Values != nil {
+			o.Spec.Values.Raw = raw
+		}
+	case *IstioRevision:
+		if o.Spec.Values != nil {
+			o.Spec.Values.Raw = raw
+		}
+	case *IstioCNI:
+		if o.Spec.Values != nil {
+			o.Spec.Values.Raw = raw
+		}
+	case *ZTunnel:
+		if o.Spec.Values != nil {
+			o.Spec.Values.Raw = raw
+		}
+	}
+}
+
+// FindCondition returns the condition of the given type, or nil
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// ResourceConditions converts typed conditions into the tool result representation
+func ResourceConditions(conditions []Condition) []types.ResourceCondition {
+	var result []types.ResourceCondition
+	for _, c := range conditions {
+		condition := types.ResourceCondition{
+			Type:    c.Type,
+			Status:  c.Status,
+			Reason:  c.Reason,
+			Message: c.Message,
+		}
+		if !c.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = c.LastTransitionTime.UTC().Format(time.RFC3339)
+		}
+		result = append(result, condition)
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package sail
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDecodeIstio(t *testing.T) {
+	transition := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		object map[string]interface{}
+		check  func(t *testing.T, istio *Istio)
+	}{
+		{
+			name: "unknown fields are ignored",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "default"},
+				"spec": map[string]interface{}{
+					"version":      "v1.26.0",
+					"futureField":  map[string]interface{}{"nested": true},
+					"anotherField": "x",
+				},
+			},
+			check: func(t *testing.T, istio *Istio) {
+				if istio.Name != "default" || istio.Spec.Version != "v1.26.0" {
+					t.Errorf("got name %q version %q", istio.Name, istio.Spec.Version)
+				}
+			},
+		},
+		{
+			name: "wrong-typed fields are left unset",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "default"},
+				"spec": map[string]interface{}{
+					"version":   int64(126),
+					"namespace": "istio-system",
+				},
+				"status": map[string]interface{}{
+					"revisions": map[string]interface{}{"total": "two", "ready": int64(1)},
+				},
+			},
+			check: func(t *testing.T, istio *Istio) {
+				if istio.Spec.Version != "" {
+					t.Errorf("version = %q, want unset", istio.Spec.Version)
+				}
+				if istio.Spec.Namespace != "istio-system" {
+					t.Errorf("namespace = %q, want istio-system", istio.Spec.Namespace)
+				}
+				if istio.Status.Revisions.Total != 0 || istio.Status.Revisions.Ready != 1 {
+					t.Errorf("revisions = %+v, want total unset and ready 1", istio.Status.Revisions)
+				}
+			},
+		},
+		{
+			name: "missing status",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "default"},
+				"spec":     map[string]interface{}{"version": "v1.26.0"},
+			},
+			check: func(t *testing.T, istio *Istio) {
+				if istio.Status.State != "" || len(istio.Status.Conditions) != 0 {
+					t.Errorf("status = %+v, want empty", istio.Status)
+				}
+			},
+		},
+		{
+			name: "condition transition time",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "default"},
+				"status": map[string]interface{}{
+					"state": "Healthy",
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "True", "lastTransitionTime": "2025-03-14T09:26:53Z"},
+					},
+				},
+			},
+			check: func(t *testing.T, istio *Istio) {
+				ready := FindCondition(istio.Status.Conditions, "Ready")
+				if ready == nil || !ready.LastTransitionTime.Time.Equal(transition) {
+					t.Errorf("Ready condition = %+v, want transition at %s", ready, transition)
+				}
+			},
+		},
+		{
+			name: "malformed timestamp only drops itself",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "default", "creationTimestamp": "yesterday"},
+				"spec":     map[string]interface{}{"version": "v1.26.0"},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False", "reason": "ReconcileError", "lastTransitionTime": "not a time"},
+					},
+				},
+			},
+			check: func(t *testing.T, istio *Istio) {
+				if istio.Name != "default" || istio.Spec.Version != "v1.26.0" {
+					t.Errorf("got name %q version %q", istio.Name, istio.Spec.Version)
+				}
+				if !istio.CreationTimestamp.IsZero() {
+					t.Errorf("creationTimestamp = %s, want unset", istio.CreationTimestamp)
+				}
+				ready := FindCondition(istio.Status.Conditions, "Ready")
+				if ready == nil || ready.Reason != "ReconcileError" || !ready.LastTransitionTime.IsZero() {
+					t.Errorf("Ready condition = %+v, want reason kept and time unset", ready)
+				}
+			},
+		},
+		{
+			name: "malformed quantity only drops itself",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "default"},
+				"spec": map[string]interface{}{
+					"values": map[string]interface{}{
+						"pilot": map[string]interface{}{
+							"image": "pilot",
+							"resources": map[string]interface{}{
+								"requests": map[string]interface{}{"cpu": "500m", "memory": "lots"},
+							},
+						},
+					},
+				},
+			},
+			check: func(t *testing.T, istio *Istio) {
+				pilot := istio.Spec.Values.Pilot
+				if pilot == nil || pilot.Image != "pilot" || pilot.Resources == nil {
+					t.Fatalf("pilot = %+v, want image and resources", pilot)
+				}
+				requests := pilot.Resources.Requests
+				if cpu := requests["cpu"]; cpu.Cmp(resource.MustParse("500m")) != 0 {
+					t.Errorf("cpu request = %s, want 500m", cpu.String())
+				}
+				if _, ok := requests["memory"]; ok {
+					t.Errorf("memory request = %v, want unset", requests["memory"])
+				}
+				if istio.Spec.Values.Raw == nil {
+					t.Errorf("raw values not kept")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: tt.object}
+			u.SetAPIVersion("sailoperator.io/v1")
+			u.SetKind("Istio")
+			istio, err := Decode[Istio](u)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			tt.check(t, istio)
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package sail
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// Group is the API group of all Sail Operator resources
+const Group = "sailoperator.io"
+
+// ResourceKind identifies a Sail Operator resource type
+type ResourceKind struct {
+	Kind     string // e.g. Istio
+	Resource string // plural resource name, e.g. istios
+}
+
+// CRDName returns the name of the CustomResourceDefinition serving the kind
+func (k ResourceKind) CRDName() string {
+	return k.Resource + "." + Group
+}
+
+var (
+	KindIstio            = ResourceKind{Kind: "Istio", Resource: "istios"}
+	KindIstioRevision    = ResourceKind{Kind: "IstioRevision", Resource: "istiorevisions"}
+	KindIstioRevisionTag = ResourceKind{Kind: "IstioRevisionTag", Resource: "istiorevisiontags"}
+	KindIstioCNI         = ResourceKind{Kind: "IstioCNI", Resource: "istiocnis"}
+	KindZTunnel          = ResourceKind{Kind: "ZTunnel", Resource: "ztunnels"}
+)
+
+// Kinds lists every Sail Operator resource kind known to the server
+var Kinds = []ResourceKind{KindIstio, KindIstioRevision, KindIstioRevisionTag, KindIstioCNI, KindZTunnel}
+
+// LookupKind finds a Sail resource kind by kind, singular or plural name, case-insensitively
+func LookupKind(name string) (ResourceKind, bool) {
+	for _, k := range Kinds {
+		if strings.EqualFold(name, k.Kind) || strings.EqualFold(name, k.Resource) {
+			return k, true
+		}
+	}
+	return ResourceKind{}, false
+}
+
+// rediscoverAfter is how old discovery data may be before a lookup of a
+// missing kind triggers a new discovery round
+const rediscoverAfter = 30 * time.Second
+
+// refreshTimeout bounds a discovery round, which runs detached from the
+// caller that started it so that its waiters are not cut short
+const refreshTimeout = 30 * time.Second
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// NotInstalledError is returned when a Sail resource kind is not served by the cluster
+type NotInstalledError struct {
+	Kind string
+}
+
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("%s CRD is not installed", e.Kind)
+}
+
+// IsNotInstalled reports whether err is a NotInstalledError
+func IsNotInstalled(err error) bool {
+	_, ok := err.(*NotInstalledError)
+	return ok
+}
+
+// Registry discovers which Sail Operator CRDs the cluster serves and which
+// API version to use for each kind
+type Registry struct {
+	discovery discovery.DiscoveryInterface
+	dynamic   dynamic.Interface
+
+	mu           sync.RWMutex
+	crds         map[string]types.SailCRDInfo
+	gvErrors     map[string]string // discovery errors by group version
+	discoveredAt time.Time
+	refreshing   *refreshCall
+}
+
+// refreshCall is a discovery round in flight; concurrent refreshes wait for
+// it instead of starting their own
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewRegistry creates a registry; discovery runs on the first lookup or Refresh
+func NewRegistry(discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface) *Registry {
+	return &Registry{
+		discovery: discoveryClient,
+		dynamic:   dynamicClient,
+		crds:      make(map[string]types.SailCRDInfo),
+	}
+}
+
+// Refresh re-runs API discovery for the Sail Operator group and reads the CRD
+// objects to find out whether they are established. Concurrent calls share
+// one discovery round, which runs on its own context with refreshTimeout;
+// each caller stops waiting when its own ctx ends.
+func (r *Registry) Refresh(ctx context.Context) error {
+	r.mu.Lock()
+	call := r.refreshing
+	if call == nil {
+		call = &refreshCall{done: make(chan struct{})}
+		r.refreshing = call
+		go r.runRefresh(context.WithoutCancel(ctx), call)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runRefresh runs a shared discovery round and wakes its waiters
+func (r *Registry) runRefresh(ctx context.Context, call *refreshCall) {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	call.err = r.refresh(ctx)
+
+	r.mu.Lock()
+	r.refreshing = nil
+	r.mu.Unlock()
+	close(call.done)
+}
+
+func (r *Registry) refresh(ctx context.Context) error {
+	crds := make(map[string]types.SailCRDInfo)
+	gvErrors := make(map[string]string)
+	for _, k := range Kinds {
+		crds[k.Kind] = types.SailCRDInfo{Kind: k.Kind, Resource: k.Resource, CRDName: k.CRDName()}
+	}
+
+	groups, err := r.discovery.ServerGroups()
+	if err != nil {
+		return fmt.Errorf("failed to discover API groups: %w", err)
+	}
+
+	var group *metav1.APIGroup
+	for i := range groups.Groups {
+		if groups.Groups[i].Name == Group {
+			group = &groups.Groups[i]
+			break
+		}
+	}
+
+	if group != nil {
+		// Versions are listed in server priority order
+		for _, gv := range group.Versions {
+			resources, err := r.discovery.ServerResourcesForGroupVersion(gv.GroupVersion)
+			if err != nil {
+				gvErrors[gv.GroupVersion] = err.Error()
+				continue
+			}
+			for _, res := range resources.APIResources {
+				if strings.Contains(res.Name, "/") {
+					continue // subresource
+				}
+				k, ok := LookupKind(res.Name)
+				if !ok {
+					continue
+				}
+				info := crds[k.Kind]
+				info.Installed = true
+				info.Namespaced = res.Namespaced
+				info.ServedVersions = append(info.ServedVersions, gv.Version)
+				if info.PreferredVersion == "" || gv.Version == group.PreferredVersion.Version {
+					info.PreferredVersion = gv.Version
+				}
+				crds[k.Kind] = info
+			}
+		}
+	}
+
+	for _, k := range Kinds {
+		info := crds[k.Kind]
+		r.inspectCRD(ctx, k, &info, gvErrors)
+		sort.Slice(info.ServedVersions, func(i, j int) bool {
+			return version.CompareKubeAwareVersionStrings(info.ServedVersions[i], info.ServedVersions[j]) > 0
+		})
+		crds[k.Kind] = info
+	}
+
+	r.mu.Lock()
+	r.crds = crds
+	r.gvErrors = gvErrors
+	r.discoveredAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// inspectCRD reads the CustomResourceDefinition object for a kind. Reading CRDs
+// needs cluster-scoped RBAC, so failures only leave Established unset. The
+// kind's error names a group version whose discovery failed only when the
+// CRD serves that version.
+func (r *Registry) inspectCRD(ctx context.Context, k ResourceKind, info *types.SailCRDInfo, gvErrors map[string]string) {
+	crd, err := r.dynamic.Resource(crdGVR).Get(ctx, k.CRDName(), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) && info.Error == "" {
+			info.Error = fmt.Sprintf("failed to read CRD %s: %v", k.CRDName(), err)
+		}
+		return
+	}
+
+	info.Installed = true
+	established := false
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, condRaw := range conditions {
+		if condMap, ok := condRaw.(map[string]interface{}); ok && condMap["type"] == "Established" {
+			established = condMap["status"] == "True"
+		}
+	}
+	info.Established = &established
+
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, vRaw := range versions {
+		vMap, ok := vRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := vMap["name"].(string)
+		if storage, _ := vMap["storage"].(bool); storage {
+			info.StorageVersion = name
+		}
+		gv := Group + "/" + name
+		if served, _ := vMap["served"].(bool); served && gvErrors[gv] != "" && info.Error == "" {
+			info.Error = fmt.Sprintf("failed to discover %s: %s", gv, gvErrors[gv])
+		}
+	}
+}
+
+// CRDs returns the discovered state of every Sail resource kind, running
+// discovery first if it has not happened yet
+func (r *Registry) CRDs(ctx context.Context) ([]types.SailCRDInfo, time.Time, error) {
+	if err := r.ensureDiscovered(ctx, ""); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var crds []types.SailCRDInfo
+	for _, k := range Kinds {
+		crds = append(crds, r.crds[k.Kind])
+	}
+	return crds, r.discoveredAt, nil
+}
+
+// DiscoveryErrors returns the errors of the last discovery round by group
+// version; the kinds those versions serve may be missing from CRDs
+func (r *Registry) DiscoveryErrors() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	errs := make(map[string]string, len(r.gvErrors))
+	for gv, err := range r.gvErrors {
+		errs[gv] = err
+	}
+	return errs
+}
+
+// GVR returns the group, preferred served version and resource of a Sail
+// kind. It returns a NotInstalledError when the cluster does not serve it.
+func (r *Registry) GVR(ctx context.Context, k ResourceKind) (schema.GroupVersionResource, error) {
+	if err := r.ensureDiscovered(ctx, k.Kind); err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+
+	r.mu.RLock()
+	info := r.crds[k.Kind]
+	r.mu.RUnlock()
+
+	if info.PreferredVersion == "" {
+		return schema.GroupVersionResource{}, &NotInstalledError{Kind: k.Kind}
+	}
+	return schema.GroupVersionResource{Group: Group, Version: info.PreferredVersion, Resource: k.Resource}, nil
+}
+
+// ensureDiscovered runs discovery if it never ran, or if the requested kind is
+// not served and the discovery data is old enough that it may have been installed since
+func (r *Registry) ensureDiscovered(ctx context.Context, kind string) error {
+	r.mu.RLock()
+	discoveredAt := r.discoveredAt
+	served := r.crds[kind].PreferredVersion != ""
+	r.mu.RUnlock()
+
+	if discoveredAt.IsZero() || (kind != "" && !served && time.Since(discoveredAt) > rediscoverAfter) {
+		return r.Refresh(ctx)
+	}
+	return nil
+}
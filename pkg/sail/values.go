@@ -0,0 +1,251 @@
+package sail
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+// ProfileDefaultsSource names the operator release and profile version the
+// profileDefaults table was taken from
+const ProfileDefaultsSource = "sail-operator 1.0 profiles for Istio v1.26"
+
+// profileDefaults approximates the values each built-in Sail profile sets,
+// as found in ProfileDefaultsSource. The operator ships the full profiles
+// with each Istio version; this table only covers the settings that most
+// often explain differences between control planes and must not be read as
+// the authoritative defaults, especially for other operator releases.
+var profileDefaults = map[string]map[string]interface{}{
+	"default": {
+		"global": map[string]interface{}{
+			"istioNamespace": "istio-system",
+			"proxy": map[string]interface{}{
+				"clusterDomain": "cluster.local",
+			},
+		},
+		"pilot": map[string]interface{}{
+			"autoscaleEnabled": true,
+			"autoscaleMin":     int64(1),
+			"autoscaleMax":     int64(5),
+			"replicaCount":     int64(1),
+			"traceSampling":    float64(1),
+		},
+		"meshConfig": map[string]interface{}{
+			"enablePrometheusMerge": true,
+		},
+	},
+	"demo": {
+		"pilot": map[string]interface{}{
+			"traceSampling": float64(100),
+		},
+		"meshConfig": map[string]interface{}{
+			"accessLogFile": "/dev/stdout",
+		},
+	},
+	"ambient": {
+		"profile": "ambient",
+		"pilot": map[string]interface{}{
+			"trustedZtunnelNamespace": "ztunnel",
+			"env": map[string]interface{}{
+				"PILOT_ENABLE_AMBIENT": "true",
+			},
+		},
+		"meshConfig": map[string]interface{}{
+			"defaultConfig": map[string]interface{}{
+				"proxyMetadata": map[string]interface{}{
+					"ISTIO_META_ENABLE_HBONE": "true",
+				},
+			},
+		},
+	},
+	"openshift": {
+		"global": map[string]interface{}{
+			"platform": "openshift",
+		},
+		"pilot": map[string]interface{}{
+			"cni": map[string]interface{}{
+				"enabled": true,
+			},
+		},
+	},
+	"openshift-ambient": {
+		"global": map[string]interface{}{
+			"platform": "openshift",
+		},
+		"profile": "ambient",
+		"pilot": map[string]interface{}{
+			"trustedZtunnelNamespace": "ztunnel",
+			"cni": map[string]interface{}{
+				"enabled": true,
+			},
+			"env": map[string]interface{}{
+				"PILOT_ENABLE_AMBIENT": "true",
+			},
+		},
+	},
+	"remote": {
+		"istiodRemote": map[string]interface{}{
+			"enabled": true,
+		},
+		"pilot": map[string]interface{}{
+			"configMap": false,
+		},
+	},
+	"empty": {},
+}
+
+// ProfileDefaults returns the approximate values of a built-in profile layered
+// on top of the default profile, as the operator does. The second result is
+// false for profiles the server does not know.
+func ProfileDefaults(profile string) (map[string]interface{}, bool) {
+	if profile == "" {
+		profile = "default"
+	}
+	overlay, ok := profileDefaults[profile]
+	if !ok {
+		return nil, false
+	}
+	if profile == "empty" {
+		return map[string]interface{}{}, true
+	}
+	return MergeValues(profileDefaults["default"], overlay), true
+}
+
+// Profiles lists the names of the built-in profiles the server knows
+func Profiles() []string {
+	var names []string
+	for name := range profileDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MergeValues deep-merges overlay on top of base without modifying either.
+// Maps are merged key by key; any other value in overlay replaces the one in base.
+func MergeValues(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = copyValue(v)
+	}
+	for k, v := range overlay {
+		baseMap, baseIsMap := merged[k].(map[string]interface{})
+		overlayMap, overlayIsMap := v.(map[string]interface{})
+		if baseIsMap && overlayIsMap {
+			merged[k] = MergeValues(baseMap, overlayMap)
+			continue
+		}
+		merged[k] = copyValue(v)
+	}
+	return merged
+}
+
+func copyValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return MergeValues(t, nil)
+	case []interface{}:
+		c := make([]interface{}, len(t))
+		for i := range t {
+			c[i] = copyValue(t[i])
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// DiffValues compares two values trees and returns one entry per leaf path
+// that was added, removed or changed going from a to b, sorted by path.
+// Lists are compared as a whole.
+func DiffValues(a, b map[string]interface{}) []types.ValueDiff {
+	var diffs []types.ValueDiff
+	diffValues("", a, b, &diffs)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+func diffValues(prefix string, a, b map[string]interface{}, diffs *[]types.ValueDiff) {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+
+	for k := range keys {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		av, inA := a[k]
+		bv, inB := b[k]
+
+		aMap, aIsMap := av.(map[string]interface{})
+		bMap, bIsMap := bv.(map[string]interface{})
+		switch {
+		case inA && inB && aIsMap && bIsMap:
+			diffValues(path, aMap, bMap, diffs)
+		case !inA && bIsMap:
+			diffValues(path, nil, bMap, diffs)
+		case aIsMap && !inB:
+			diffValues(path, aMap, nil, diffs)
+		case !inA:
+			*diffs = append(*diffs, types.ValueDiff{Path: path, Change: "added", To: bv})
+		case !inB:
+			*diffs = append(*diffs, types.ValueDiff{Path: path, Change: "removed", From: av})
+		case !valuesEqual(av, bv):
+			*diffs = append(*diffs, types.ValueDiff{Path: path, Change: "changed", From: av, To: bv})
+		}
+	}
+}
+
+// valuesEqual compares two leaf values, treating numbers of different Go
+// types as equal when they hold the same value (unstructured data mixes
+// int64 and float64)
+func valuesEqual(a, b interface{}) bool {
+	if af, ok := toFloat(a); ok {
+		if bf, ok := toFloat(b); ok {
+			return af == bf
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// FormatValue renders a leaf value for text output
+func FormatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "<unset>"
+	case string:
+		return fmt.Sprintf("%q", t)
+	case []interface{}:
+		var items []string
+		for _, item := range t {
+			items = append(items, FormatValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
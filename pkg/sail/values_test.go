@@ -0,0 +1,160 @@
+package sail
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/frherrer/mcp-sail-operator/pkg/types"
+)
+
+func TestMergeValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    map[string]interface{}
+		overlay map[string]interface{}
+		want    map[string]interface{}
+	}{
+		{
+			name:    "nil inputs",
+			base:    nil,
+			overlay: nil,
+			want:    map[string]interface{}{},
+		},
+		{
+			name:    "maps merge key by key",
+			base:    map[string]interface{}{"pilot": map[string]interface{}{"replicaCount": int64(1), "autoscaleEnabled": true}},
+			overlay: map[string]interface{}{"pilot": map[string]interface{}{"replicaCount": int64(3)}},
+			want:    map[string]interface{}{"pilot": map[string]interface{}{"replicaCount": int64(3), "autoscaleEnabled": true}},
+		},
+		{
+			name:    "scalar replaces map",
+			base:    map[string]interface{}{"pilot": map[string]interface{}{"cni": map[string]interface{}{"enabled": true}}},
+			overlay: map[string]interface{}{"pilot": map[string]interface{}{"cni": false}},
+			want:    map[string]interface{}{"pilot": map[string]interface{}{"cni": false}},
+		},
+		{
+			name:    "lists are replaced whole",
+			base:    map[string]interface{}{"meshConfig": map[string]interface{}{"extensionProviders": []interface{}{"a", "b"}}},
+			overlay: map[string]interface{}{"meshConfig": map[string]interface{}{"extensionProviders": []interface{}{"c"}}},
+			want:    map[string]interface{}{"meshConfig": map[string]interface{}{"extensionProviders": []interface{}{"c"}}},
+		},
+		{
+			name:    "new keys are added",
+			base:    map[string]interface{}{"global": map[string]interface{}{"hub": "docker.io/istio"}},
+			overlay: map[string]interface{}{"revision": "canary"},
+			want:    map[string]interface{}{"global": map[string]interface{}{"hub": "docker.io/istio"}, "revision": "canary"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeValues(tt.base, tt.overlay); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeValuesDoesNotModifyInputs(t *testing.T) {
+	base := map[string]interface{}{"pilot": map[string]interface{}{"env": map[string]interface{}{"A": "1"}}}
+	overlay := map[string]interface{}{"pilot": map[string]interface{}{"env": map[string]interface{}{"B": "2"}}}
+
+	merged := MergeValues(base, overlay)
+	merged["pilot"].(map[string]interface{})["env"].(map[string]interface{})["C"] = "3"
+
+	if env := base["pilot"].(map[string]interface{})["env"].(map[string]interface{}); len(env) != 1 {
+		t.Errorf("base env = %v, want only A", env)
+	}
+	if env := overlay["pilot"].(map[string]interface{})["env"].(map[string]interface{}); len(env) != 1 {
+		t.Errorf("overlay env = %v, want only B", env)
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b map[string]interface{}
+		want []types.ValueDiff
+	}{
+		{
+			name: "equal trees",
+			a:    map[string]interface{}{"pilot": map[string]interface{}{"replicaCount": int64(2)}},
+			b:    map[string]interface{}{"pilot": map[string]interface{}{"replicaCount": int64(2)}},
+			want: nil,
+		},
+		{
+			name: "numbers of different types are equal",
+			a:    map[string]interface{}{"pilot": map[string]interface{}{"traceSampling": int64(1)}},
+			b:    map[string]interface{}{"pilot": map[string]interface{}{"traceSampling": float64(1)}},
+			want: nil,
+		},
+		{
+			name: "added, removed and changed leaves sorted by path",
+			a: map[string]interface{}{
+				"global": map[string]interface{}{"hub": "docker.io/istio", "tag": "1.25.0"},
+				"pilot":  map[string]interface{}{"replicaCount": int64(1)},
+			},
+			b: map[string]interface{}{
+				"global":     map[string]interface{}{"tag": "1.26.0"},
+				"pilot":      map[string]interface{}{"replicaCount": int64(1)},
+				"meshConfig": map[string]interface{}{"accessLogFile": "/dev/stdout"},
+			},
+			want: []types.ValueDiff{
+				{Path: "global.hub", Change: "removed", From: "docker.io/istio"},
+				{Path: "global.tag", Change: "changed", From: "1.25.0", To: "1.26.0"},
+				{Path: "meshConfig.accessLogFile", Change: "added", To: "/dev/stdout"},
+			},
+		},
+		{
+			name: "removed subtree lists its leaves",
+			a:    map[string]interface{}{"pilot": map[string]interface{}{"cni": map[string]interface{}{"enabled": true, "provider": "multus"}}},
+			b:    map[string]interface{}{},
+			want: []types.ValueDiff{
+				{Path: "pilot.cni.enabled", Change: "removed", From: true},
+				{Path: "pilot.cni.provider", Change: "removed", From: "multus"},
+			},
+		},
+		{
+			name: "lists compare as a whole",
+			a:    map[string]interface{}{"meshConfig": map[string]interface{}{"extensionProviders": []interface{}{"a", "b"}}},
+			b:    map[string]interface{}{"meshConfig": map[string]interface{}{"extensionProviders": []interface{}{"a"}}},
+			want: []types.ValueDiff{
+				{Path: "meshConfig.extensionProviders", Change: "changed", From: []interface{}{"a", "b"}, To: []interface{}{"a"}},
+			},
+		},
+		{
+			name: "map replaced by scalar",
+			a:    map[string]interface{}{"pilot": map[string]interface{}{"cni": map[string]interface{}{"enabled": true}}},
+			b:    map[string]interface{}{"pilot": map[string]interface{}{"cni": false}},
+			want: []types.ValueDiff{
+				{Path: "pilot.cni", Change: "changed", From: map[string]interface{}{"enabled": true}, To: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffValues(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffValues() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileDefaults(t *testing.T) {
+	ambient, ok := ProfileDefaults("ambient")
+	if !ok {
+		t.Fatal("ambient profile not known")
+	}
+	if ambient["profile"] != "ambient" {
+		t.Errorf("profile = %v, want ambient", ambient["profile"])
+	}
+	// The default profile is layered underneath
+	if pilot := ambient["pilot"].(map[string]interface{}); pilot["autoscaleEnabled"] != true {
+		t.Errorf("pilot.autoscaleEnabled = %v, want the default profile's true", pilot["autoscaleEnabled"])
+	}
+
+	if _, ok := ProfileDefaults("no-such-profile"); ok {
+		t.Error("unknown profile reported as known")
+	}
+}
@@ -47,6 +47,18 @@ type GetNamespaceDetailsResult struct {
 type ListPodsParams struct {
 	Namespace     string `json:"namespace,omitempty"`
 	LabelSelector string `json:"label_selector,omitempty"`
+	FieldSelector string `json:"field_selector,omitempty"`
+	NodeName      string `json:"node_name,omitempty"`
+	Phase         string `json:"phase,omitempty"` // Pending|Running|Succeeded|Failed|Unknown
+	// Computed filters, applied to the whole selection before paging
+	NotReady           bool   `json:"not_ready,omitempty"`            // only pods that are not Ready, completed pods excepted
+	RestartsAbove      *int32 `json:"restarts_above,omitempty"`       // only pods restarted more than this many times
+	OlderThanSeconds   int64  `json:"older_than_seconds,omitempty"`   // only pods created longer ago
+	YoungerThanSeconds int64  `json:"younger_than_seconds,omitempty"` // only pods created more recently
+	HasSidecar         *bool  `json:"has_sidecar,omitempty"`          // true: only pods with an istio-proxy container, false: only pods without
+	SortBy             string `json:"sort_by,omitempty"`              // age (newest first), restarts (most first) or name; orders the whole selection
+	Limit              int64  `json:"limit,omitempty"`                // items per call, defaults to 500
+	Cursor             string `json:"cursor,omitempty"`               // next_cursor of the previous call
 }
 
 // PodInfo represents information about a pod
@@ -66,16 +78,20 @@ type PodInfo struct {
 
 // ListPodsResult represents the result of listing pods
 type ListPodsResult struct {
-	Status string    `json:"status"`
-	Pods   []PodInfo `json:"pods,omitempty"`
-	Count  int       `json:"count,omitempty"`
-	Error  string    `json:"error,omitempty"`
+	Status     string    `json:"status"`
+	Pods       []PodInfo `json:"pods,omitempty"`
+	Count      int       `json:"count,omitempty"`
+	NextCursor string    `json:"next_cursor,omitempty"` // set when more pods follow
+	Remaining  int64     `json:"remaining,omitempty"`   // pods known to follow, when counted
+	Error      string    `json:"error,omitempty"`
 }
 
 // ListServicesParams represents parameters for listing services
 type ListServicesParams struct {
 	Namespace     string `json:"namespace,omitempty"`
 	LabelSelector string `json:"label_selector,omitempty"`
+	Limit         int64  `json:"limit,omitempty"`  // items per call, defaults to 500
+	Cursor        string `json:"cursor,omitempty"` // next_cursor of the previous call
 }
 
 // ServiceInfo represents information about a service
@@ -102,16 +118,20 @@ type ServicePort struct {
 
 // ListServicesResult represents the result of listing services
 type ListServicesResult struct {
-	Status   string        `json:"status"`
-	Services []ServiceInfo `json:"services,omitempty"`
-	Count    int           `json:"count,omitempty"`
-	Error    string        `json:"error,omitempty"`
+	Status     string        `json:"status"`
+	Services   []ServiceInfo `json:"services,omitempty"`
+	Count      int           `json:"count,omitempty"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	Remaining  int64         `json:"remaining,omitempty"`
+	Error      string        `json:"error,omitempty"`
 }
 
 // ListDeploymentsParams represents parameters for listing deployments
 type ListDeploymentsParams struct {
 	Namespace     string `json:"namespace,omitempty"`
 	LabelSelector string `json:"label_selector,omitempty"`
+	Limit         int64  `json:"limit,omitempty"`  // items per call, defaults to 500
+	Cursor        string `json:"cursor,omitempty"` // next_cursor of the previous call
 }
 
 // DeploymentInfo represents information about a deployment
@@ -132,6 +152,8 @@ type ListDeploymentsResult struct {
 	Status      string           `json:"status"`
 	Deployments []DeploymentInfo `json:"deployments,omitempty"`
 	Count       int              `json:"count,omitempty"`
+	NextCursor  string           `json:"next_cursor,omitempty"`
+	Remaining   int64            `json:"remaining,omitempty"`
 	Error       string           `json:"error,omitempty"`
 }
 
@@ -139,6 +161,8 @@ type ListDeploymentsResult struct {
 type ListConfigMapsParams struct {
 	Namespace     string `json:"namespace,omitempty"`
 	LabelSelector string `json:"label_selector,omitempty"`
+	Limit         int64  `json:"limit,omitempty"`  // items per call, defaults to 500
+	Cursor        string `json:"cursor,omitempty"` // next_cursor of the previous call
 }
 
 // ConfigMapInfo represents information about a configmap
@@ -156,6 +180,8 @@ type ListConfigMapsResult struct {
 	Status     string          `json:"status"`
 	ConfigMaps []ConfigMapInfo `json:"configmaps,omitempty"`
 	Count      int             `json:"count,omitempty"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Remaining  int64           `json:"remaining,omitempty"`
 	Error      string          `json:"error,omitempty"`
 }
 
@@ -168,6 +194,28 @@ type GetPodLogsParams struct {
 	Follow       bool   `json:"follow,omitempty"`
 	Previous     bool   `json:"previous,omitempty"`
 	SinceSeconds int64  `json:"since_seconds,omitempty"`
+	// Follow mode stops at the first of these; without lines or since_seconds only new lines are followed
+	FollowSeconds int64  `json:"follow_seconds,omitempty"` // defaults to 30, at most 600
+	MaxLines      int64  `json:"max_lines,omitempty"`
+	Until         string `json:"until,omitempty"` // regex; stop at the first matching line
+	// Server-side filtering; include, exclude and min_level also apply in follow mode
+	Include  string `json:"include,omitempty"`   // regex lines must match
+	Exclude  string `json:"exclude,omitempty"`   // regex lines must not match
+	MinLevel string `json:"min_level,omitempty"` // debug|info|warn|error, from Istio, Envoy, klog or JSON logs
+	Dedup    bool   `json:"dedup,omitempty"`     // collapse repeated messages into counts
+	MaxBytes int    `json:"max_bytes,omitempty"` // keep the newest lines within this size
+	Output   string `json:"output,omitempty"`    // lines (default) or summary
+	Top      int    `json:"top,omitempty"`       // signatures in summary output, defaults to 10
+}
+
+// LogSignature aggregates repeated warnings or errors in a log summary
+type LogSignature struct {
+	Signature string `json:"signature"`
+	Level     string `json:"level"`
+	Count     int    `json:"count"`
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+	Example   string `json:"example"`
 }
 
 // GetPodLogsResult represents the result of getting pod logs
@@ -175,12 +223,262 @@ type GetPodLogsResult struct {
 	Status string `json:"status"`
 	Logs   string `json:"logs,omitempty"`
 	Error  string `json:"error,omitempty"`
+	// Set in follow mode
+	StopReason    string `json:"stop_reason,omitempty"` // until|max_lines|timeout|stream_ended|cancelled
+	LinesStreamed int    `json:"lines_streamed,omitempty"`
+	MatchedLine   string `json:"matched_line,omitempty"`
+	Duration      string `json:"duration,omitempty"`
+	// Set when filtering, dedup, a byte budget or summary output was requested
+	LinesRead    int            `json:"lines_read,omitempty"`
+	LinesMatched int            `json:"lines_matched,omitempty"`
+	Dropped      int            `json:"dropped,omitempty"` // lines left out to stay under max_bytes
+	Summary      []LogSignature `json:"summary,omitempty"`
+}
+
+// AnalyzeAccessLogsParams represents parameters for analysing Envoy access logs.
+// The logs are read from a pod's istio-proxy container unless logs is given.
+type AnalyzeAccessLogsParams struct {
+	Namespace    string `json:"namespace,omitempty"`
+	PodName      string `json:"pod_name,omitempty"`
+	Container    string `json:"container,omitempty"` // defaults to istio-proxy
+	Lines        int64  `json:"lines,omitempty"`     // defaults to 1000
+	SinceSeconds int64  `json:"since_seconds,omitempty"`
+	Logs         string `json:"logs,omitempty"` // access log text to analyse instead of reading a pod
+}
+
+// ResponseFlagCount counts an Envoy response flag and explains it
+type ResponseFlagCount struct {
+	Flag    string `json:"flag"`
+	Meaning string `json:"meaning"`
+	Count   int    `json:"count"`
+}
+
+// AccessLogDestination aggregates the access log entries of one destination
+type AccessLogDestination struct {
+	Destination   string              `json:"destination"`
+	Requests      int                 `json:"requests"`
+	Errors        int                 `json:"errors"` // 5xx, and responses without a code that carry a flag
+	ErrorRate     float64             `json:"error_rate"`
+	StatusCodes   map[string]int      `json:"status_codes"`
+	ResponseFlags []ResponseFlagCount `json:"response_flags,omitempty"`
+	DurationP50Ms int64               `json:"duration_p50_ms"`
+	DurationP95Ms int64               `json:"duration_p95_ms"`
+	DurationMaxMs int64               `json:"duration_max_ms"`
+}
+
+// AnalyzeAccessLogsResult represents the result of analysing Envoy access logs
+type AnalyzeAccessLogsResult struct {
+	Status        string                 `json:"status"`
+	Source        string                 `json:"source"`
+	LinesRead     int                    `json:"lines_read"`
+	Entries       int                    `json:"entries"`
+	Skipped       int                    `json:"skipped"` // lines that were not access log entries
+	Destinations  []AccessLogDestination `json:"destinations"`
+	ResponseFlags []ResponseFlagCount    `json:"response_flags,omitempty"`
+	Findings      []string               `json:"findings,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// DiagnosePodParams represents parameters for diagnosing a failing or restarting pod
+type DiagnosePodParams struct {
+	Namespace string `json:"namespace"`
+	PodName   string `json:"pod_name"`
+	Container string `json:"container,omitempty"` // whose previous logs to read; defaults to the one that restarted most
+	LogLines  int64  `json:"log_lines,omitempty"` // previous log lines to include, defaults to 50
+}
+
+// ContainerTermination describes how a container last terminated
+type ContainerTermination struct {
+	Reason     string `json:"reason,omitempty"`
+	ExitCode   int32  `json:"exit_code"`
+	Signal     int32  `json:"signal,omitempty"`
+	Message    string `json:"message,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// ProbeInfo summarises a container probe
+type ProbeInfo struct {
+	Type                string `json:"type"`    // liveness|readiness|startup
+	Handler             string `json:"handler"` // e.g. "httpGet :8080/healthz"
+	InitialDelaySeconds int32  `json:"initial_delay_seconds"`
+	PeriodSeconds       int32  `json:"period_seconds"`
+	TimeoutSeconds      int32  `json:"timeout_seconds"`
+	FailureThreshold    int32  `json:"failure_threshold"`
+}
+
+// ContainerDiagnosis is the state, configuration and history of one container
+type ContainerDiagnosis struct {
+	Name            string                `json:"name"`
+	Init            bool                  `json:"init,omitempty"`
+	Ready           bool                  `json:"ready"`
+	RestartCount    int32                 `json:"restart_count"`
+	State           string                `json:"state"` // running|waiting|terminated
+	Reason          string                `json:"reason,omitempty"`
+	Message         string                `json:"message,omitempty"`
+	LastTermination *ContainerTermination `json:"last_termination,omitempty"`
+	Requests        map[string]string     `json:"requests,omitempty"`
+	Limits          map[string]string     `json:"limits,omitempty"`
+	Probes          []ProbeInfo           `json:"probes,omitempty"`
+}
+
+// DiagnosisFinding is one observation of a pod diagnosis
+type DiagnosisFinding struct {
+	Severity  string `json:"severity"` // error|warning|info
+	Category  string `json:"category"`
+	Container string `json:"container,omitempty"`
+	Message   string `json:"message"`
+}
+
+// DiagnosePodResult represents the result of diagnosing a pod
+type DiagnosePodResult struct {
+	Status          string               `json:"status"`
+	Namespace       string               `json:"namespace"`
+	Pod             string               `json:"pod"`
+	Phase           string               `json:"phase"`
+	NodeName        string               `json:"node_name,omitempty"`
+	Classification  string               `json:"classification"` // e.g. oom_killed, liveness_probe_failure, image_pull_error, sidecar_not_ready
+	Summary         string               `json:"summary"`
+	Findings        []DiagnosisFinding   `json:"findings,omitempty"`
+	Recommendations []string             `json:"recommendations,omitempty"`
+	Containers      []ContainerDiagnosis `json:"containers"`
+	Events          []EventInfo          `json:"events,omitempty"`
+	LogsContainer   string               `json:"logs_container,omitempty"`
+	PreviousLogs    string               `json:"previous_logs,omitempty"`
+	LogsError       string               `json:"logs_error,omitempty"`
+	Error           string               `json:"error,omitempty"`
+}
+
+// WatchEventsParams represents parameters for watching events
+type WatchEventsParams struct {
+	Namespace          string `json:"namespace,omitempty"` // all namespaces when empty
+	InvolvedKind       string `json:"involved_kind,omitempty"`
+	InvolvedName       string `json:"involved_name,omitempty"`
+	WarningsOnly       bool   `json:"warnings_only,omitempty"`
+	DurationSeconds    int64  `json:"duration_seconds,omitempty"`     // defaults to 60, at most 600
+	StormThreshold     int    `json:"storm_threshold,omitempty"`      // Warning events per window that raise an alert, defaults to 10
+	StormWindowSeconds int64  `json:"storm_window_seconds,omitempty"` // defaults to 60
+}
+
+// WatchedEvent aggregates the occurrences of one event while watching
+type WatchedEvent struct {
+	Object    string `json:"object"` // Kind/namespace/name
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Count     int    `json:"count"`
+	Component string `json:"component,omitempty"` // the mesh component involved, if any
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
+}
+
+// EventStormAlert reports Warning events for a mesh component crossing the rate threshold
+type EventStormAlert struct {
+	Component     string `json:"component"`
+	Reason        string `json:"reason"`
+	Count         int    `json:"count"`
+	WindowSeconds int64  `json:"window_seconds"`
+	Time          string `json:"time"`
+	Example       string `json:"example"`
+}
+
+// WatchEventsResult represents the result of watching events
+type WatchEventsResult struct {
+	Status      string            `json:"status"`
+	StopReason  string            `json:"stop_reason"` // timeout|cancelled|error
+	Duration    string            `json:"duration"`
+	Occurrences int               `json:"occurrences"` // including repeats within series
+	Unique      int               `json:"unique"`
+	Events      []WatchedEvent    `json:"events"` // most frequent first
+	Alerts      []EventStormAlert `json:"alerts,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// BuildIncidentTimelineParams represents parameters for building an incident timeline.
+// Exactly one of namespace or istio selects what the timeline covers.
+type BuildIncidentTimelineParams struct {
+	Namespace    string `json:"namespace,omitempty"`
+	Istio        string `json:"istio,omitempty"`         // an Istio resource, its revisions and control plane namespace
+	SinceSeconds int64  `json:"since_seconds,omitempty"` // window, defaults to 3600
+	WarningsOnly bool   `json:"warnings_only,omitempty"`
+	MaxEntries   int    `json:"max_entries,omitempty"` // newest entries kept, defaults to 300
+}
+
+// TimelineEntry is one deduplicated occurrence on an incident timeline
+type TimelineEntry struct {
+	Time     string `json:"time"`
+	LastTime string `json:"last_time,omitempty"` // set when the entry repeated
+	Source   string `json:"source"`              // event|condition|pod|container|rollout
+	Object   string `json:"object"`              // Kind/namespace/name
+	Type     string `json:"type"`                // Normal|Warning
+	Reason   string `json:"reason"`
+	Message  string `json:"message,omitempty"`
+	Count    int    `json:"count"`
+}
+
+// TimelineObject groups the timeline entries of one involved object
+type TimelineObject struct {
+	Object    string `json:"object"`
+	Entries   int    `json:"entries"`
+	Warnings  int    `json:"warnings"`
+	FirstTime string `json:"first_time"`
+	LastTime  string `json:"last_time"`
+}
+
+// BuildIncidentTimelineResult represents a chronological incident timeline
+type BuildIncidentTimelineResult struct {
+	Status  string           `json:"status"`
+	Scope   string           `json:"scope"`
+	Since   string           `json:"since"`
+	Entries []TimelineEntry  `json:"entries"`
+	Objects []TimelineObject `json:"objects"`
+	Dropped int              `json:"dropped,omitempty"` // older entries beyond max_entries
+	Notes   []string         `json:"notes,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// GetWorkloadLogsParams represents parameters for getting the logs of every pod of a workload.
+// Exactly one of label_selector, deployment, daemonset or istio_revision selects the pods.
+type GetWorkloadLogsParams struct {
+	Namespace     string `json:"namespace,omitempty"` // required except for istio_revision
+	LabelSelector string `json:"label_selector,omitempty"`
+	Deployment    string `json:"deployment,omitempty"`
+	DaemonSet     string `json:"daemonset,omitempty"`
+	IstioRevision string `json:"istio_revision,omitempty"` // the revision's istiod pods
+	Container     string `json:"container,omitempty"`      // all containers when empty
+	Lines         int64  `json:"lines,omitempty"`          // per container, defaults to 100
+	SinceSeconds  int64  `json:"since_seconds,omitempty"`
+	Previous      bool   `json:"previous,omitempty"`
+	MaxBytes      int    `json:"max_bytes,omitempty"` // total cap, defaults to 262144; the oldest lines are dropped first
+}
+
+// WorkloadLogStream describes the logs read from one container
+type WorkloadLogStream struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Lines     int    `json:"lines"`
+	Error     string `json:"error,omitempty"`
+}
+
+// GetWorkloadLogsResult represents the interleaved logs of a workload
+type GetWorkloadLogsResult struct {
+	Status    string              `json:"status"`
+	Namespace string              `json:"namespace,omitempty"`
+	Selector  string              `json:"selector,omitempty"`
+	Streams   []WorkloadLogStream `json:"streams,omitempty"`
+	Lines     int                 `json:"lines"`
+	Bytes     int                 `json:"bytes"`
+	Dropped   int                 `json:"dropped,omitempty"` // oldest lines left out to stay under max_bytes
+	Logs      string              `json:"logs,omitempty"`
+	Error     string              `json:"error,omitempty"`
 }
 
 // CheckMeshWorkloadsParams represents parameters for checking mesh workloads
 type CheckMeshWorkloadsParams struct {
 	Namespace     string `json:"namespace,omitempty"`
 	LabelSelector string `json:"label_selector,omitempty"`
+	Limit         int64  `json:"limit,omitempty"`  // items per call, defaults to 500
+	Cursor        string `json:"cursor,omitempty"` // next_cursor of the previous call
 }
 
 // WorkloadInfo represents information about a workload in the mesh
@@ -198,10 +496,12 @@ type WorkloadInfo struct {
 
 // CheckMeshWorkloadsResult represents the result of checking mesh workloads
 type CheckMeshWorkloadsResult struct {
-	Status    string         `json:"status"`
-	Workloads []WorkloadInfo `json:"workloads,omitempty"`
-	Count     int            `json:"count,omitempty"`
-	Error     string         `json:"error,omitempty"`
+	Status     string         `json:"status"`
+	Workloads  []WorkloadInfo `json:"workloads,omitempty"`
+	Count      int            `json:"count,omitempty"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Remaining  int64          `json:"remaining,omitempty"`
+	Error      string         `json:"error,omitempty"`
 }
 
 // ListEventsParams represents parameters for listing Events
@@ -237,3 +537,81 @@ type ListEventsResult struct {
 	Count  int         `json:"count,omitempty"`
 	Error  string      `json:"error,omitempty"`
 }
+
+// GetResourceParams represents parameters for getting any resource kind
+type GetResourceParams struct {
+	Kind          string `json:"kind"` // kind, plural or short name, optionally with group (deploy, hpa, jobs.batch, Istio)
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"name,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+	FieldSelector string `json:"field_selector,omitempty"`
+	Limit         int64  `json:"limit,omitempty"`
+	Output        string `json:"output,omitempty"` // summary (default), yaml or json
+}
+
+// ResourceSummary represents one row of a generic resource listing
+type ResourceSummary struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Owner      string `json:"owner,omitempty"`
+	Age        string `json:"age"`
+}
+
+// GetResourceResult represents the result of getting resources of any kind
+type GetResourceResult struct {
+	Status     string            `json:"status"`
+	APIVersion string            `json:"api_version,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Resource   string            `json:"resource,omitempty"`
+	Namespaced bool              `json:"namespaced"`
+	Items      []ResourceSummary `json:"items,omitempty"`
+	Count      int               `json:"count"`
+	More       bool              `json:"more,omitempty"` // more items exist beyond the limit
+	Error      string            `json:"error,omitempty"`
+}
+
+// DescribeResourceParams represents parameters for describing resources of any kind
+type DescribeResourceParams struct {
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"name,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+}
+
+// OwnerReferenceInfo represents an owner reference of a resource
+type OwnerReferenceInfo struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Controller bool   `json:"controller,omitempty"`
+}
+
+// ResourceDescription represents a describe-style summary of one resource
+type ResourceDescription struct {
+	APIVersion   string               `json:"api_version"`
+	Kind         string               `json:"kind"`
+	Name         string               `json:"name"`
+	Namespace    string               `json:"namespace,omitempty"`
+	UID          string               `json:"uid"`
+	Created      string               `json:"created"`
+	Age          string               `json:"age"`
+	Status       string               `json:"status,omitempty"`
+	Labels       map[string]string    `json:"labels,omitempty"`
+	Annotations  map[string]string    `json:"annotations,omitempty"`
+	Owners       []OwnerReferenceInfo `json:"owners,omitempty"`
+	Spec         map[string]string    `json:"spec,omitempty"`          // scalar spec fields by dotted path
+	StatusFields map[string]string    `json:"status_fields,omitempty"` // scalar status fields by dotted path
+	Conditions   []ResourceCondition  `json:"conditions,omitempty"`
+	Events       []EventInfo          `json:"events,omitempty"`
+}
+
+// DescribeResourceResult represents the result of describing resources of any kind
+type DescribeResourceResult struct {
+	Status    string                `json:"status"`
+	Resources []ResourceDescription `json:"resources,omitempty"`
+	Count     int                   `json:"count"`
+	More      bool                  `json:"more,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
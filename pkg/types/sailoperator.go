@@ -3,7 +3,7 @@ package types
 // ListSailOperatorResourcesParams represents parameters for listing Sail Operator resources
 type ListSailOperatorResourcesParams struct {
 	Namespace string `json:"namespace,omitempty"`
-	Resource  string `json:"resource,omitempty"` // istio, istiorevision, istiocni, ztunnel, all
+	Resource  string `json:"resource,omitempty"` // istio, istiorevision, istiorevisiontag, istiocni, ztunnel, all
 }
 
 // SailOperatorResource represents a generic Sail Operator CRD resource
@@ -20,10 +20,11 @@ type SailOperatorResource struct {
 
 // ResourceCondition represents a condition in a Kubernetes resource status
 type ResourceCondition struct {
-	Type    string `json:"type"`
-	Status  string `json:"status"`
-	Reason  string `json:"reason,omitempty"`
-	Message string `json:"message,omitempty"`
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"last_transition_time,omitempty"`
 }
 
 // ListSailOperatorResourcesResult represents the result of listing Sail Operator resources
@@ -71,15 +72,34 @@ type GetIstioStatusResult struct {
 // CheckSailOperatorHealthParams represents parameters for health checking
 type CheckSailOperatorHealthParams struct {
 	Namespace string `json:"namespace,omitempty"`
+	// StaleAfterSeconds is how long a resource may stay unreconciled or keep a
+	// critical condition in a non-True state before it is reported as Stale
+	StaleAfterSeconds int64 `json:"stale_after_seconds,omitempty"`
+	// Concurrency limits how many component types are checked at once
+	Concurrency int `json:"concurrency,omitempty"`
+	// TimeoutSeconds bounds the checks of each component type; components
+	// that do not answer in time are reported with status Timeout
+	TimeoutSeconds int64 `json:"timeout_seconds,omitempty"`
 }
 
 // HealthCheckResult represents health check results
 type HealthCheckResult struct {
-	Component string              `json:"component"`
-	Status    string              `json:"status"`
-	Reason    string              `json:"reason,omitempty"`
-	Issues    []string            `json:"issues,omitempty"`
+	Component  string              `json:"component"`
+	Status     string              `json:"status"`
+	Reason     string              `json:"reason,omitempty"`
+	Issues     []string            `json:"issues,omitempty"`
 	Conditions []ResourceCondition `json:"conditions,omitempty"`
+	Resources  []ResourceHealth    `json:"resources,omitempty"`
+}
+
+// ResourceHealth represents the health of a single Sail Operator resource
+type ResourceHealth struct {
+	Name               string   `json:"name"`
+	Namespace          string   `json:"namespace,omitempty"`
+	Status             string   `json:"status"` // Healthy|Degraded|Reconciling|Stale|Unhealthy
+	Generation         int64    `json:"generation,omitempty"`
+	ObservedGeneration int64    `json:"observed_generation,omitempty"`
+	Issues             []string `json:"issues,omitempty"`
 }
 
 // CheckSailOperatorHealthResult represents the result of health checking
@@ -89,4 +109,262 @@ type CheckSailOperatorHealthResult struct {
 	Components  []HealthCheckResult `json:"components,omitempty"`
 	Summary     string              `json:"summary,omitempty"`
 	Error       string              `json:"error,omitempty"`
-}
\ No newline at end of file
+}
+
+// ListSailCRDsParams represents parameters for listing Sail Operator CRDs
+type ListSailCRDsParams struct {
+	Refresh bool `json:"refresh,omitempty"` // re-run API discovery before reporting
+}
+
+// SailCRDInfo describes how a Sail Operator resource kind is served by the cluster
+type SailCRDInfo struct {
+	Kind             string   `json:"kind"`
+	Resource         string   `json:"resource"`
+	CRDName          string   `json:"crd_name"`
+	Installed        bool     `json:"installed"`
+	Established      *bool    `json:"established,omitempty"` // nil when the CRD object could not be read
+	Namespaced       bool     `json:"namespaced"`
+	ServedVersions   []string `json:"served_versions,omitempty"`
+	PreferredVersion string   `json:"preferred_version,omitempty"`
+	StorageVersion   string   `json:"storage_version,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// ListSailCRDsResult represents the result of listing Sail Operator CRDs
+type ListSailCRDsResult struct {
+	Status          string            `json:"status"`
+	CRDs            []SailCRDInfo     `json:"crds,omitempty"`
+	DiscoveryErrors map[string]string `json:"discovery_errors,omitempty"` // by group version
+	DiscoveredAt    string            `json:"discovered_at,omitempty"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// GetIstioValuesParams represents parameters for inspecting Istio values
+type GetIstioValuesParams struct {
+	Name string `json:"name,omitempty"` // Istio resource name, defaults to "default"
+	// FromRevision and ToRevision select two IstioRevisions to diff; when only
+	// one is set it is compared with the active revision
+	FromRevision string `json:"from_revision,omitempty"`
+	ToRevision   string `json:"to_revision,omitempty"`
+	// IncludeProfileDefaults merges approximate built-in profile defaults
+	// under the values before showing and diffing them
+	IncludeProfileDefaults bool `json:"include_profile_defaults,omitempty"`
+}
+
+// ValueDiff is a single difference between two Helm values trees
+type ValueDiff struct {
+	Path   string      `json:"path"`   // dotted path, e.g. pilot.env.PILOT_ENABLE_AMBIENT
+	Change string      `json:"change"` // added|removed|changed
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+// RevisionValues holds the values the operator rendered into an IstioRevision
+type RevisionValues struct {
+	Name    string                 `json:"name"`
+	Version string                 `json:"version,omitempty"`
+	Active  bool                   `json:"active,omitempty"`
+	Values  map[string]interface{} `json:"values,omitempty"`
+}
+
+// ValuesComparison is the diff between two values trees
+type ValuesComparison struct {
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Approximate bool        `json:"approximate,omitempty"` // both sides include approximate profile defaults
+	Diffs       []ValueDiff `json:"diffs"`
+}
+
+// GetIstioValuesResult represents the result of inspecting Istio values
+type GetIstioValuesResult struct {
+	Status                string                 `json:"status"`
+	Name                  string                 `json:"name,omitempty"`
+	Version               string                 `json:"version,omitempty"`
+	Profile               string                 `json:"profile,omitempty"`
+	UserValues            map[string]interface{} `json:"user_values,omitempty"`
+	EffectiveValues       map[string]interface{} `json:"effective_values,omitempty"`        // approximate profile defaults merged with user values
+	Approximate           bool                   `json:"approximate,omitempty"`             // effective values and comparisons use the server's approximate profile defaults, not the operator's rendered profiles
+	ProfileDefaultsSource string                 `json:"profile_defaults_source,omitempty"` // operator release the approximate profile defaults were taken from
+	Revisions             []RevisionValues       `json:"revisions,omitempty"`
+	Comparisons           []ValuesComparison     `json:"comparisons,omitempty"`
+	Error                 string                 `json:"error,omitempty"`
+}
+
+// NodeAgentStatusParams represents parameters for inspecting the IstioCNI or
+// ZTunnel node agent DaemonSet
+type NodeAgentStatusParams struct {
+	Name         string `json:"name,omitempty"`           // IstioCNI/ZTunnel resource name, defaults to "default"
+	ShowAllNodes bool   `json:"show_all_nodes,omitempty"` // list covered nodes too, not only the gaps
+}
+
+// DaemonSetCoverage summarizes the rollout state of a node agent DaemonSet
+type DaemonSetCoverage struct {
+	Name         string   `json:"name"`
+	Namespace    string   `json:"namespace"`
+	Desired      int32    `json:"desired"`
+	Scheduled    int32    `json:"scheduled"`
+	Ready        int32    `json:"ready"`
+	Available    int32    `json:"available"`
+	Updated      int32    `json:"updated"`
+	Misscheduled int32    `json:"misscheduled,omitempty"`
+	Tolerations  []string `json:"tolerations,omitempty"`
+	NodeSelector string   `json:"node_selector,omitempty"`
+}
+
+// NodeCoverage describes whether a node runs a healthy node agent pod
+type NodeCoverage struct {
+	Node              string   `json:"node"`
+	Status            string   `json:"status"` // Ready|NotReady|Missing|Excluded
+	Pod               string   `json:"pod,omitempty"`
+	PodPhase          string   `json:"pod_phase,omitempty"`
+	Reason            string   `json:"reason,omitempty"`
+	UntoleratedTaints []string `json:"untolerated_taints,omitempty"`
+}
+
+// CNIChainingConfig describes how the Istio CNI plugin is installed on nodes
+type CNIChainingConfig struct {
+	ConfigMap    string            `json:"config_map,omitempty"`
+	Chained      *bool             `json:"chained,omitempty"`
+	Provider     string            `json:"provider,omitempty"`
+	ConfDir      string            `json:"conf_dir,omitempty"`
+	BinDir       string            `json:"bin_dir,omitempty"`
+	ConfFileName string            `json:"conf_file_name,omitempty"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+// NodeAgentStatusResult represents the result of inspecting a node agent
+type NodeAgentStatusResult struct {
+	Status     string              `json:"status"`
+	Kind       string              `json:"kind"`
+	Name       string              `json:"name"`
+	Namespace  string              `json:"namespace,omitempty"`
+	Version    string              `json:"version,omitempty"`
+	State      string              `json:"state,omitempty"`
+	Conditions []ResourceCondition `json:"conditions,omitempty"`
+	DaemonSet  *DaemonSetCoverage  `json:"daemonset,omitempty"`
+	TotalNodes int                 `json:"total_nodes"`
+	Covered    int                 `json:"covered_nodes"`
+	Nodes      []NodeCoverage      `json:"nodes,omitempty"`
+	CNIConfig  *CNIChainingConfig  `json:"cni_config,omitempty"`
+	Issues     []string            `json:"issues,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// CheckInjectionWebhooksParams represents parameters for checking Istio webhooks
+type CheckInjectionWebhooksParams struct {
+	Revision string `json:"revision,omitempty"` // only check webhooks of this revision or tag
+}
+
+// WebhookInfo describes a single webhook of an Istio webhook configuration
+type WebhookInfo struct {
+	Configuration      string   `json:"configuration"`
+	Type               string   `json:"type"` // mutating|validating
+	Name               string   `json:"name"`
+	Revision           string   `json:"revision,omitempty"`
+	Tag                string   `json:"tag,omitempty"`
+	MappedTo           string   `json:"mapped_to,omitempty"` // e.g. IstioRevision default-v1-24-0
+	Service            string   `json:"service,omitempty"`   // namespace/name:port
+	URL                string   `json:"url,omitempty"`
+	ReadyEndpoints     int      `json:"ready_endpoints"`
+	FailurePolicy      string   `json:"failure_policy,omitempty"`
+	CAExpiry           string   `json:"ca_expiry,omitempty"`
+	CADaysLeft         *int     `json:"ca_days_left,omitempty"`
+	NamespaceSelector  string   `json:"namespace_selector,omitempty"`
+	ObjectSelector     string   `json:"object_selector,omitempty"`
+	MatchingNamespaces int      `json:"matching_namespaces"`
+	Issues             []string `json:"issues,omitempty"`
+}
+
+// WebhookOverlap reports two injection webhooks that would both mutate pods
+// in the same namespaces
+type WebhookOverlap struct {
+	Webhooks   []string `json:"webhooks"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// CheckInjectionWebhooksResult represents the result of checking Istio webhooks
+type CheckInjectionWebhooksResult struct {
+	Status   string           `json:"status"`
+	Webhooks []WebhookInfo    `json:"webhooks,omitempty"`
+	Overlaps []WebhookOverlap `json:"overlaps,omitempty"`
+	Issues   []string         `json:"issues,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// PatchIstioVersionParams represents parameters for changing the Istio version
+type PatchIstioVersionParams struct {
+	Name    string `json:"name,omitempty"` // Istio resource name, defaults to "default"
+	Version string `json:"version"`
+}
+
+// SetUpdateStrategyParams represents parameters for changing the Istio update strategy
+type SetUpdateStrategyParams struct {
+	Name                                       string `json:"name,omitempty"` // Istio resource name, defaults to "default"
+	Type                                       string `json:"type"`           // InPlace|RevisionBased
+	InactiveRevisionDeletionGracePeriodSeconds *int64 `json:"inactive_revision_deletion_grace_period_seconds,omitempty"`
+	UpdateWorkloads                            *bool  `json:"update_workloads,omitempty"`
+}
+
+// SwitchRevisionTagParams represents parameters for pointing a revision tag at another target
+type SwitchRevisionTagParams struct {
+	Tag        string `json:"tag"`
+	TargetKind string `json:"target_kind,omitempty"` // Istio|IstioRevision, defaults to Istio
+	TargetName string `json:"target_name"`
+}
+
+// RestartWorkloadsForRevisionParams represents parameters for restarting the
+// workloads injected by a revision
+type RestartWorkloadsForRevisionParams struct {
+	Revision  string `json:"revision"`
+	Namespace string `json:"namespace,omitempty"` // only restart workloads in this namespace
+}
+
+// PlannedChange is the dry-run result for a single object
+type PlannedChange struct {
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	Diffs     []ValueDiff `json:"diffs,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// WriteResult represents the result of a confirmed write operation
+type WriteResult struct {
+	Status  string          `json:"status"`
+	Outcome string          `json:"outcome"` // applied|declined|cancelled|refused|rejected|no-op|dry-run-failed|failed
+	Changes []PlannedChange `json:"changes,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// GenerateIstioManifestParams describes the intended mesh installation
+type GenerateIstioManifestParams struct {
+	Name           string `json:"name,omitempty"`     // Istio resource name, defaults to "default"
+	Version        string `json:"version"`            // e.g. v1.24.3
+	Profile        string `json:"profile,omitempty"`  // defaults to "ambient" when ambient is set
+	Strategy       string `json:"strategy,omitempty"` // InPlace (default) or RevisionBased
+	Ambient        bool   `json:"ambient,omitempty"`  // also generate IstioCNI and ZTunnel
+	CNI            bool   `json:"cni,omitempty"`      // generate IstioCNI without ambient, e.g. on OpenShift
+	MeshID         string `json:"mesh_id,omitempty"`
+	TrustDomain    string `json:"trust_domain,omitempty"`
+	Namespace      string `json:"namespace,omitempty"` // control plane namespace, defaults to istio-system
+	Tag            string `json:"tag,omitempty"`       // IstioRevisionTag name for RevisionBased, defaults to "default"
+	SkipValidation bool   `json:"skip_validation,omitempty"`
+}
+
+// ManifestValidation is the server-side dry-run result of a generated object
+type ManifestValidation struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Mode  string `json:"mode"` // create|update|skipped
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// GenerateIstioManifestResult represents the generated manifests
+type GenerateIstioManifestResult struct {
+	Status      string               `json:"status"`
+	YAML        string               `json:"yaml,omitempty"`
+	Validations []ManifestValidation `json:"validations,omitempty"`
+	Notes       []string             `json:"notes,omitempty"`
+	Error       string               `json:"error,omitempty"`
+}